@@ -0,0 +1,27 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// FormatRupiah formats an amount the Indonesian way, e.g. "Rp 1.250.000".
+// Amounts are rounded to whole rupiah since sen are not used in practice.
+func FormatRupiah(amount decimal.Decimal) string {
+	digits := amount.Round(0).Abs().String()
+
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if amount.Round(0).IsNegative() {
+		return "-Rp " + grouped.String()
+	}
+
+	return "Rp " + grouped.String()
+}
@@ -42,7 +42,7 @@ func ErrorHandler() fiber.ErrorHandler {
 			errorMsg = fiberErr.Message
 		} else {
 			code = getStatusCode(err)
-			errorMsg = err.Error()
+			errorMsg = ErrorMessage(ctx, err)
 		}
 
 		return ctx.Status(code).JSON(dto.ApiResponse{
@@ -1,11 +1,11 @@
 package common
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"math/rand"
-	"time"
+	"math/big"
 )
 
 func GenerateSHA256(salt, word string) string {
@@ -15,11 +15,18 @@ func GenerateSHA256(salt, word string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// GenerateRandomString picks strlen characters of chars with crypto/rand, so
+// the result can be used for codes that must not be guessed.
 func GenerateRandomString(chars string, strlen int) string {
-	rand.New(rand.NewSource(time.Now().UTC().UnixNano()))
+	max := big.NewInt(int64(len(chars)))
 	result := make([]byte, strlen)
 	for i := 0; i < strlen; i++ {
-		result[i] = chars[rand.Intn(len(chars))]
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			// only happens when the system has no source of randomness
+			panic(err)
+		}
+		result[i] = chars[n.Int64()]
 	}
 	return string(result)
 }
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const earthRadiusMeters = 6371000
+
+// Distance returns the great-circle distance in meters between two points
+// given in decimal degrees, using the haversine formula.
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return earthRadiusMeters * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// ParseCoordinates reads a latitude and longitude stored as text.
+func ParseCoordinates(lat, lng string) (float64, float64, error) {
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return 0, 0, fmt.Errorf("coordinates %s,%s are out of range", lat, lng)
+	}
+
+	return latitude, longitude, nil
+}
+
+// BoundingBox returns the latitudes and longitudes bounding the points within
+// meters of the given one, to narrow them down before measuring the Distance.
+// Longitudes are left unbounded where the box would wrap around the globe.
+func BoundingBox(lat, lng, meters float64) (minLat, maxLat, minLng, maxLng float64) {
+	dLat := meters / earthRadiusMeters * 180 / math.Pi
+	minLat, maxLat = math.Max(lat-dLat, -90), math.Min(lat+dLat, 90)
+	minLng, maxLng = -180, 180
+
+	if cos := math.Cos(math.Max(math.Abs(minLat), math.Abs(maxLat)) * math.Pi / 180); cos > 0 {
+		dLng := dLat / cos
+		if lng-dLng >= -180 && lng+dLng <= 180 {
+			minLng, maxLng = lng-dLng, lng+dLng
+		}
+	}
+
+	return minLat, maxLat, minLng, maxLng
+}
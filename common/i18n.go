@@ -0,0 +1,196 @@
+package common
+
+import (
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// SupportedLocales lists the locales the API and emails are available in,
+// the first one being the fallback.
+var SupportedLocales = []string{enums.LocaleID, enums.LocaleEN}
+
+var messages = map[string]map[string]string{
+	enums.LocaleEN: {
+		enums.MsgSuccess: "Successfuly",
+	},
+	enums.LocaleID: {
+		enums.MsgSuccess: "Berhasil",
+	},
+}
+
+var statusMessagesID = map[int]string{
+	fiber.StatusOK:                  "Berhasil",
+	fiber.StatusBadRequest:          "Permintaan tidak valid",
+	fiber.StatusUnauthorized:        "Tidak terautentikasi",
+	fiber.StatusForbidden:           "Akses ditolak",
+	fiber.StatusNotFound:            "Data tidak ditemukan",
+	fiber.StatusConflict:            "Terjadi konflik data",
+	fiber.StatusUnprocessableEntity: "Data tidak dapat diproses",
+	fiber.StatusTooManyRequests:     "Terlalu banyak permintaan",
+	fiber.StatusInternalServerError: "Terjadi kesalahan pada server",
+	fiber.StatusServiceUnavailable:  "Layanan sedang tidak tersedia",
+}
+
+var errorMessagesID = map[error]string{
+	enums.ErrAccessForbidden:          "anda tidak memiliki akses ke url ini",
+	enums.ErrUnauthorized:             "Tidak terautentikasi",
+	enums.ErrNotFound:                 "Data yang diminta tidak ditemukan",
+	enums.ErrInternalServor:           "Terjadi kesalahan pada server",
+	enums.ErrBadParamInput:            "Parameter yang dikirim tidak valid",
+	enums.ErrIncorrectCredential:      "Login gagal. Email atau kata sandi salah.",
+	enums.ErrInvalidToken:             "token tidak valid",
+	enums.ErrInvalidRefreshToken:      "refresh token tidak valid",
+	enums.ErrExpiredToken:             "token sudah kedaluwarsa",
+	enums.ErrEmailOrPasswordMissMatch: "email/kata sandi tidak cocok",
+	enums.ErrCampaignTransition:       "status campaign tidak dapat diubah ke status ini dari status saat ini",
+	enums.ErrCampaignLocked:           "campaign tidak dapat diubah lagi pada status saat ini",
+	enums.ErrReportNotAllowed:         "laporan campaign belum dapat dikirim sebelum distribusi dimulai",
+	enums.ErrReportExists:             "campaign sudah memiliki laporan yang menunggu verifikasi atau terverifikasi",
+	enums.ErrReportPhoto:              "foto laporan harus diunggah ke tujuan media report",
+	enums.ErrReportDistance:           "lokasi laporan terlalu jauh dari lokasi campaign",
+	enums.ErrReportReviewed:           "laporan sudah ditinjau",
+	enums.ErrOrderNotDeliverable:      "hanya pesanan yang disetujui pada campaign yang terdanai atau berjalan yang dapat diantar",
+	enums.ErrDeliveryTokenExpired:     "token pengantaran sudah kedaluwarsa",
+	enums.ErrDeliveryTokenUsed:        "token pengantaran sudah digunakan atau diganti",
+	enums.ErrDeliveryCampaign:         "token pengantaran bukan untuk campaign ini",
+	enums.ErrPayoutProviderMissing:    "penyedia pembayaran belum dikonfigurasi",
+	enums.ErrPayoutNothingDue:         "tidak ada merchant dengan saldo yang perlu dibayarkan",
+	enums.ErrFeePercentage:            "biaya persentase tidak boleh lebih dari 100",
+	enums.ErrBudgetExceeded:           "pesanan melebihi dana campaign",
+	enums.ErrOrderLocked:              "pesanan tidak dapat ditambahkan lagi pada status campaign saat ini",
+	enums.ErrEmailRegistered:          "email atau nomor telepon sudah terdaftar",
+	enums.ErrOTPExpired:               "OTP sudah kedaluwarsa, silakan minta OTP baru",
+	enums.ErrDonationClosed:           "campaign tidak sedang menerima donasi",
+	enums.ErrDonationReviewed:         "donasi sudah diperiksa",
+	enums.ErrCertificateNotReady:      "sertifikat diterbitkan setelah campaign selesai",
+	enums.ErrReceiptNotIssued:         "kuitansi diterbitkan setelah donasi dibayar",
+	enums.ErrImportFormat:             "hanya file CSV dan XLSX yang dapat diimpor",
+	enums.ErrImportTooLarge:           "jumlah baris file melebihi batas impor sekaligus",
+	enums.ErrImportFileSize:           "ukuran file melebihi batas impor sekaligus",
+	enums.ErrImportInterrupted:        "impor terhenti, silakan unggah ulang file",
+	enums.ErrImportColumns:            "file harus memiliki kolom name, description, price dan qty",
+	enums.ErrImportInvalid:            "ada baris yang tidak valid, tidak ada produk yang diimpor",
+	enums.ErrImportNumber:             "harus berupa angka",
+	enums.ErrCategoryExists:           "kategori produk sudah ada",
+	enums.ErrCategoryNotFound:         "kategori produk tidak ditemukan",
+	enums.ErrProductNotApproved:       "hanya produk yang disetujui yang dapat diaktifkan",
+	enums.ErrProductUnavailable:       "produk belum disetujui, tidak aktif atau stoknya habis",
+	enums.ErrProductInUse:             "produk dengan pesanan yang masih berjalan tidak dapat dihapus",
+	enums.ErrCampaignNotDeletable:     "hanya campaign draft dan yang dibatalkan yang dapat dihapus",
+	enums.ErrDetonatorInUse:           "detonator dengan campaign yang masih berjalan tidak dapat dihapus",
+	enums.ErrRestoreOwner:             "pulihkan terlebih dahulu merchant atau detonator pemiliknya",
+	enums.ErrAccountDeleted:           "akun telah dihapus",
+	enums.ErrPriceStart:               "harga reguler hanya dapat dijadwalkan untuk waktu mendatang",
+	enums.ErrPromoPeriod:              "harga promo harus berakhir setelah dimulai dan di waktu mendatang",
+	enums.ErrPromoOverlap:             "produk sudah memiliki harga promo pada periode tersebut",
+	enums.ErrPriceApplied:             "harga yang sudah berlaku tidak dapat dibatalkan",
+	enums.ErrMerchantHours:            "merchant harus tutup setelah buka, sekali per hari",
+	enums.ErrClosurePeriod:            "penutupan harus berakhir pada atau setelah hari dimulainya",
+	enums.ErrMerchantClosed:           "merchant tutup pada waktu acara",
+	enums.ErrMerchantCapacity:         "kapasitas merchant pada tanggal acara sudah habis",
+	enums.ErrCampaignLocation:         "lokasi campaign tidak valid untuk mencari merchant",
+	enums.ErrOTPMismatch:              "OTP tidak cocok, silakan periksa kembali kode OTP anda",
+	enums.ErrOTPAttempts:              "terlalu banyak kode OTP yang salah, silakan minta OTP baru",
+	enums.ErrOTPCooldown:              "OTP baru saja dikirim, silakan tunggu sebelum meminta lagi",
+	enums.ErrOutboxNotReplayable:      "hanya pesan dead-letter yang dapat dikirim ulang",
+	enums.ErrOrderNotWaiting:          "hanya pesanan yang menunggu yang dapat disetujui atau ditolak",
+	enums.ErrMailTransportMissing:     "transport email belum dikonfigurasi",
+	enums.ErrCampaignDetonator:        "detonator campaign tidak dapat diubah",
+	enums.ErrWebhookPingRedelivery:    "ping tidak dapat dikirim ulang, kirim ping baru",
+	enums.ErrMerchantBalance:          "merchant dengan saldo yang belum dibayar atau pembayaran yang sedang diproses tidak dapat dihapus",
+}
+
+// errorsByMessage indexes the translated enums errors by their English text, so
+// messages that services already turned into strings can still be localized.
+var errorsByMessage = func() map[string]error {
+	index := make(map[string]error, len(errorMessagesID))
+	for err := range errorMessagesID {
+		index[err.Error()] = err
+	}
+
+	return index
+}()
+
+// NegotiateLocale picks the best supported locale for the request's Accept-Language header.
+func NegotiateLocale(c *fiber.Ctx) string {
+	if locale := c.AcceptsLanguages(SupportedLocales...); locale != "" {
+		return locale
+	}
+
+	return enums.DefaultLocale
+}
+
+// Locale returns the locale negotiated for the current request.
+func Locale(c *fiber.Ctx) string {
+	if locale, ok := c.Locals(enums.LocaleLocalsKey).(string); ok && locale != "" {
+		return locale
+	}
+
+	return NegotiateLocale(c)
+}
+
+// IsSupportedLocale reports whether the API has translations for the locale.
+func IsSupportedLocale(locale string) bool {
+	_, ok := messages[locale]
+	return ok
+}
+
+// Translate returns the message for key in the given locale, falling back to
+// English and finally to the key itself.
+func Translate(locale, key string) string {
+	if message, ok := messages[locale][key]; ok {
+		return message
+	}
+
+	if message, ok := messages[enums.LocaleEN][key]; ok {
+		return message
+	}
+
+	return key
+}
+
+// Message returns the message for key in the request locale.
+func Message(c *fiber.Ctx, key string) string {
+	return Translate(Locale(c), key)
+}
+
+// StatusMessage returns the HTTP status text in the request locale.
+func StatusMessage(c *fiber.Ctx, code int) string {
+	if Locale(c) == enums.LocaleID {
+		if message, ok := statusMessagesID[code]; ok {
+			return message
+		}
+	}
+
+	return utils.StatusMessage(code)
+}
+
+// ErrorMessage returns the text of one of the enums errors in the request
+// locale. Other errors are returned unchanged.
+func ErrorMessage(c *fiber.Ctx, err error) string {
+	return LocalizedError(Locale(c), err)
+}
+
+// FailMessage returns the message of a service error in the request locale.
+// Messages that are not the text of one of the enums errors are returned unchanged.
+func FailMessage(c *fiber.Ctx, message string) string {
+	if err, ok := errorsByMessage[message]; ok {
+		return ErrorMessage(c, err)
+	}
+
+	return message
+}
+
+// LocalizedError returns the text of one of the enums errors in the locale,
+// for messages built outside of a request.
+func LocalizedError(locale string, err error) string {
+	if locale == enums.LocaleID {
+		if message, ok := errorMessagesID[err]; ok {
+			return message
+		}
+	}
+
+	return err.Error()
+}
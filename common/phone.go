@@ -0,0 +1,22 @@
+package common
+
+import "strings"
+
+// NormalizePhone converts Indonesian phone numbers to the international format
+// without the plus sign expected by SMS and WhatsApp gateways, e.g.
+// "0812-3456-7890" and "+6281234567890" both become "6281234567890".
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	number := digits.String()
+	if strings.HasPrefix(number, "0") {
+		return "62" + number[1:]
+	}
+
+	return number
+}
@@ -0,0 +1,14 @@
+package common
+
+import (
+	"foodia-be/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Session returns the JWT claims stored by the RBAC middleware, or nil on
+// routes that are not authenticated.
+func Session(c *fiber.Ctx) *dto.JWTClaims {
+	claims, _ := c.Locals("session").(*dto.JWTClaims)
+	return claims
+}
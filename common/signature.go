@@ -0,0 +1,84 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"foodia-be/enums"
+)
+
+// GenerateSecret returns a random hex encoded secret of n bytes.
+func GenerateSecret(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// SignPayload computes the webhook signature of body sent at timestamp (unix
+// seconds): hex(HMAC-SHA256(secret, "<timestamp>.<body>")).
+func SignPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPayload reports whether signature matches the body and timestamp.
+func VerifyPayload(secret string, timestamp int64, body []byte, signature string) bool {
+	expected := SignPayload(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// SignToken encodes claims as a compact "<payload>.<signature>" token, both
+// parts base64url encoded, signed with HMAC-SHA256.
+func SignToken(secret string, claims any) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyToken checks the signature of a token made by SignToken and decodes
+// its claims.
+func VerifyToken(secret, token string, claims any) error {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return enums.ErrInvalidToken
+	}
+
+	sum, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return enums.ErrInvalidToken
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return enums.ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return enums.ErrInvalidToken
+	}
+
+	return json.Unmarshal(payload, claims)
+}
@@ -0,0 +1,438 @@
+package common
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"foodia-be/enums"
+)
+
+// SheetWriter writes a table row by row, so exports never hold more than the
+// rows being written.
+type SheetWriter interface {
+	Write(row []string) error
+	// Flush pushes the rows written so far to the underlying writer.
+	Flush() error
+	// Close finishes the document. It does not close the underlying writer.
+	Close() error
+}
+
+// SheetContentTypes are the content types of the sheet formats.
+var SheetContentTypes = map[string]string{
+	enums.ExportFormatCSV:  "text/csv; charset=utf-8",
+	enums.ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// NewSheetWriter returns the writer for the format, named name where the
+// format has sheet names.
+func NewSheetWriter(format string, w io.Writer, name string) SheetWriter {
+	if format == enums.ExportFormatXLSX {
+		return NewXLSXWriter(w, name)
+	}
+
+	return NewCSVWriter(w)
+}
+
+type CSVWriter struct {
+	csv *csv.Writer
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{csv: csv.NewWriter(w)}
+}
+
+// Write escapes values that spreadsheet programs would run as formulas.
+func (w *CSVWriter) Write(row []string) error {
+	safe := make([]string, len(row))
+	for i, value := range row {
+		if formulaLike(value) {
+			value = "'" + value
+		}
+		safe[i] = value
+	}
+
+	return w.csv.Write(safe)
+}
+
+// formulaLike reports whether a spreadsheet could read the value as a formula.
+// Plain numbers, negative amounts included, are left as they are.
+func formulaLike(value string) bool {
+	if value == "" || !strings.ContainsAny(value[:1], "=@+-\t\r") {
+		return false
+	}
+
+	_, err := strconv.ParseFloat(value, 64)
+	return err != nil
+}
+
+func (w *CSVWriter) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *CSVWriter) Close() error {
+	return w.Flush()
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// XLSXWriter writes a workbook with a single sheet of text cells. The sheet is
+// the last part of the zip so its rows can be streamed out as they come.
+type XLSXWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	err   error
+}
+
+func NewXLSXWriter(w io.Writer, name string) *XLSXWriter {
+	writer := &XLSXWriter{zip: zip.NewWriter(w)}
+
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(name))
+
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + escaped.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	parts := [][2]string{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+
+	for _, part := range parts {
+		if writer.err = writer.part(part[0], part[1]); writer.err != nil {
+			return writer
+		}
+	}
+
+	sheet, err := writer.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		writer.err = err
+		return writer
+	}
+
+	writer.sheet = bufio.NewWriter(sheet)
+	_, writer.err = writer.sheet.WriteString(xlsxSheetStart)
+
+	return writer
+}
+
+func (w *XLSXWriter) part(name, content string) error {
+	file, err := w.zip.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(file, content)
+	return err
+}
+
+func (w *XLSXWriter) Write(row []string) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.sheet.WriteString("<row>")
+	for _, value := range row {
+		w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(w.sheet, []byte(value)); err != nil {
+			w.err = err
+			return err
+		}
+		w.sheet.WriteString("</t></is></c>")
+	}
+	_, w.err = w.sheet.WriteString("</row>")
+
+	return w.err
+}
+
+func (w *XLSXWriter) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.err = w.sheet.Flush(); w.err != nil {
+		return w.err
+	}
+
+	w.err = w.zip.Flush()
+	return w.err
+}
+
+func (w *XLSXWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if _, w.err = w.sheet.WriteString(xlsxSheetEnd); w.err != nil {
+		return w.err
+	}
+
+	if w.err = w.sheet.Flush(); w.err != nil {
+		return w.err
+	}
+
+	return w.zip.Close()
+}
+
+// MaskKTP hides all but the last four digits of a KTP number.
+func MaskKTP(number string) string {
+	if len(number) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
+
+// ReadSheet reads the rows of a CSV file or of the first sheet of an XLSX
+// workbook. It fails with enums.ErrImportTooLarge past maxRows rows.
+func ReadSheet(format string, data []byte, maxRows int) ([][]string, error) {
+	if format == enums.ExportFormatXLSX {
+		return readXLSX(data, maxRows)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == maxRows {
+			return nil, enums.ErrImportTooLarge
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// xlsxMaxPartSize caps the uncompressed size of the parts of an XLSX workbook
+// that are read. Uploads are capped on their compressed size, which a zip
+// bomb stays well below.
+const xlsxMaxPartSize = 32 << 20
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+
+	var text strings.Builder
+	for _, run := range t.Runs {
+		text.WriteString(run.Text)
+	}
+
+	return text.String()
+}
+
+type xlsxCell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Value  string   `xml:"v"`
+	Inline xlsxText `xml:"is"`
+}
+
+func readXLSX(data []byte, maxRows int) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var shared []string
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+
+		if err := decodeZipXML(file, &sst); err != nil {
+			return nil, err
+		}
+
+		for _, item := range sst.Items {
+			shared = append(shared, item.String())
+		}
+	}
+
+	file, ok := files[firstSheet(files)]
+	if !ok {
+		return nil, enums.ErrImportFormat
+	}
+
+	content, err := openZipPart(file)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	var rows [][]string
+	var row []string
+
+	decoder := xml.NewDecoder(content)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "row":
+				row = []string{}
+			case "c":
+				var cell xlsxCell
+				if err := decoder.DecodeElement(&cell, &element); err != nil {
+					return nil, err
+				}
+
+				value := cell.Value
+				switch cell.Type {
+				case "s":
+					index, err := strconv.Atoi(cell.Value)
+					if err != nil || index < 0 || index >= len(shared) {
+						return nil, enums.ErrImportFormat
+					}
+					value = shared[index]
+				case "inlineStr":
+					value = cell.Inline.String()
+				}
+
+				// empty cells are left out of the file, put them back
+				if column := xlsxColumn(cell.Ref); column > len(row) {
+					row = append(row, make([]string, column-len(row))...)
+				}
+
+				row = append(row, value)
+			}
+		case xml.EndElement:
+			if element.Name.Local == "row" {
+				if len(rows) == maxRows {
+					return nil, enums.ErrImportTooLarge
+				}
+
+				rows = append(rows, row)
+			}
+		}
+	}
+}
+
+// firstSheet finds the file of the first sheet of the workbook.
+func firstSheet(files map[string]*zip.File) string {
+	fallback := "xl/worksheets/sheet1.xml"
+
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+
+	file, ok := files["xl/workbook.xml"]
+	if !ok || decodeZipXML(file, &workbook) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+
+	file, ok = files["xl/_rels/workbook.xml.rels"]
+	if !ok || decodeZipXML(file, &rels) != nil {
+		return fallback
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].ID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/")
+			}
+
+			return "xl/" + rel.Target
+		}
+	}
+
+	return fallback
+}
+
+func decodeZipXML(file *zip.File, v any) error {
+	content, err := openZipPart(file)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	return xml.NewDecoder(content).Decode(v)
+}
+
+// openZipPart opens a part of the workbook, refusing parts larger than
+// xlsxMaxPartSize. The size in the zip header is not trusted, reading stops
+// at the limit whatever it says.
+func openZipPart(file *zip.File) (io.ReadCloser, error) {
+	if file.UncompressedSize64 > xlsxMaxPartSize {
+		return nil, enums.ErrImportFileSize
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(content, xlsxMaxPartSize), content}, nil
+}
+
+// xlsxColumn is the zero based column of a cell reference such as "C12".
+func xlsxColumn(ref string) int {
+	column := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+	}
+
+	return column - 1
+}
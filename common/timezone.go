@@ -0,0 +1,42 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"foodia-be/enums"
+)
+
+// Indonesian time zones have no daylight saving time, so fixed offsets are
+// used instead of depending on the tz database being installed.
+var timezones = map[string]*time.Location{
+	enums.TimezoneWIB:  time.FixedZone(enums.TimezoneWIB, 7*60*60),
+	enums.TimezoneWITA: time.FixedZone(enums.TimezoneWITA, 8*60*60),
+	enums.TimezoneWIT:  time.FixedZone(enums.TimezoneWIT, 9*60*60),
+}
+
+// Timezone returns the location of WIB, WITA or WIT.
+func Timezone(name string) (*time.Location, error) {
+	if location, ok := timezones[name]; ok {
+		return location, nil
+	}
+
+	return nil, fmt.Errorf("unknown timezone %q", name)
+}
+
+// ParseEventTime combines a YYYY-MM-DD date and a HH:MM (or HH:MM:SS) time in
+// the given Indonesian time zone into an instant.
+func ParseEventTime(date, clock, timezone string) (time.Time, error) {
+	location, err := Timezone(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, date+" "+clock, location); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid event date/time %q %q", date, clock)
+}
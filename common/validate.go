@@ -7,31 +7,36 @@ import (
 	"strings"
 
 	"foodia-be/dto"
+	"foodia-be/enums"
 
 	"github.com/go-playground/validator/v10"
 
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
 	ut "github.com/go-playground/universal-translator"
 
-	translations "github.com/go-playground/validator/v10/translations/en"
+	translationsEN "github.com/go-playground/validator/v10/translations/en"
+	translationsID "github.com/go-playground/validator/v10/translations/id"
 )
 
-// ValidateRequest performs validation on the provided data using the struct tags and validation rules.
-// It takes a single parameter: `data` (any) representing the data to be validated.
-// The function returns a slice of `entities.FieldError` containing any validation errors encountered during the validation process.
-func ValidateRequest(data any) []dto.ApiFieldError {
-	// Create a new language translator for the 'id' locale
-	lang := en.New()
-	uni := ut.New(lang, lang)
+var (
+	validate    *validator.Validate
+	translators *ut.UniversalTranslator
+)
 
-	// Get the translator for the 'id' locale
-	trans, _ := uni.GetTranslator("en")
+func init() {
+	// Create the universal translator holding the 'en' and 'id' locales
+	translators = ut.New(en.New(), en.New(), id.New())
 
 	// Create a new validator instance
-	validate := validator.New()
+	validate = validator.New()
 
-	// Register default translations for the validator
-	translations.RegisterDefaultTranslations(validate, trans)
+	// Register default translations for every supported locale
+	transEN, _ := translators.GetTranslator(enums.LocaleEN)
+	translationsEN.RegisterDefaultTranslations(validate, transEN)
+
+	transID, _ := translators.GetTranslator(enums.LocaleID)
+	translationsID.RegisterDefaultTranslations(validate, transID)
 
 	// Register a custom tag name function for the validator
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -41,6 +46,18 @@ func ValidateRequest(data any) []dto.ApiFieldError {
 		}
 		return name
 	})
+}
+
+// ValidateRequest performs validation on the provided data using the struct tags and validation rules.
+// It takes two parameters: `data` (any) representing the data to be validated and `locale` (string)
+// selecting the language of the returned messages.
+// The function returns a slice of `entities.FieldError` containing any validation errors encountered during the validation process.
+func ValidateRequest(data any, locale string) []dto.ApiFieldError {
+	// Get the translator for the requested locale, falling back to the default one
+	trans, found := translators.GetTranslator(locale)
+	if !found {
+		trans, _ = translators.GetTranslator(enums.DefaultLocale)
+	}
 
 	// Perform the data validation
 	err := validate.Struct(data)
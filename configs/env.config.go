@@ -20,4 +20,28 @@ type EnvConfig struct {
 	SmtpUser              string        `koanf:"SMTP_USER"`
 	SmtpPass              string        `koanf:"SMTP_PASS"`
 	SmtpSender            string        `koanf:"SMTP_SENDER"`
+	MailTransport         string        `koanf:"MAIL_TRANSPORT"`
+	MailCaptureDir        string        `koanf:"MAIL_CAPTURE_DIR"`
+	SmsProvider           string        `koanf:"SMS_PROVIDER"`
+	SmsGatewayURL         string        `koanf:"SMS_GATEWAY_URL"`
+	SmsGatewayToken       string        `koanf:"SMS_GATEWAY_TOKEN"`
+	WhatsAppProvider      string        `koanf:"WHATSAPP_PROVIDER"`
+	WhatsAppGatewayURL    string        `koanf:"WHATSAPP_GATEWAY_URL"`
+	WhatsAppGatewayToken  string        `koanf:"WHATSAPP_GATEWAY_TOKEN"`
+	OutboxInterval        time.Duration `koanf:"OUTBOX_INTERVAL"`
+	OutboxMaxAttempts     int           `koanf:"OUTBOX_MAX_ATTEMPTS"`
+	StreamBroker          string        `koanf:"STREAM_BROKER"`
+	StreamHeartbeat       time.Duration `koanf:"STREAM_HEARTBEAT"`
+	CampaignInterval      time.Duration `koanf:"CAMPAIGN_SCHEDULER_INTERVAL"`
+	CampaignFundingCutoff time.Duration `koanf:"CAMPAIGN_FUNDING_CUTOFF"`
+	CampaignExecution     time.Duration `koanf:"CAMPAIGN_EXECUTION_WINDOW"`
+	PriceInterval         time.Duration `koanf:"PRICE_SCHEDULER_INTERVAL"`
+	ReportMaxDistance     float64       `koanf:"REPORT_MAX_DISTANCE"`
+	DeliverySecret        string        `koanf:"DELIVERY_SECRET"`
+	DeliveryTokenTTL      time.Duration `koanf:"DELIVERY_TOKEN_TTL"`
+	PayoutProvider        string        `koanf:"PAYOUT_PROVIDER"`
+	PayoutMinAmount       float64       `koanf:"PAYOUT_MIN_AMOUNT"`
+	LinkAjaURL            string        `koanf:"LINKAJA_URL"`
+	LinkAjaToken          string        `koanf:"LINKAJA_TOKEN"`
+	DocumentVerifyURL     string        `koanf:"DOCUMENT_VERIFY_URL"`
 }
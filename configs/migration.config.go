@@ -0,0 +1,267 @@
+package configs
+
+import (
+	"strings"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"gorm.io/gorm"
+)
+
+// Migrate creates or updates the tables managed by the application itself.
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&entities.Oauth{},
+		&entities.Outbox{},
+		&entities.Notification{},
+		&entities.WebhookSubscription{},
+		&entities.WebhookDelivery{},
+		&entities.CampaignReport{},
+		&entities.CampaignReportPhoto{},
+		&entities.LedgerTransaction{},
+		&entities.LedgerEntry{},
+		&entities.PayoutBatch{},
+		&entities.Payout{},
+		&entities.FeeRule{},
+		&entities.Donation{},
+		&entities.Document{},
+		&entities.ProductImportJob{},
+		&entities.ProductCategory{},
+		&entities.ProductAttribute{},
+		&entities.ProductPrice{},
+		&entities.MerchantHour{},
+		&entities.MerchantClosure{},
+	); err != nil {
+		return err
+	}
+
+	// campaigns without an event time were never moved to the lifecycle
+	legacyCampaigns := !db.Migrator().HasColumn(&entities.Campaign{}, "EventAt")
+
+	// campaigns predate the migrations and carry relations AutoMigrate would
+	// try to turn into constraints, so only the newer columns are added
+	if err := addColumns(db, &entities.Campaign{}, "EventAt", "Timezone", "Collected", "StatusNote", "StatusAt", "BudgetOverride", "DeletedAt"); err != nil {
+		return err
+	}
+
+	if err := addIndexes(db, &entities.Campaign{}, "EventAt", "Status", "DeletedAt"); err != nil {
+		return err
+	}
+
+	if err := addColumns(db, &entities.Order{}, "Qty", "Price", "DeliveryNonce", "DeliveredAt", "DeliveredBy", "DeliveryLatitude", "DeliveryLongitude", "DeletedAt"); err != nil {
+		return err
+	}
+
+	if err := addIndexes(db, &entities.Order{}, "DeletedAt"); err != nil {
+		return err
+	}
+
+	if err := addColumns(db, &entities.Merchant{}, "DailyCapacity", "DeliveryRadius"); err != nil {
+		return err
+	}
+
+	for _, model := range []any{&entities.Merchant{}, &entities.Detonator{}} {
+		if err := addColumns(db, model, "DeletedAt"); err != nil {
+			return err
+		}
+
+		if err := addIndexes(db, model, "DeletedAt"); err != nil {
+			return err
+		}
+	}
+
+	if err := addColumns(db, &entities.MerchantProduct{}, "CategoryID", "PortionGrams", "Packaging", "ReviewNote", "ReviewedBy", "ReviewedAt", "DeletedAt"); err != nil {
+		return err
+	}
+
+	if err := backfillProductDeletes(db); err != nil {
+		return err
+	}
+
+	if err := addIndexes(db, &entities.MerchantProduct{}, "CategoryID", "Status", "DeletedAt"); err != nil {
+		return err
+	}
+
+	if err := otpEmailColumn(db); err != nil {
+		return err
+	}
+
+	if err := addColumns(db, &entities.OauthOTP{}, "Attempts"); err != nil {
+		return err
+	}
+
+	if err := addIndexes(db, &entities.OauthOTP{}, "Email"); err != nil {
+		return err
+	}
+
+	if legacyCampaigns {
+		if err := db.Transaction(backfillCampaigns); err != nil {
+			return err
+		}
+	}
+
+	return backfillOrders(db)
+}
+
+func addColumns(db *gorm.DB, model any, fields ...string) error {
+	for _, field := range fields {
+		if db.Migrator().HasColumn(model, field) {
+			continue
+		}
+
+		if err := db.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// otpEmailColumn turns the legacy integer email column of the OTPs into text;
+// MySQL compared every email as 0 against it, so any code matched any email.
+func otpEmailColumn(db *gorm.DB) error {
+	columns, err := db.Migrator().ColumnTypes(&entities.OauthOTP{})
+	if err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		if column.Name() == "email" && !strings.Contains(strings.ToLower(column.DatabaseTypeName()), "char") {
+			// the stored emails are lost already, the codes cannot be used anymore
+			if err := db.Where("1 = 1").Delete(&entities.OauthOTP{}).Error; err != nil {
+				return err
+			}
+
+			return db.Migrator().AlterColumn(&entities.OauthOTP{}, "Email")
+		}
+	}
+
+	return nil
+}
+
+func addIndexes(db *gorm.DB, model any, fields ...string) error {
+	for _, field := range fields {
+		if db.Migrator().HasIndex(model, field) {
+			continue
+		}
+
+		if err := db.Migrator().CreateIndex(model, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillCampaigns moves campaigns created before the lifecycle was introduced
+// to the new statuses and parses their free text event date and time, read as
+// WIB. It runs once, when the event time is added to the campaigns.
+func backfillCampaigns(db *gorm.DB) error {
+	var campaigns []entities.Campaign
+	if err := db.Select("id", "status", "event_date", "event_time", "timezone").
+		Where("event_at IS NULL").
+		Find(&campaigns).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, campaign := range campaigns {
+		timezone := campaign.Timezone
+		if timezone == "" {
+			timezone = enums.TimezoneWIB
+		}
+
+		update := map[string]any{}
+
+		// unreadable dates are left for the detonator to correct, the
+		// scheduler ignores the campaign meanwhile
+		var eventAt *time.Time
+		if parsed, err := common.ParseEventTime(campaign.EventDate, campaign.EventTime, timezone); err == nil {
+			eventAt = &parsed
+			update["event_at"] = parsed
+		}
+
+		if status, ok := legacyStatus(campaign.Status, eventAt, now); ok {
+			update["status"] = status
+		}
+
+		if len(update) == 0 {
+			continue
+		}
+
+		if err := db.Model(&entities.Campaign{}).
+			Where("id = ?", campaign.ID).
+			Updates(update).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// legacyStatus is the lifecycle status of a campaign in a status from before
+// the lifecycle. Campaigns whose event is over are closed right away, left
+// open the scheduler would cancel them and tell everyone involved.
+func legacyStatus(status string, eventAt *time.Time, now time.Time) (string, bool) {
+	past := eventAt != nil && !eventAt.After(now)
+
+	switch status {
+	case "waiting":
+		if past {
+			return enums.CampaignStatusCancelled, true
+		}
+		return enums.CampaignStatusReview, true
+	case "approved":
+		if past {
+			return enums.CampaignStatusCompleted, true
+		}
+		return enums.CampaignStatusFundraising, true
+	case "rejected":
+		if past {
+			return enums.CampaignStatusCancelled, true
+		}
+		return enums.CampaignStatusDraft, true
+	}
+
+	return "", false
+}
+
+// backfillOrders prices orders placed before the unit price was kept on the
+// order with the current price of their product.
+func backfillOrders(db *gorm.DB) error {
+	return db.Model(&entities.Order{}).
+		Where("price = ? AND merchant_product_id IN (?)", 0, db.Model(&entities.MerchantProduct{}).Select("id")).
+		Update("price", db.Model(&entities.MerchantProduct{}).
+			Select("price").
+			Where("merchant_products.id = orders.merchant_product_id")).Error
+}
+
+// backfillProductDeletes turns the deleted_at column of products, which was
+// never used and holds zero dates, into the nullable soft delete column.
+// Left as is every product would count as deleted.
+func backfillProductDeletes(db *gorm.DB) error {
+	columns, err := db.Migrator().ColumnTypes(&entities.MerchantProduct{})
+	if err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		if column.Name() != "deleted_at" {
+			continue
+		}
+
+		if nullable, ok := column.Nullable(); ok && !nullable {
+			if err := db.Migrator().AlterColumn(&entities.MerchantProduct{}, "DeletedAt"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return db.Unscoped().Model(&entities.MerchantProduct{}).
+		Where("deleted_at < ?", "1000-01-01").
+		Update("deleted_at", nil).Error
+}
@@ -29,15 +29,15 @@ func (ctrl AuthController) BasicAuthentication(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
@@ -46,14 +46,14 @@ func (ctrl AuthController) BasicAuthentication(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    oauth,
 	})
 }
@@ -63,15 +63,15 @@ func (ctrl AuthController) ValidateOTP(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
@@ -80,14 +80,115 @@ func (ctrl AuthController) ValidateOTP(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    oauth,
 	})
 }
+
+func (ctrl AuthController) UpdateChannel(c *fiber.Ctx) error {
+	var req dto.ChannelPreference
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	if fail := ctrl.AuthService.UpdateChannel(common.Session(c).UserId, req); fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    req,
+	})
+}
+
+func (ctrl AuthController) DonorRegistration(c *fiber.Ctx) error {
+	var req dto.DonorRegistration
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	donor, fail := ctrl.AuthService.DonorRegistration(req, common.Locale(c))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    donor,
+	})
+}
+
+// DonorOTP sends a donor the OTP to log in with at /auth/verify-otp.
+func (ctrl AuthController) DonorOTP(c *fiber.Ctx) error {
+	var req dto.DonorOTPRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	if fail := ctrl.AuthService.DonorOTP(req); fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    req,
+	})
+}
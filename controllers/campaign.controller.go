@@ -14,14 +14,18 @@ import (
 )
 
 type CampaignController struct {
-	CampaignService *services.CampaignService
+	CampaignService      *services.CampaignService
+	BudgetService        *services.BudgetService
+	MerchantMatchService *services.MerchantMatchService
 }
 
 func NewCampaignController(ctx context.Context) *CampaignController {
 	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
 
 	return &CampaignController{
-		CampaignService: services.NewCampaignService(ctx, db),
+		CampaignService:      services.NewCampaignService(ctx, db),
+		BudgetService:        services.NewBudgetService(ctx, db),
+		MerchantMatchService: services.NewMerchantMatchService(ctx, db),
 	}
 }
 
@@ -30,31 +34,38 @@ func (ctrl CampaignController) CampaignCreate(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
 
-	campaign, fail := ctrl.CampaignService.Create(req)
+	// detonators create campaigns of their own, superadmins pick the detonator
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	campaign, fail := ctrl.CampaignService.Create(ownerId, req)
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    campaign,
 	})
 }
@@ -79,14 +90,14 @@ func (ctrl CampaignController) GetAll(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    campaigns,
 		Meta:    pagination,
 	})
@@ -99,14 +110,33 @@ func (ctrl CampaignController) GetByID(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    campaign,
+	})
+}
+
+func (ctrl CampaignController) GetDetail(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	campaign, fail := ctrl.CampaignService.GetDetail(id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    campaign,
 	})
 }
@@ -118,31 +148,301 @@ func (ctrl CampaignController) CampaignUpdate(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	// superadmins may update any campaign, detonators only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	campaign, fail := ctrl.CampaignService.Update(ownerId, id, req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    campaign,
+	})
+}
+
+func (ctrl CampaignController) CampaignApproval(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req dto.CampaignApproval
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	campaign, fail := ctrl.CampaignService.Approval(id, req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    campaign,
+	})
+}
+
+func (ctrl CampaignController) CampaignSubmit(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	campaign, fail := ctrl.CampaignService.Submit(common.Session(c).UserId, id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    campaign,
+	})
+}
+
+func (ctrl CampaignController) CampaignCancel(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req dto.CampaignCancel
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	// superadmins may cancel any campaign, detonators only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	campaign, fail := ctrl.CampaignService.Cancel(ownerId, id, req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    campaign,
+	})
+}
+
+func (ctrl CampaignController) CampaignBudget(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// only superadmins and the detonator of the campaign see its budget
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	budget, fail := ctrl.BudgetService.Budget(ownerId, id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    budget,
+	})
+}
+
+func (ctrl CampaignController) CampaignBudgetOverride(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req dto.BudgetOverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
 
-	campaign, fail := ctrl.CampaignService.Update(id, req)
+	budget, fail := ctrl.BudgetService.Override(id, req)
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    budget,
+	})
+}
+
+func (ctrl CampaignController) CampaignDelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// superadmins may delete any campaign, detonators only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	if fail := ctrl.CampaignService.Delete(ownerId, id); fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    nil,
+	})
+}
+
+func (ctrl CampaignController) CampaignRestore(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	campaign, fail := ctrl.CampaignService.Restore(id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    campaign,
 	})
 }
+
+func (ctrl CampaignController) GetDeleted(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	campaigns, fail := ctrl.CampaignService.GetDeleted(c, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    campaigns,
+		Meta:    pagination,
+	})
+}
+
+// CampaignMatch suggests merchants and order plans for the ?portions= of a
+// campaign, narrowed down with the catalogue search filters.
+func (ctrl CampaignController) CampaignMatch(c *fiber.Ctx) error {
+	// superadmins may match any campaign, detonators only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	matches, fail := ctrl.MerchantMatchService.Match(c, ownerId, c.Params("id"))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    matches,
+	})
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CampaignReportController struct {
+	CampaignReportService *services.CampaignReportService
+}
+
+func NewCampaignReportController(ctx context.Context) *CampaignReportController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
+	return &CampaignReportController{
+		CampaignReportService: services.NewCampaignReportService(ctx, db),
+	}
+}
+
+func (ctrl CampaignReportController) ReportSubmit(c *fiber.Ctx) error {
+	var req dto.CampaignReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	report, fail := ctrl.CampaignReportService.Submit(common.Session(c).UserId, req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    report,
+	})
+}
+
+func (ctrl CampaignReportController) GetAll(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	reports, fail := ctrl.CampaignReportService.GetAll(c, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    reports,
+		Meta:    pagination,
+	})
+}
+
+func (ctrl CampaignReportController) GetByID(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// superadmins see every report, others only their own and verified ones
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	report, fail := ctrl.CampaignReportService.GetByID(ownerId, id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    report,
+	})
+}
+
+func (ctrl CampaignReportController) ReportReview(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req dto.CampaignReportReview
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	report, fail := ctrl.CampaignReportService.Review(common.Session(c).UserId, id, req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    report,
+	})
+}
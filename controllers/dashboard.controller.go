@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type DashboardController struct {
+	DashboardService *services.DashboardService
+}
+
+func NewDashboardController(ctx context.Context) *DashboardController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
+	return &DashboardController{
+		DashboardService: services.NewDashboardService(ctx, db),
+	}
+}
+
+func (ctrl DashboardController) Registrations(c *fiber.Ctx) error {
+	stats, fail := ctrl.DashboardService.Registrations()
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    stats,
+	})
+}
+
+func (ctrl DashboardController) Campaigns(c *fiber.Ctx) error {
+	stats, fail := ctrl.DashboardService.Campaigns(c)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    stats,
+	})
+}
+
+func (ctrl DashboardController) Orders(c *fiber.Ctx) error {
+	volumes, fail := ctrl.DashboardService.Orders(c)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    volumes,
+	})
+}
+
+func (ctrl DashboardController) Donations(c *fiber.Ctx) error {
+	buckets, fail := ctrl.DashboardService.Donations(c)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    buckets,
+	})
+}
+
+func (ctrl DashboardController) TopMerchants(c *fiber.Ctx) error {
+	merchants, fail := ctrl.DashboardService.TopMerchants(c)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchants,
+	})
+}
@@ -31,7 +31,7 @@ func (ctrl DetonatorController) DetonatorRegistration(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
@@ -43,19 +43,19 @@ func (ctrl DetonatorController) DetonatorRegistration(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramSelfPhotoKey, err),
 		})
 	}
 
 	// Populate files from form data to request variable
-	paramKTPPhotoKey := "self_photo"
+	paramKTPPhotoKey := "ktp_photo"
 
 	KTPPhotoFile, err := c.FormFile(paramKTPPhotoKey)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramKTPPhotoKey, err),
 		})
 	}
@@ -63,26 +63,26 @@ func (ctrl DetonatorController) DetonatorRegistration(c *fiber.Ctx) error {
 	req.SelfPhoto = selfPhotoFile
 	req.KTPPhoto = KTPPhotoFile
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
 
-	detonator, fail := ctrl.DetonatorService.DetonatorRegistration(c, req)
+	detonator, fail := ctrl.DetonatorService.DetonatorRegistration(req, common.Locale(c))
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    detonator,
 	})
 }
@@ -108,14 +108,14 @@ func (ctrl DetonatorController) GetAllDetonator(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    detonators,
 		Meta:    pagination,
 	})
@@ -128,14 +128,14 @@ func (ctrl DetonatorController) GetByID(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    detonator,
 	})
 }
@@ -147,15 +147,15 @@ func (ctrl DetonatorController) DetonatorApproval(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
@@ -164,14 +164,14 @@ func (ctrl DetonatorController) DetonatorApproval(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 	})
 }
 
@@ -182,7 +182,7 @@ func (ctrl DetonatorController) DetonatorUpdate(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
@@ -195,7 +195,7 @@ func (ctrl DetonatorController) DetonatorUpdate(c *fiber.Ctx) error {
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 				Code:    fiber.ErrUnprocessableEntity.Code,
-				Message: fiber.ErrUnprocessableEntity.Message,
+				Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 				Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramSelfPhotoKey, err),
 			})
 		}
@@ -209,7 +209,7 @@ func (ctrl DetonatorController) DetonatorUpdate(c *fiber.Ctx) error {
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 				Code:    fiber.ErrUnprocessableEntity.Code,
-				Message: fiber.ErrUnprocessableEntity.Message,
+				Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 				Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramKTPPhotoKey, err),
 			})
 		}
@@ -217,26 +217,96 @@ func (ctrl DetonatorController) DetonatorUpdate(c *fiber.Ctx) error {
 		req.KTPPhoto = KTPPhotoFile
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
 
-	detonator, fail := ctrl.DetonatorService.DetonatorUpdate(c, id, req)
+	detonator, fail := ctrl.DetonatorService.DetonatorUpdate(id, req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    detonator,
+	})
+}
+
+func (ctrl DetonatorController) DetonatorDelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if fail := ctrl.DetonatorService.Delete(id); fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    nil,
+	})
+}
+
+func (ctrl DetonatorController) DetonatorRestore(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	detonator, fail := ctrl.DetonatorService.Restore(id)
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    detonator,
 	})
 }
+
+func (ctrl DetonatorController) GetDeleted(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	detonators, fail := ctrl.DetonatorService.GetDeleted(&pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    detonators,
+		Meta:    pagination,
+	})
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type DocumentController struct {
+	DocumentService *services.DocumentService
+}
+
+func NewDocumentController(ctx context.Context) *DocumentController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
+	return &DocumentController{
+		DocumentService: services.NewDocumentService(ctx, db),
+	}
+}
+
+// Certificate downloads the PDF certificate of a completed campaign.
+func (ctrl DocumentController) Certificate(c *fiber.Ctx) error {
+	id := c.Params("campaign_id")
+
+	certificate, fail := ctrl.DocumentService.Certificate(id, common.Locale(c))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	c.Attachment(fmt.Sprintf("certificate-%s.pdf", id))
+
+	return c.Send(certificate)
+}
+
+// Verify tells what a receipt or certificate with the code was issued for.
+func (ctrl DocumentController) Verify(c *fiber.Ctx) error {
+	document, fail := ctrl.DocumentService.Verify(c.Params("code"))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    document,
+	})
+}
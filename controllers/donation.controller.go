@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type DonationController struct {
+	DonationService *services.DonationService
+	DocumentService *services.DocumentService
+}
+
+func NewDonationController(ctx context.Context) *DonationController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
+	return &DonationController{
+		DonationService: services.NewDonationService(ctx, db),
+		DocumentService: services.NewDocumentService(ctx, db),
+	}
+}
+
+// DonationCreate records a donation of the logged in donor.
+func (ctrl DonationController) DonationCreate(c *fiber.Ctx) error {
+	return ctrl.create(c, common.Session(c).UserId)
+}
+
+// GuestCreate records a donation of someone without an account.
+func (ctrl DonationController) GuestCreate(c *fiber.Ctx) error {
+	return ctrl.create(c, 0)
+}
+
+func (ctrl DonationController) create(c *fiber.Ctx, userId int) error {
+	var req dto.DonationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	donation, fail := ctrl.DonationService.Create(userId, req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    donation,
+	})
+}
+
+func (ctrl DonationController) DonationReview(c *fiber.Ctx) error {
+	var req dto.DonationReview
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	donation, fail := ctrl.DonationService.Review(common.Session(c).UserId, c.Params("id"), req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    donation,
+	})
+}
+
+func (ctrl DonationController) GetAll(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	donations, fail := ctrl.DonationService.GetAll(c, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    donations,
+		Meta:    pagination,
+	})
+}
+
+// GetMine is the donation history of the logged in donor.
+func (ctrl DonationController) GetMine(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	donations, fail := ctrl.DonationService.GetMine(c, common.Session(c).UserId, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    donations,
+		Meta:    pagination,
+	})
+}
+
+// GetByCampaign lists the donors of a campaign for its public page.
+func (ctrl DonationController) GetByCampaign(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	donations, fail := ctrl.DonationService.GetByCampaign(c.Params("campaign_id"), &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    donations,
+		Meta:    pagination,
+	})
+}
+
+// Receipt downloads the receipt of a paid donation of the logged in donor.
+func (ctrl DonationController) Receipt(c *fiber.Ctx) error {
+	donation, fail := ctrl.DonationService.Receipt(common.Session(c).UserId, c.Params("id"))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return ctrl.download(c, donation)
+}
+
+// GuestReceipt downloads the receipt of a guest donation with the access
+// token given as ?token= when it was made.
+func (ctrl DonationController) GuestReceipt(c *fiber.Ctx) error {
+	donation, fail := ctrl.DonationService.GuestReceipt(c.Params("id"), c.Query("token"))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return ctrl.download(c, donation)
+}
+
+// download sends the PDF receipt of the donation.
+func (ctrl DonationController) download(c *fiber.Ctx, donation *entities.Donation) error {
+	receipt, fail := ctrl.DocumentService.Receipt(donation, common.Locale(c))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	c.Attachment(fmt.Sprintf("receipt-%s.pdf", *donation.ReceiptNumber))
+
+	return c.Send(receipt)
+}
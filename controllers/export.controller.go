@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ExportController struct {
+	ExportService *services.ExportService
+}
+
+func NewExportController(ctx context.Context) *ExportController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
+	return &ExportController{
+		ExportService: services.NewExportService(ctx, db),
+	}
+}
+
+func (ctrl ExportController) Merchants(c *fiber.Ctx) error {
+	unmask := common.Session(c).Role == "superadmin"
+
+	return ctrl.stream(c, "merchants", ctrl.ExportService.Merchants(unmask))
+}
+
+func (ctrl ExportController) Detonators(c *fiber.Ctx) error {
+	unmask := common.Session(c).Role == "superadmin"
+
+	return ctrl.stream(c, "detonators", ctrl.ExportService.Detonators(unmask))
+}
+
+func (ctrl ExportController) Campaigns(c *fiber.Ctx) error {
+	return ctrl.stream(c, "campaigns", ctrl.ExportService.Campaigns(c))
+}
+
+func (ctrl ExportController) Orders(c *fiber.Ctx) error {
+	return ctrl.stream(c, "orders", ctrl.ExportService.Orders(c))
+}
+
+func (ctrl ExportController) Products(c *fiber.Ctx) error {
+	return ctrl.stream(c, "products", ctrl.ExportService.Products(c))
+}
+
+// stream sends the export as a download in the ?format= of csv (the default)
+// or xlsx, writing it out while the records are read.
+func (ctrl ExportController) stream(c *fiber.Ctx, name string, export services.Export) error {
+	format := c.Query("format", enums.ExportFormatCSV)
+
+	contentType, ok := common.SheetContentTypes[format]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   common.ErrorMessage(c, enums.ErrBadParamInput),
+		})
+	}
+
+	c.Attachment(fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format))
+	c.Set(fiber.HeaderContentType, contentType)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		export(common.NewSheetWriter(format, w, name))
+		w.Flush()
+	})
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type FeeRuleController struct {
+	BudgetService *services.BudgetService
+}
+
+func NewFeeRuleController(ctx context.Context) *FeeRuleController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
+	return &FeeRuleController{
+		BudgetService: services.NewBudgetService(ctx, db),
+	}
+}
+
+func (ctrl FeeRuleController) Create(c *fiber.Ctx) error {
+	var req dto.FeeRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	rule, fail := ctrl.BudgetService.CreateRule(req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    rule,
+	})
+}
+
+func (ctrl FeeRuleController) GetAll(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	rules, fail := ctrl.BudgetService.GetRules(c, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    rules,
+		Meta:    pagination,
+	})
+}
+
+func (ctrl FeeRuleController) GetByID(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	rule, fail := ctrl.BudgetService.GetRule(id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    rule,
+	})
+}
+
+func (ctrl FeeRuleController) Update(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req dto.FeeRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	rule, fail := ctrl.BudgetService.UpdateRule(id, req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    rule,
+	})
+}
+
+func (ctrl FeeRuleController) Delete(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if fail := ctrl.BudgetService.DeleteRule(id); fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    nil,
+	})
+}
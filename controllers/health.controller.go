@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const healthCheckTimeout = 5 * time.Second
+
+type HealthController struct {
+	DB   *gorm.DB
+	Mail *services.MonitoredMailTransport
+}
+
+func NewHealthController(ctx context.Context) *HealthController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+	transport := ctx.Value(enums.MailCtxKey).(*services.MonitoredMailTransport)
+
+	return &HealthController{
+		DB:   db,
+		Mail: transport,
+	}
+}
+
+// Health reports the overall state of the application and its dependencies.
+// The response is 503 when the database is unreachable; mail problems only
+// degrade the status since the outbox keeps retrying. The mail state comes from
+// the recorded deliveries, the transport itself is only checked by MailHealth.
+func (ctrl HealthController) Health(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), healthCheckTimeout)
+	defer cancel()
+
+	health := dto.HealthResponse{
+		Status:   enums.HealthStatusUp,
+		Database: enums.HealthStatusUp,
+		Mail:     ctrl.Mail.Status(),
+	}
+
+	if sqlDB, err := ctrl.DB.DB(); err != nil || sqlDB.PingContext(ctx) != nil {
+		health.Database = enums.HealthStatusDown
+	}
+
+	code := fiber.StatusOK
+	switch {
+	case health.Database == enums.HealthStatusDown:
+		health.Status = enums.HealthStatusDown
+		code = fiber.StatusServiceUnavailable
+	case health.Mail != enums.HealthStatusUp:
+		health.Status = enums.HealthStatusDegraded
+	}
+
+	return c.Status(code).JSON(dto.ApiResponse{
+		Code:    code,
+		Message: common.StatusMessage(c, code),
+		Body:    health,
+	})
+}
+
+// MailHealth returns the mail transport details, including the last delivery error.
+func (ctrl HealthController) MailHealth(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), healthCheckTimeout)
+	defer cancel()
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    ctrl.Mail.Health(ctx),
+	})
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"embed"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MailController struct {
+	MailService *services.MailService
+}
+
+func NewMailController(ctx context.Context) *MailController {
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+	transport := ctx.Value(enums.MailCtxKey).(*services.MonitoredMailTransport)
+	template := ctx.Value(enums.TemplateCtxKey).(embed.FS)
+
+	return &MailController{
+		MailService: services.NewMailService(config.SmtpSender, transport, template),
+	}
+}
+
+func (ctrl MailController) GetTemplates(c *fiber.Ctx) error {
+	var templates []dto.MailTemplateResponse
+	for _, tmpl := range services.MailTemplates {
+		templates = append(templates, dto.MailTemplateResponse{
+			Name:     tmpl.Sample.MailTemplate(),
+			Subjects: tmpl.Subject,
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    templates,
+	})
+}
+
+// Preview renders a template with its sample data in the request locale, or
+// the one given with ?locale=. Use ?format=html or ?format=text to get the raw
+// part for viewing in a browser.
+func (ctrl MailController) Preview(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	tmpl, ok := services.FindMailTemplate(name)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ApiResponse{
+			Code:    fiber.ErrNotFound.Code,
+			Message: common.StatusMessage(c, fiber.ErrNotFound.Code),
+			Error:   common.ErrorMessage(c, enums.ErrNotFound),
+		})
+	}
+
+	locale := c.Query("locale")
+	if !common.IsSupportedLocale(locale) {
+		locale = common.Locale(c)
+	}
+
+	rendered, err := ctrl.MailService.Render(locale, tmpl.Sample)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ApiResponse{
+			Code:    fiber.ErrInternalServerError.Code,
+			Message: common.StatusMessage(c, fiber.ErrInternalServerError.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	switch c.Query("format") {
+	case "html":
+		c.Type("html", "utf-8")
+		return c.SendString(rendered.HTML)
+	case "text":
+		c.Type("txt", "utf-8")
+		return c.SendString(rendered.Text)
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body: dto.MailPreviewResponse{
+			Name:    name,
+			Locale:  locale,
+			Subject: rendered.Subject,
+			HTML:    rendered.HTML,
+			Text:    rendered.Text,
+		},
+	})
+}
@@ -7,6 +7,7 @@ import (
 
 	"foodia-be/common"
 	"foodia-be/dto"
+	"foodia-be/enums"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -23,7 +24,7 @@ func (ctrl MediaController) MediaUpload(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
@@ -35,17 +36,17 @@ func (ctrl MediaController) MediaUpload(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramFileKey, err),
 		})
 	}
 
 	req.File = file
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
@@ -55,14 +56,14 @@ func (ctrl MediaController) MediaUpload(c *fiber.Ctx) error {
 	if err := c.SaveFile(req.File, fmt.Sprintf("./storage/%s", fileUrl)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body: dto.MediaResponse{
 			Destination: req.Destination,
 			FileUrl:     fileUrl,
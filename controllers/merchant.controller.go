@@ -15,14 +15,16 @@ import (
 )
 
 type MerchantController struct {
-	MerchantService *services.MerchantService
+	MerchantService             *services.MerchantService
+	MerchantAvailabilityService *services.MerchantAvailabilityService
 }
 
 func NewMerchantController(ctx context.Context) *MerchantController {
 	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
 
 	return &MerchantController{
-		MerchantService: services.NewMerchantService(ctx, db),
+		MerchantService:             services.NewMerchantService(ctx, db),
+		MerchantAvailabilityService: services.NewMerchantAvailabilityService(ctx, db),
 	}
 }
 
@@ -31,7 +33,7 @@ func (ctrl MerchantController) MerchantRegistration(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
@@ -43,19 +45,19 @@ func (ctrl MerchantController) MerchantRegistration(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramSelfPhotoKey, err),
 		})
 	}
 
 	// Populate files from form data to request variable
-	paramKTPPhotoKey := "self_photo"
+	paramKTPPhotoKey := "ktp_photo"
 
 	KTPPhotoFile, err := c.FormFile(paramKTPPhotoKey)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramKTPPhotoKey, err),
 		})
 	}
@@ -63,26 +65,26 @@ func (ctrl MerchantController) MerchantRegistration(c *fiber.Ctx) error {
 	req.SelfPhoto = selfPhotoFile
 	req.KTPPhoto = KTPPhotoFile
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
 
-	merchant, fail := ctrl.MerchantService.MerchantRegistration(c, req)
+	merchant, fail := ctrl.MerchantService.MerchantRegistration(req, common.Locale(c))
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    merchant,
 	})
 }
@@ -108,14 +110,14 @@ func (ctrl MerchantController) GetAllMerchant(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    merchants,
 		Meta:    pagination,
 	})
@@ -128,14 +130,14 @@ func (ctrl MerchantController) GetByID(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    merchant,
 	})
 }
@@ -147,15 +149,15 @@ func (ctrl MerchantController) MerchantApproval(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
@@ -164,14 +166,14 @@ func (ctrl MerchantController) MerchantApproval(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 	})
 }
 
@@ -182,7 +184,7 @@ func (ctrl MerchantController) MerchantUpdate(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
@@ -195,7 +197,7 @@ func (ctrl MerchantController) MerchantUpdate(c *fiber.Ctx) error {
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 				Code:    fiber.ErrUnprocessableEntity.Code,
-				Message: fiber.ErrUnprocessableEntity.Message,
+				Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 				Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramSelfPhotoKey, err),
 			})
 		}
@@ -211,7 +213,7 @@ func (ctrl MerchantController) MerchantUpdate(c *fiber.Ctx) error {
 		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 				Code:    fiber.ErrUnprocessableEntity.Code,
-				Message: fiber.ErrUnprocessableEntity.Message,
+				Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 				Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramKTPPhotoKey, err),
 			})
 		}
@@ -219,26 +221,237 @@ func (ctrl MerchantController) MerchantUpdate(c *fiber.Ctx) error {
 		req.KTPPhoto = KTPPhotoFile
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
 
-	merchant, fail := ctrl.MerchantService.MerchantUpdate(c, id, req)
+	merchant, fail := ctrl.MerchantService.MerchantUpdate(id, req)
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    merchant,
 	})
 }
+
+func (ctrl MerchantController) MerchantDelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if fail := ctrl.MerchantService.Delete(id); fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    nil,
+	})
+}
+
+func (ctrl MerchantController) MerchantRestore(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	merchant, fail := ctrl.MerchantService.Restore(id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchant,
+	})
+}
+
+func (ctrl MerchantController) GetDeleted(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	merchants, fail := ctrl.MerchantService.GetDeleted(&pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchants,
+		Meta:    pagination,
+	})
+}
+
+func (ctrl MerchantController) GetSchedule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	merchant, fail := ctrl.MerchantAvailabilityService.GetSchedule(id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchant,
+	})
+}
+
+func (ctrl MerchantController) MerchantSchedule(c *fiber.Ctx) error {
+	var req dto.MerchantScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	// superadmins may change any merchant, merchants only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	merchant, fail := ctrl.MerchantAvailabilityService.SetSchedule(ownerId, c.Params("id"), req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchant,
+	})
+}
+
+// GetDay tells whether the merchant takes orders on the ?date= and how many
+// portions it has left then.
+func (ctrl MerchantController) GetDay(c *fiber.Ctx) error {
+	day, fail := ctrl.MerchantAvailabilityService.GetDay(c.Params("id"), c.Query("date"))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    day,
+	})
+}
+
+func (ctrl MerchantController) ClosureCreate(c *fiber.Ctx) error {
+	var req dto.MerchantClosureRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	closure, fail := ctrl.MerchantAvailabilityService.AddClosure(ownerId, c.Params("id"), req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    closure,
+	})
+}
+
+func (ctrl MerchantController) ClosureDelete(c *fiber.Ctx) error {
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	if fail := ctrl.MerchantAvailabilityService.DeleteClosure(ownerId, c.Params("id")); fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    nil,
+	})
+}
@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"strconv"
 
 	"foodia-be/common"
@@ -15,6 +17,8 @@ import (
 
 type MerchantProductController struct {
 	MerchantProductService *services.MerchantProductService
+	ProductImportService   *services.ProductImportService
+	ProductPriceService    *services.ProductPriceService
 }
 
 func NewMerchantProductController(ctx context.Context) *MerchantProductController {
@@ -22,6 +26,8 @@ func NewMerchantProductController(ctx context.Context) *MerchantProductControlle
 
 	return &MerchantProductController{
 		MerchantProductService: services.NewMerchantProductService(ctx, db),
+		ProductImportService:   services.NewProductImportService(ctx, db),
+		ProductPriceService:    services.NewProductPriceService(ctx, db),
 	}
 }
 
@@ -30,15 +36,15 @@ func (ctrl MerchantProductController) MerchantProductCreate(c *fiber.Ctx) error
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
@@ -47,14 +53,14 @@ func (ctrl MerchantProductController) MerchantProductCreate(c *fiber.Ctx) error
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    merchantProduct,
 	})
 }
@@ -65,7 +71,7 @@ func (ctrl MerchantProductController) GetByMerchant(c *fiber.Ctx) error {
 	if merchantId == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.StatusBadRequest,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   "merchantId cannot be null!",
 		})
 	}
@@ -89,14 +95,14 @@ func (ctrl MerchantProductController) GetByMerchant(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    merchantProducts,
 		Meta:    pagination,
 	})
@@ -109,14 +115,14 @@ func (ctrl MerchantProductController) GetByID(c *fiber.Ctx) error {
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    merchantProduct,
 	})
 }
@@ -128,31 +134,411 @@ func (ctrl MerchantProductController) MerchantProductUpdate(c *fiber.Ctx) error
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnprocessableEntity.Code,
-			Message: fiber.ErrUnprocessableEntity.Message,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
 			Error:   err.Error(),
 		})
 	}
 
-	if err := common.ValidateRequest(req); err != nil {
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
 			Code:    fiber.ErrBadRequest.Code,
-			Message: fiber.ErrBadRequest.Message,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
 			Error:   err,
 		})
 	}
 
-	merchantProduct, fail := ctrl.MerchantProductService.Update(id, req)
+	// superadmins may update any product, merchants only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	merchantProduct, fail := ctrl.MerchantProductService.Update(ownerId, id, req)
 	if fail != nil {
 		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
 			Code:    fail.StatusCode.Code,
-			Message: fail.StatusCode.Message,
-			Error:   fail.Message,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
 		})
 	}
 
 	return c.JSON(dto.ApiResponse{
 		Code:    fiber.StatusOK,
-		Message: "Successfuly",
+		Message: common.Message(c, enums.MsgSuccess),
 		Body:    merchantProduct,
 	})
 }
+
+// MerchantProductImport imports the products of the uploaded CSV or XLSX file.
+// Merchants import to their own store, superadmins to the merchant_id given.
+func (ctrl MerchantProductController) MerchantProductImport(c *fiber.Ctx) error {
+	var req dto.ProductImportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	paramFileKey := "file"
+
+	file, err := c.FormFile(paramFileKey)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   fmt.Sprintf("error retrieving file from %s parameter: %v", paramFileKey, err),
+		})
+	}
+
+	if file.Size > services.ProductImportMaxSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(dto.ApiResponse{
+			Code:    fiber.StatusRequestEntityTooLarge,
+			Message: common.StatusMessage(c, fiber.StatusRequestEntityTooLarge),
+			Error:   common.ErrorMessage(c, enums.ErrImportFileSize),
+		})
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(io.LimitReader(content, services.ProductImportMaxSize))
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	report, fail := ctrl.ProductImportService.Import(session.UserId, ownerId, req, file.Filename, data, common.Locale(c))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    report,
+	})
+}
+
+// ImportStatus shows the progress and row report of an import.
+func (ctrl MerchantProductController) ImportStatus(c *fiber.Ctx) error {
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	report, fail := ctrl.ProductImportService.GetJob(ownerId, c.Params("id"))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    report,
+	})
+}
+
+func (ctrl MerchantProductController) GetForReview(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	merchantProducts, fail := ctrl.MerchantProductService.GetForReview(c, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchantProducts,
+		Meta:    pagination,
+	})
+}
+
+func (ctrl MerchantProductController) MerchantProductReview(c *fiber.Ctx) error {
+	var req dto.ProductReview
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	merchantProduct, fail := ctrl.MerchantProductService.Review(common.Session(c).UserId, c.Params("id"), req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchantProduct,
+	})
+}
+
+// MerchantProductActivation activates or deactivates an approved product.
+func (ctrl MerchantProductController) MerchantProductActivation(c *fiber.Ctx) error {
+	var req dto.ProductActivation
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	// superadmins may change any product, merchants only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	merchantProduct, fail := ctrl.MerchantProductService.SetActive(ownerId, c.Params("id"), req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchantProduct,
+	})
+}
+
+func (ctrl MerchantProductController) MerchantProductDelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// superadmins may delete any product, merchants only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	if fail := ctrl.MerchantProductService.Delete(ownerId, id); fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    nil,
+	})
+}
+
+func (ctrl MerchantProductController) MerchantProductRestore(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	merchantProduct, fail := ctrl.MerchantProductService.Restore(id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchantProduct,
+	})
+}
+
+func (ctrl MerchantProductController) GetDeleted(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	merchantProducts, fail := ctrl.MerchantProductService.GetDeleted(c, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    merchantProducts,
+		Meta:    pagination,
+	})
+}
+
+// GetPrices lists the price history and the scheduled prices of a product.
+func (ctrl MerchantProductController) GetPrices(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	// superadmins may see the prices of any product, merchants only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	prices, fail := ctrl.ProductPriceService.GetPrices(ownerId, id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    prices,
+	})
+}
+
+func (ctrl MerchantProductController) PriceSchedule(c *fiber.Ctx) error {
+	var req dto.ProductPriceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ApiResponse{
+			Code:    fiber.ErrUnprocessableEntity.Code,
+			Message: common.StatusMessage(c, fiber.ErrUnprocessableEntity.Code),
+			Error:   err.Error(),
+		})
+	}
+
+	if err := common.ValidateRequest(req, common.Locale(c)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ApiResponse{
+			Code:    fiber.ErrBadRequest.Code,
+			Message: common.StatusMessage(c, fiber.ErrBadRequest.Code),
+			Error:   err,
+		})
+	}
+
+	// superadmins may price any product, merchants only their own
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	price, fail := ctrl.ProductPriceService.Schedule(ownerId, c.Params("id"), req)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    price,
+	})
+}
+
+func (ctrl MerchantProductController) PriceCancel(c *fiber.Ctx) error {
+	session := common.Session(c)
+	ownerId := session.UserId
+	if session.Role == "superadmin" {
+		ownerId = 0
+	}
+
+	price, fail := ctrl.ProductPriceService.Cancel(ownerId, c.Params("id"))
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    price,
+	})
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type NotificationController struct {
+	NotificationService *services.NotificationService
+}
+
+func NewNotificationController(ctx context.Context) *NotificationController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
+	return &NotificationController{
+		NotificationService: services.NewNotificationService(ctx, db),
+	}
+}
+
+func (ctrl NotificationController) GetAll(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	notifications, fail := ctrl.NotificationService.GetAll(c, common.Session(c).UserId, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    notifications,
+		Meta:    pagination,
+	})
+}
+
+func (ctrl NotificationController) UnreadCount(c *fiber.Ctx) error {
+	count, fail := ctrl.NotificationService.UnreadCount(common.Session(c).UserId)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    count,
+	})
+}
+
+func (ctrl NotificationController) MarkRead(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	notification, fail := ctrl.NotificationService.MarkRead(common.Session(c).UserId, id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    notification,
+	})
+}
+
+func (ctrl NotificationController) MarkAllRead(c *fiber.Ctx) error {
+	result, fail := ctrl.NotificationService.MarkAllRead(common.Session(c).UserId)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    result,
+	})
+}
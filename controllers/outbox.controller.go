@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type OutboxController struct {
+	OutboxService *services.OutboxService
+}
+
+func NewOutboxController(ctx context.Context) *OutboxController {
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
+	return &OutboxController{
+		OutboxService: services.NewOutboxService(ctx, db),
+	}
+}
+
+func (ctrl OutboxController) GetAll(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil {
+		page = common.DefaultPage
+	}
+
+	perPage, err := strconv.Atoi(c.Query("per_page"))
+	if err != nil {
+		perPage = common.DefaultPerPage
+	}
+
+	pagination := common.Pagination{
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	messages, fail := ctrl.OutboxService.GetAll(c, &pagination)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    messages,
+		Meta:    pagination,
+	})
+}
+
+func (ctrl OutboxController) GetByID(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	message, fail := ctrl.OutboxService.GetByID(id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    message,
+	})
+}
+
+func (ctrl OutboxController) Replay(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	message, fail := ctrl.OutboxService.Replay(id)
+	if fail != nil {
+		return c.Status(fail.StatusCode.Code).JSON(dto.ApiResponse{
+			Code:    fail.StatusCode.Code,
+			Message: common.StatusMessage(c, fail.StatusCode.Code),
+			Error:   common.FailMessage(c, fail.Message),
+		})
+	}
+
+	return c.JSON(dto.ApiResponse{
+		Code:    fiber.StatusOK,
+		Message: common.Message(c, enums.MsgSuccess),
+		Body:    message,
+	})
+}
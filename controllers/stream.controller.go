@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/enums"
+	"foodia-be/services"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultStreamHeartbeat = 25 * time.Second
+
+type StreamController struct {
+	StreamService *services.StreamService
+	Heartbeat     time.Duration
+}
+
+func NewStreamController(ctx context.Context) *StreamController {
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+
+	heartbeat := config.StreamHeartbeat
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+
+	return &StreamController{
+		StreamService: services.NewStreamService(ctx),
+		Heartbeat:     heartbeat,
+	}
+}
+
+// Events streams the events visible to the current user as Server-Sent Events.
+// Clients may narrow the stream with ?topics=order,notification,donation.
+func (ctrl StreamController) Events(c *fiber.Ctx) error {
+	if ctrl.StreamService.Broker == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ApiResponse{
+			Code:    fiber.StatusServiceUnavailable,
+			Message: common.StatusMessage(c, fiber.StatusServiceUnavailable),
+		})
+	}
+
+	userId := common.Session(c).UserId
+
+	topics := map[string]bool{}
+	for _, topic := range strings.Split(c.Query("topics"), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics[topic] = true
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	events, cancel := ctrl.StreamService.Broker.Subscribe()
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		heartbeat := time.NewTicker(ctrl.Heartbeat)
+		defer heartbeat.Stop()
+
+		// tell the client the stream is open before the first event arrives
+		fmt.Fprintf(w, "retry: %d\n: connected\n\n", (5 * time.Second).Milliseconds())
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				if !ctrl.StreamService.Visible(event, userId, topics) {
+					continue
+				}
+
+				data, err := json.Marshal(event)
+				if err != nil {
+					ctrl.StreamService.Log.Error().Msg(err.Error())
+					continue
+				}
+
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Topic, data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// a failing flush means the client went away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
@@ -26,6 +26,9 @@ type OTPRequest struct {
 	OTPCode   string    `json:"otp_code"`
 	ExpiredAt time.Time `json:"expired_at"`
 	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	Channel   string    `json:"channel"`
+	Locale    string    `json:"locale"`
 }
 
 type ValidateOTPRequest struct {
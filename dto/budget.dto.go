@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+type FeeRuleRequest struct {
+	Name      string  `json:"name" validate:"required"`
+	Type      string  `json:"type" validate:"required,oneof=percentage flat"`
+	Base      string  `json:"base" validate:"omitempty,oneof=donation order"`
+	Value     float64 `json:"value" validate:"required,gt=0"`
+	EventType string  `json:"event_type"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+type FeeLine struct {
+	RuleID int             `json:"rule_id"`
+	Name   string          `json:"name"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// CampaignBudget shows where the money of a campaign goes: what donors gave,
+// what the platform keeps and what the orders of the campaign cost.
+type CampaignBudget struct {
+	CampaignID     int             `json:"campaign_id"`
+	DonationTarget decimal.Decimal `json:"donation_target"`
+	Collected      decimal.Decimal `json:"collected"`
+	Fees           []FeeLine       `json:"fees"`
+	FeeTotal       decimal.Decimal `json:"fee_total"`
+	Available      decimal.Decimal `json:"available"`
+	Committed      decimal.Decimal `json:"committed"`
+	Delivered      decimal.Decimal `json:"delivered"`
+	Remaining      decimal.Decimal `json:"remaining"`
+	Override       bool            `json:"override"`
+}
+
+type BudgetOverrideRequest struct {
+	Override *bool  `json:"override" validate:"required"`
+	Note     string `json:"note"`
+}
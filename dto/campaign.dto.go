@@ -1,11 +1,12 @@
 package dto
 
 type CampaignRequest struct {
-	DetonatorID    int     `json:"detonator_id" validate:"required"`
+	DetonatorID    int     `json:"detonator_id"`
 	EventName      string  `json:"event_name" validate:"required"`
 	EventType      string  `json:"event_type" validate:"required"`
-	EventDate      string  `json:"event_date" validate:"required"`
+	EventDate      string  `json:"event_date" validate:"required,datetime=2006-01-02"`
 	EventTime      string  `json:"event_time" validate:"required"`
+	Timezone       string  `json:"timezone" validate:"omitempty,oneof=WIB WITA WIT"`
 	Description    string  `json:"description" validate:"required"`
 	DonationTarget float64 `json:"donation_target" validate:"required"`
 	Province       string  `json:"province" validate:"required"`
@@ -16,7 +17,18 @@ type CampaignRequest struct {
 	Latitude       string  `json:"latitude" validate:"required"`
 	Longitude      string  `json:"longitude" validate:"required"`
 	ImageURL       string  `json:"image_url" validate:"required"`
+	IsDraft        bool    `json:"is_draft"`
 	Products       []struct {
-		MerchantProductID int `json:"merchant_product_id"`
-	} `json:"products"`
+		MerchantProductID int  `json:"merchant_product_id"`
+		Qty               *int `json:"qty" validate:"omitempty,gte=1"`
+	} `json:"products" validate:"dive"`
+}
+
+type CampaignApproval struct {
+	Status string `json:"status" validate:"required,oneof=approved rejected"`
+	Note   string `json:"note"`
+}
+
+type CampaignCancel struct {
+	Note string `json:"note" validate:"required"`
 }
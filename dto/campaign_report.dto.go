@@ -0,0 +1,15 @@
+package dto
+
+type CampaignReportRequest struct {
+	CampaignID    int      `json:"campaign_id" validate:"required"`
+	Beneficiaries int      `json:"beneficiaries" validate:"required,min=1"`
+	Latitude      *float64 `json:"latitude" validate:"required,latitude"`
+	Longitude     *float64 `json:"longitude" validate:"required,longitude"`
+	Notes         string   `json:"notes"`
+	Photos        []string `json:"photos" validate:"required,min=1,dive,required"`
+}
+
+type CampaignReportReview struct {
+	Status string `json:"status" validate:"required,oneof=verified rejected"`
+	Note   string `json:"note"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+// Recipient is who a notification is addressed to and how they prefer to receive it.
+type Recipient struct {
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Locale  string `json:"locale"`
+	Channel string `json:"channel"`
+}
+
+// ShortMessagePayload is the outbox payload of SMS and WhatsApp messages. The
+// fallback email receives the regular email when the channel fails.
+type ShortMessagePayload struct {
+	FallbackEmail string `json:"fallback_email"`
+	Data          any    `json:"data"`
+}
+
+type ChannelPreference struct {
+	Channel string `json:"channel" validate:"required,oneof=email sms whatsapp"`
+}
@@ -0,0 +1,46 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+type StatusCount struct {
+	Status string `json:"status"`
+	Total  int64  `json:"total"`
+}
+
+type ProvinceCount struct {
+	Province string `json:"province"`
+	Total    int64  `json:"total"`
+}
+
+type RegistrationStats struct {
+	Merchants  []StatusCount `json:"merchants"`
+	Detonators []StatusCount `json:"detonators"`
+}
+
+type CampaignStats struct {
+	ByStatus   []StatusCount   `json:"by_status"`
+	ByProvince []ProvinceCount `json:"by_province"`
+}
+
+type OrderVolume struct {
+	Status   string          `json:"status"`
+	Orders   int64           `json:"orders"`
+	Portions int64           `json:"portions"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+// DonationBucket sums the paid donations of one day, week or month, starting
+// on the date of Period.
+type DonationBucket struct {
+	Period    string          `json:"period"`
+	Donations int64           `json:"donations"`
+	Amount    decimal.Decimal `json:"amount"`
+}
+
+type TopMerchant struct {
+	MerchantID int             `json:"merchant_id"`
+	Fullname   string          `json:"fullname"`
+	Orders     int64           `json:"orders"`
+	Portions   int64           `json:"portions"`
+	Amount     decimal.Decimal `json:"amount"`
+}
@@ -8,6 +8,7 @@ type DetonatorRegistration struct {
 	Email     string                `json:"email" form:"email" validate:"required"`
 	Password  string                `json:"password" form:"password" validate:"required"`
 	KTPNumber string                `json:"ktp_number" form:"ktp_number" validate:"required"`
+	Channel   string                `json:"channel" form:"channel" validate:"omitempty,oneof=email sms whatsapp"`
 	SelfPhoto *multipart.FileHeader `json:"self_photo" form:"self_photo" validate:"required"`
 	KTPPhoto  *multipart.FileHeader `json:"ktp_photo" form:"ktp_photo" validate:"required"`
 }
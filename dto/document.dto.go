@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DocumentVerification is what a verification code was issued for, to compare
+// with the receipt or certificate that carries it.
+type DocumentVerification struct {
+	Code     string          `json:"code"`
+	Type     string          `json:"type"`
+	Number   string          `json:"number"`
+	Holder   string          `json:"holder"`
+	Subject  string          `json:"subject"`
+	Amount   decimal.Decimal `json:"amount"`
+	IssuedAt time.Time       `json:"issued_at"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type DonorRegistration struct {
+	Fullname string `json:"fullname" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Phone    string `json:"phone" validate:"required,numeric,max=15"`
+}
+
+type DonorOTPRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// DonationRequest is a donation to a campaign. Guests give their name and
+// email for the receipt; donors that are logged in use their account.
+type DonationRequest struct {
+	CampaignID  int     `json:"campaign_id" validate:"required"`
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	Name        string  `json:"name" validate:"omitempty,max=100"`
+	Email       string  `json:"email" validate:"omitempty,email"`
+	IsAnonymous bool    `json:"is_anonymous"`
+	Message     string  `json:"message"`
+}
+
+type DonationReview struct {
+	Status string `json:"status" validate:"required,oneof=paid failed"`
+	Note   string `json:"note"`
+}
+
+// DonationCreated carries the access token a guest needs to download the
+// receipt of the donation later.
+type DonationCreated struct {
+	ID          int             `json:"id"`
+	CampaignID  int             `json:"campaign_id"`
+	Amount      decimal.Decimal `json:"amount"`
+	Status      string          `json:"status"`
+	AccessToken string          `json:"access_token,omitempty"`
+}
+
+// PublicDonation is a paid donation as listed on the campaign page.
+type PublicDonation struct {
+	Name    string          `json:"name"`
+	Amount  decimal.Decimal `json:"amount"`
+	Message string          `json:"message"`
+	PaidAt  *time.Time      `json:"paid_at"`
+}
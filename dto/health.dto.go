@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type HealthResponse struct {
+	Status   string `json:"status"`
+	Database string `json:"database"`
+	Mail     string `json:"mail"`
+}
+
+type MailHealth struct {
+	Transport     string     `json:"transport"`
+	Status        string     `json:"status"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	LastFailureAt *time.Time `json:"last_failure_at"`
+	LastError     string     `json:"last_error,omitempty"`
+}
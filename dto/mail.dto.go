@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MailData is implemented by the typed payload of every transactional email.
+// MailTemplate returns the template name used to render it.
+type MailData interface {
+	MailTemplate() string
+}
+
+type OTPMail struct {
+	Code string `json:"code"`
+}
+
+type WelcomeMail struct {
+	Fullname string `json:"fullname"`
+}
+
+type RegistrationReceivedMail struct {
+	Fullname string `json:"fullname"`
+	Role     string `json:"role"`
+}
+
+type RegistrationApprovedMail struct {
+	Fullname string `json:"fullname"`
+	Role     string `json:"role"`
+	Note     string `json:"note"`
+}
+
+type RegistrationRejectedMail struct {
+	Fullname string `json:"fullname"`
+	Role     string `json:"role"`
+	Note     string `json:"note"`
+}
+
+type CampaignApprovedMail struct {
+	Fullname  string `json:"fullname"`
+	EventName string `json:"event_name"`
+	EventDate string `json:"event_date"`
+}
+
+type OrderReceivedMail struct {
+	MerchantName string `json:"merchant_name"`
+	OrderID      int    `json:"order_id"`
+	EventName    string `json:"event_name"`
+	EventDate    string `json:"event_date"`
+	ProductName  string `json:"product_name"`
+	Quantity     int    `json:"quantity"`
+	Address      string `json:"address"`
+}
+
+type OrderStatusChangedMail struct {
+	Fullname    string `json:"fullname"`
+	OrderID     int    `json:"order_id"`
+	EventName   string `json:"event_name"`
+	ProductName string `json:"product_name"`
+	Status      string `json:"status"`
+	Note        string `json:"note"`
+}
+
+type DonationReceiptMail struct {
+	DonorName     string          `json:"donor_name"`
+	ReceiptNumber string          `json:"receipt_number"`
+	EventName     string          `json:"event_name"`
+	Amount        decimal.Decimal `json:"amount"`
+	DonatedAt     time.Time       `json:"donated_at"`
+}
+
+type MailTemplateResponse struct {
+	Name     string            `json:"name"`
+	Subjects map[string]string `json:"subjects"`
+}
+
+type MailPreviewResponse struct {
+	Name    string `json:"name"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+func (OTPMail) MailTemplate() string                  { return "otp" }
+func (WelcomeMail) MailTemplate() string              { return "welcome" }
+func (RegistrationReceivedMail) MailTemplate() string { return "registration_received" }
+func (RegistrationApprovedMail) MailTemplate() string { return "registration_approved" }
+func (RegistrationRejectedMail) MailTemplate() string { return "registration_rejected" }
+func (CampaignApprovedMail) MailTemplate() string     { return "campaign_approved" }
+func (OrderReceivedMail) MailTemplate() string        { return "order_received" }
+func (OrderStatusChangedMail) MailTemplate() string   { return "order_status_changed" }
+func (DonationReceiptMail) MailTemplate() string      { return "donation_receipt" }
@@ -3,7 +3,7 @@ package dto
 import "mime/multipart"
 
 type MediaRequest struct {
-	Destination string                `json:"destination" form:"destination" validate:"required,oneof=campaign merchant detonator product"`
+	Destination string                `json:"destination" form:"destination" validate:"required,oneof=campaign merchant detonator product report"`
 	File        *multipart.FileHeader `json:"file" form:"file" validate:"required"`
 }
 
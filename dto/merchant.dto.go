@@ -3,21 +3,23 @@ package dto
 import "mime/multipart"
 
 type MerchantRegistration struct {
-	Fullname    string                `json:"fullname" form:"fullname" validate:"required"`
-	Phone       string                `json:"phone" form:"phone" validate:"required"`
-	Email       string                `json:"email" form:"email" validate:"required"`
-	Password    string                `json:"password" form:"password" validate:"required"`
-	Province    string                `json:"province" form:"province" validate:"required"`
-	City        string                `json:"city" form:"city" validate:"required"`
-	SubDistrict string                `json:"sub_district" form:"sub_district" validate:"required"`
-	PostalCode  string                `json:"postal_code" form:"postal_code" validate:"required"`
-	Address     string                `json:"address" form:"address" validate:"required"`
-	Latitude    string                `json:"latitude" form:"latitude" validate:"required"`
-	Longitude   string                `json:"longitude" form:"longitude" validate:"required"`
-	NoLinkAja   string                `json:"no_link_aja" form:"no_link_aja" validate:"required"`
-	KTPNumber   string                `json:"ktp_number" form:"ktp_number" validate:"required"`
-	SelfPhoto   *multipart.FileHeader `json:"self_photo" form:"self_photo" validate:"required"`
-	KTPPhoto    *multipart.FileHeader `json:"ktp_photo" form:"ktp_photo" validate:"required"`
+	Fullname       string                `json:"fullname" form:"fullname" validate:"required"`
+	Phone          string                `json:"phone" form:"phone" validate:"required"`
+	Email          string                `json:"email" form:"email" validate:"required"`
+	Password       string                `json:"password" form:"password" validate:"required"`
+	Province       string                `json:"province" form:"province" validate:"required"`
+	City           string                `json:"city" form:"city" validate:"required"`
+	SubDistrict    string                `json:"sub_district" form:"sub_district" validate:"required"`
+	PostalCode     string                `json:"postal_code" form:"postal_code" validate:"required"`
+	Address        string                `json:"address" form:"address" validate:"required"`
+	Latitude       string                `json:"latitude" form:"latitude" validate:"required"`
+	Longitude      string                `json:"longitude" form:"longitude" validate:"required"`
+	NoLinkAja      string                `json:"no_link_aja" form:"no_link_aja" validate:"required"`
+	KTPNumber      string                `json:"ktp_number" form:"ktp_number" validate:"required"`
+	DeliveryRadius int                   `json:"delivery_radius" form:"delivery_radius" validate:"min=0"`
+	Channel        string                `json:"channel" form:"channel" validate:"omitempty,oneof=email sms whatsapp"`
+	SelfPhoto      *multipart.FileHeader `json:"self_photo" form:"self_photo" validate:"required"`
+	KTPPhoto       *multipart.FileHeader `json:"ktp_photo" form:"ktp_photo" validate:"required"`
 }
 
 type MerchantApproval struct {
@@ -26,19 +28,53 @@ type MerchantApproval struct {
 }
 
 type MerchantUpdate struct {
-	Fullname    string                `json:"fullname" form:"fullname"`
-	Phone       string                `json:"phone" form:"phone"`
-	Email       string                `json:"email" form:"email"`
-	Password    string                `json:"password" form:"password"`
-	Province    string                `json:"province" form:"province"`
-	City        string                `json:"city" form:"city"`
-	SubDistrict string                `json:"sub_district" form:"sub_district"`
-	PostalCode  string                `json:"postal_code" form:"postal_code"`
-	Address     string                `json:"address" form:"address"`
-	Latitude    string                `json:"latitude" form:"latitude"`
-	Longitude   string                `json:"longitude" form:"longitude"`
-	NoLinkAja   string                `json:"no_link_aja" form:"no_link_aja"`
-	KTPNumber   string                `json:"ktp_number" form:"ktp_number"`
-	SelfPhoto   *multipart.FileHeader `json:"self_photo" form:"self_photo"`
-	KTPPhoto    *multipart.FileHeader `json:"ktp_photo" form:"ktp_photo"`
+	Fullname       string                `json:"fullname" form:"fullname"`
+	Phone          string                `json:"phone" form:"phone"`
+	Email          string                `json:"email" form:"email"`
+	Password       string                `json:"password" form:"password"`
+	Province       string                `json:"province" form:"province"`
+	City           string                `json:"city" form:"city"`
+	SubDistrict    string                `json:"sub_district" form:"sub_district"`
+	PostalCode     string                `json:"postal_code" form:"postal_code"`
+	Address        string                `json:"address" form:"address"`
+	Latitude       string                `json:"latitude" form:"latitude"`
+	Longitude      string                `json:"longitude" form:"longitude"`
+	NoLinkAja      string                `json:"no_link_aja" form:"no_link_aja"`
+	KTPNumber      string                `json:"ktp_number" form:"ktp_number"`
+	DeliveryRadius int                   `json:"delivery_radius" form:"delivery_radius" validate:"min=0"`
+	SelfPhoto      *multipart.FileHeader `json:"self_photo" form:"self_photo"`
+	KTPPhoto       *multipart.FileHeader `json:"ktp_photo" form:"ktp_photo"`
+}
+
+type MerchantHourRequest struct {
+	Weekday  int    `json:"weekday" validate:"min=0,max=6"`
+	OpensAt  string `json:"opens_at" validate:"required,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"required,datetime=15:04"`
+}
+
+// MerchantScheduleRequest replaces the weekly hours of a merchant, an empty
+// list opening it every day, and sets its daily capacity, 0 for no limit.
+type MerchantScheduleRequest struct {
+	DailyCapacity int                   `json:"daily_capacity" validate:"min=0"`
+	Hours         []MerchantHourRequest `json:"hours" validate:"max=7,dive"`
+}
+
+type MerchantClosureRequest struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Note      string `json:"note" validate:"max=255"`
+}
+
+// MerchantDay is whether a merchant takes orders on a date and how many
+// portions it still has room for, Remaining being -1 without a capacity.
+type MerchantDay struct {
+	MerchantID int    `json:"merchant_id"`
+	Date       string `json:"date"`
+	Open       bool   `json:"open"`
+	OpensAt    string `json:"opens_at,omitempty"`
+	ClosesAt   string `json:"closes_at,omitempty"`
+	Closure    string `json:"closure,omitempty"`
+	Capacity   int    `json:"capacity"`
+	Booked     int    `json:"booked"`
+	Remaining  int    `json:"remaining"`
 }
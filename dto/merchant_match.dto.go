@@ -0,0 +1,30 @@
+package dto
+
+import "github.com/shopspring/decimal"
+
+// MerchantMatch is a merchant suggested for a campaign, with the orders that
+// would feed as many of the portions asked for as it can.
+type MerchantMatch struct {
+	MerchantID     int             `json:"merchant_id"`
+	Name           string          `json:"name"`
+	City           string          `json:"city"`
+	Distance       float64         `json:"distance"`
+	DeliveryRadius int             `json:"delivery_radius"`
+	Capacity       int             `json:"capacity"`
+	Remaining      int             `json:"remaining"`
+	Products       int             `json:"products"`
+	Portions       int             `json:"portions"`
+	Complete       bool            `json:"complete"`
+	Total          decimal.Decimal `json:"total"`
+	WithinBudget   bool            `json:"within_budget"`
+	Plan           []MatchOrder    `json:"plan"`
+}
+
+// MatchOrder is an order of a suggested plan, ready to be placed as is.
+type MatchOrder struct {
+	MerchantProductID int             `json:"merchant_product_id"`
+	Name              string          `json:"name"`
+	Qty               int             `json:"qty"`
+	Price             decimal.Decimal `json:"price"`
+	Total             decimal.Decimal `json:"total"`
+}
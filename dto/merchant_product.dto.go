@@ -1,12 +1,62 @@
 package dto
 
+import "time"
+
 type MerchantProductRequest struct {
-	MerchantID  int     `json:"merchant_id" validate:"required"`
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Price       float64 `json:"price" validate:"required"`
-	QTY         int     `json:"qty" validate:"required"`
-	Images      []struct {
+	MerchantID   int      `json:"merchant_id" validate:"required"`
+	Name         string   `json:"name" validate:"required"`
+	Description  string   `json:"description" validate:"required"`
+	Price        float64  `json:"price" validate:"required"`
+	QTY          int      `json:"qty" validate:"required"`
+	CategoryID   int      `json:"category_id"`
+	PortionGrams int      `json:"portion_grams" validate:"omitempty,gt=0"`
+	Packaging    string   `json:"packaging" validate:"omitempty,oneof=box paper_wrap banana_leaf plastic container"`
+	Tags         []string `json:"tags" validate:"omitempty,max=10,dive,required,max=50"`
+	Dietary      []string `json:"dietary" validate:"omitempty,dive,oneof=halal vegetarian vegan gluten_free dairy_free"`
+	Allergens    []string `json:"allergens" validate:"omitempty,dive,oneof=peanut tree_nut dairy egg fish shellfish soy wheat sesame"`
+	Images       []struct {
 		ImageURL string `json:"image_url"`
 	} `json:"images"`
 }
+
+type ProductReview struct {
+	Status string `json:"status" validate:"required,oneof=approved rejected"`
+	Note   string `json:"note"`
+}
+
+type ProductActivation struct {
+	IsActive *bool `json:"is_active" validate:"required"`
+}
+
+type ProductPriceRequest struct {
+	Kind     string     `json:"kind" validate:"required,oneof=regular promo"`
+	Price    float64    `json:"price" validate:"required,gt=0"`
+	StartsAt time.Time  `json:"starts_at" validate:"required"`
+	EndsAt   *time.Time `json:"ends_at" validate:"required_if=Kind promo"`
+	Note     string     `json:"note" validate:"max=255"`
+}
+
+type ProductCategoryRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Total int64  `json:"total"`
+}
+
+type CategoryFacet struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Total int64  `json:"total"`
+}
+
+// ProductFacets counts the products of a search per value of each filter.
+type ProductFacets struct {
+	Categories []CategoryFacet `json:"categories"`
+	Tags       []FacetCount    `json:"tags"`
+	Dietary    []FacetCount    `json:"dietary"`
+	Allergens  []FacetCount    `json:"allergens"`
+	Packaging  []FacetCount    `json:"packaging"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+// NotificationEvent is something that happened to a record and is shown in the
+// notification center of the users concerned. Params fill in the title and
+// message templates of the event type.
+type NotificationEvent struct {
+	Type    string
+	RefType string
+	RefID   int
+	Params  map[string]any
+}
+
+type NotificationCount struct {
+	Unread int64            `json:"unread"`
+	ByType map[string]int64 `json:"by_type"`
+}
+
+type NotificationReadAll struct {
+	Updated int64 `json:"updated"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type OrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=approved rejected"`
+	Note   string `json:"note"`
+}
+
+// DeliveryClaims are signed into the QR token the detonator shows at handoff.
+type DeliveryClaims struct {
+	OrderID    int    `json:"order_id"`
+	CampaignID int    `json:"campaign_id"`
+	Nonce      string `json:"nonce"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+type DeliveryToken struct {
+	OrderID   int       `json:"order_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type DeliveryScanRequest struct {
+	Token      string   `json:"token" validate:"required"`
+	CampaignID int      `json:"campaign_id" validate:"required"`
+	Latitude   *float64 `json:"latitude" validate:"required,latitude"`
+	Longitude  *float64 `json:"longitude" validate:"required,longitude"`
+}
+
+type OrderRequest struct {
+	CampaignID        int `json:"campaign_id" validate:"required"`
+	MerchantProductID int `json:"merchant_product_id" validate:"required"`
+	Qty               int `json:"qty" validate:"required,min=1"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PayoutBatchRequest struct {
+	MerchantIDs []int   `json:"merchant_ids"`
+	MinAmount   float64 `json:"min_amount" validate:"omitempty,min=0"`
+}
+
+// PayoutTransfer is a disbursement asked from the payout provider.
+type PayoutTransfer struct {
+	Reference string
+	Account   string
+	Amount    decimal.Decimal
+	Note      string
+}
+
+// PayoutResult is the state of a disbursement as reported by the provider.
+type PayoutResult struct {
+	Reference string
+	Status    string
+	Message   string
+}
+
+type MerchantStatement struct {
+	MerchantID int                     `json:"merchant_id"`
+	From       time.Time               `json:"from"`
+	To         time.Time               `json:"to"`
+	Opening    decimal.Decimal         `json:"opening_balance"`
+	Accrued    decimal.Decimal         `json:"accrued"`
+	PaidOut    decimal.Decimal         `json:"paid_out"`
+	Closing    decimal.Decimal         `json:"closing_balance"`
+	InTransit  decimal.Decimal         `json:"in_transit"`
+	Lines      []MerchantStatementLine `json:"lines"`
+}
+
+type MerchantStatementLine struct {
+	Date        time.Time       `json:"date"`
+	Type        string          `json:"type"`
+	RefType     string          `json:"ref_type"`
+	RefID       int             `json:"ref_id"`
+	Description string          `json:"description"`
+	Debit       decimal.Decimal `json:"debit"`
+	Credit      decimal.Decimal `json:"credit"`
+	Balance     decimal.Decimal `json:"balance"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type ProductImportRequest struct {
+	MerchantID int  `form:"merchant_id" json:"merchant_id"`
+	DryRun     bool `form:"dry_run" json:"dry_run"`
+	Async      bool `form:"async" json:"async"`
+}
+
+// ProductImportRow is the outcome of one spreadsheet row, numbered like the
+// spreadsheet with the header as row 1.
+type ProductImportRow struct {
+	Row       int             `json:"row"`
+	Name      string          `json:"name"`
+	Valid     bool            `json:"valid"`
+	Errors    []ApiFieldError `json:"errors,omitempty"`
+	ProductID int             `json:"product_id,omitempty"`
+}
+
+type ProductImportReport struct {
+	JobID      int                `json:"job_id"`
+	MerchantID int                `json:"merchant_id"`
+	Filename   string             `json:"filename"`
+	Status     string             `json:"status"`
+	DryRun     bool               `json:"dry_run"`
+	Total      int                `json:"total"`
+	Valid      int                `json:"valid"`
+	Invalid    int                `json:"invalid"`
+	Created    int                `json:"created"`
+	Error      string             `json:"error,omitempty"`
+	Rows       []ProductImportRow `json:"rows"`
+	CreatedAt  time.Time          `json:"created_at"`
+	FinishedAt *time.Time         `json:"finished_at"`
+}
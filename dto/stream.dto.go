@@ -0,0 +1,25 @@
+package dto
+
+// StreamEvent is pushed to subscribed clients of the event stream. Events with
+// a UserId are only delivered to that user, the others to everyone.
+type StreamEvent struct {
+	ID     string `json:"id"`
+	Topic  string `json:"topic"`
+	Type   string `json:"type"`
+	UserId int    `json:"-"`
+	Data   any    `json:"data"`
+}
+
+type OrderStatusEvent struct {
+	OrderID     int    `json:"order_id"`
+	CampaignID  int    `json:"campaign_id"`
+	OrderStatus string `json:"order_status"`
+	Note        string `json:"note"`
+}
+
+type DonationProgressEvent struct {
+	CampaignID     int     `json:"campaign_id"`
+	DonationTarget float64 `json:"donation_target"`
+	Collected      float64 `json:"collected"`
+	Donors         int64   `json:"donors"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Secret      string   `json:"secret" validate:"omitempty,min=16"`
+	Events      []string `json:"events" validate:"required,min=1,dive,oneof=campaign.approved campaign.funded campaign.completed"`
+	Description string   `json:"description"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// WebhookPayload is the body posted to subscribers. ID is stable across
+// retries and redeliveries so receivers can deduplicate.
+type WebhookPayload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type WebhookCampaignData struct {
+	ID             int             `json:"id"`
+	EventName      string          `json:"event_name"`
+	EventType      string          `json:"event_type"`
+	EventDate      string          `json:"event_date"`
+	EventTime      string          `json:"event_time"`
+	DonationTarget decimal.Decimal `json:"donation_target"`
+	Province       string          `json:"province"`
+	City           string          `json:"city"`
+	Status         string          `json:"status"`
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type Campaign struct {
@@ -13,8 +14,11 @@ type Campaign struct {
 	EventType      string          `json:"event_type"`
 	EventDate      string          `json:"event_date"`
 	EventTime      string          `json:"event_time"`
+	EventAt        *time.Time      `gorm:"index" json:"event_at"`
+	Timezone       string          `gorm:"type:varchar(5);not null;default:'WIB'" json:"timezone"`
 	Description    string          `json:"description"`
 	DonationTarget decimal.Decimal `json:"donation_target"`
+	Collected      decimal.Decimal `gorm:"type:decimal(15,2);not null;default:0" json:"collected"`
 	Province       string          `json:"province"`
 	City           string          `json:"city"`
 	SubDistrict    string          `json:"sub_district"`
@@ -22,11 +26,16 @@ type Campaign struct {
 	Address        string          `json:"address"`
 	Latitude       string          `json:"latitude"`
 	Longitude      string          `json:"longitude"`
-	Status         string          `gorm:"default:'waiting'" json:"status"`
+	Status         string          `gorm:"default:'review';index" json:"status"`
+	StatusNote     string          `gorm:"type:text" json:"status_note"`
+	StatusAt       *time.Time      `json:"status_at"`
 	IsActive       bool            `gorm:"default:false" json:"is_active"`
+	BudgetOverride bool            `gorm:"not null;default:false" json:"budget_override"`
 	ImageURL       string          `json:"image_url"`
 	CreatedAt      time.Time       `gorm:"default:current_timestamp()"  json:"created_at"`
 	UpdatedAt      time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `gorm:"index" json:"deleted_at"`
 
-	Detonator *Detonator `gorm:"foreignKey:ID;references:DetonatorID" json:"detonator"`
+	Detonator *Detonator      `gorm:"foreignKey:ID;references:DetonatorID" json:"detonator"`
+	Report    *CampaignReport `gorm:"foreignKey:CampaignID;references:ID" json:"report,omitempty"`
 }
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+)
+
+type CampaignReport struct {
+	ID              int        `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	CampaignID      int        `gorm:"type:int(11);not null;uniqueIndex" json:"campaign_id"`
+	DetonatorID     int        `gorm:"type:int(11);not null;index" json:"detonator_id"`
+	Beneficiaries   int        `gorm:"not null" json:"beneficiaries"`
+	Latitude        float64    `gorm:"type:decimal(10,7)" json:"latitude"`
+	Longitude       float64    `gorm:"type:decimal(10,7)" json:"longitude"`
+	DistanceMeters  float64    `json:"distance_meters"`
+	LocationChecked bool       `json:"location_checked"`
+	Notes           string     `gorm:"type:text" json:"notes"`
+	Status          string     `gorm:"type:varchar(50);default:'submitted';index" json:"status"`
+	ReviewNote      string     `gorm:"type:text" json:"review_note"`
+	ReviewedBy      int        `gorm:"type:int(11)" json:"reviewed_by"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+	CreatedAt       time.Time  `gorm:"default:current_timestamp()"  json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"default:current_timestamp()" json:"updated_at"`
+
+	Photos []CampaignReportPhoto `gorm:"foreignKey:CampaignReportID;references:ID" json:"photos"`
+}
+
+type CampaignReportPhoto struct {
+	ID               int       `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	CampaignReportID int       `gorm:"type:int(11);not null;index" json:"campaign_report_id"`
+	ImageURL         string    `gorm:"type:varchar(255);not null" json:"image_url"`
+	CreatedAt        time.Time `gorm:"default:current_timestamp()"  json:"created_at"`
+}
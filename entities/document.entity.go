@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Document is an issued receipt or certificate. It keeps what the document
+// says so its verification code can be checked against it later.
+type Document struct {
+	ID        int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	Type      string          `gorm:"type:varchar(20);not null;uniqueIndex:idx_document_reference" json:"type"`
+	RefID     int             `gorm:"type:int(11);not null;uniqueIndex:idx_document_reference" json:"ref_id"`
+	Code      string          `gorm:"type:varchar(20);not null;uniqueIndex" json:"code"`
+	Number    string          `gorm:"type:varchar(32)" json:"number"`
+	Holder    string          `gorm:"type:varchar(100)" json:"holder"`
+	Subject   string          `gorm:"type:varchar(255)" json:"subject"`
+	Amount    decimal.Decimal `gorm:"type:decimal(15,2);not null;default:0" json:"amount"`
+	IssuedAt  time.Time       `json:"issued_at"`
+	CreatedAt time.Time       `gorm:"default:current_timestamp()" json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Donation is money given to a campaign, by a donor account or a guest. The
+// receipt number is only issued once the payment is confirmed.
+type Donation struct {
+	ID            int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	CampaignID    int             `gorm:"type:int(11);not null;index" json:"campaign_id"`
+	DonorID       int             `gorm:"type:int(11);index" json:"donor_id"`
+	Name          string          `gorm:"type:varchar(100)" json:"name"`
+	Email         string          `gorm:"type:varchar(100)" json:"email"`
+	IsAnonymous   bool            `gorm:"not null;default:false" json:"is_anonymous"`
+	Amount        decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Message       string          `gorm:"type:text" json:"message"`
+	Status        string          `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Note          string          `gorm:"type:text" json:"note"`
+	ReceiptNumber *string         `gorm:"type:varchar(32);uniqueIndex" json:"receipt_number"`
+	AccessToken   string          `gorm:"type:varchar(64)" json:"-"`
+	ReviewedBy    int             `gorm:"type:int(11)" json:"reviewed_by"`
+	PaidAt        *time.Time      `gorm:"index" json:"paid_at"`
+	CreatedAt     time.Time       `gorm:"default:current_timestamp()"  json:"created_at"`
+	UpdatedAt     time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
+
+	Campaign *Campaign `gorm:"foreignKey:ID;references:CampaignID;-:migration" json:"campaign,omitempty"`
+}
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FeeRule is a platform fee charged on a campaign, either a percentage of its
+// donations or order costs, or a flat amount per campaign. Rules with an event
+// type only apply to campaigns of that type.
+type FeeRule struct {
+	ID        int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	Name      string          `gorm:"type:varchar(100);not null" json:"name"`
+	Type      string          `gorm:"type:varchar(20);not null" json:"type"`
+	Base      string          `gorm:"type:varchar(20);not null" json:"base"`
+	Value     decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"value"`
+	EventType string          `gorm:"type:varchar(100);index" json:"event_type"`
+	IsActive  bool            `gorm:"not null;index" json:"is_active"`
+	CreatedAt time.Time       `gorm:"default:current_timestamp()" json:"created_at"`
+	UpdatedAt time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
+}
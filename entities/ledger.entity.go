@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// LedgerTransaction groups the balanced entries of one money movement. The
+// reference is unique per type so the same event is never booked twice.
+type LedgerTransaction struct {
+	ID          int       `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	Type        string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_ledger_reference" json:"type"`
+	RefType     string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_ledger_reference" json:"ref_type"`
+	RefID       int       `gorm:"type:int(11);not null;uniqueIndex:idx_ledger_reference" json:"ref_id"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	CreatedAt   time.Time `gorm:"default:current_timestamp()" json:"created_at"`
+
+	Entries []LedgerEntry `gorm:"foreignKey:TransactionID;references:ID" json:"entries,omitempty"`
+}
+
+type LedgerEntry struct {
+	ID            int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	TransactionID int             `gorm:"type:int(11);not null;index" json:"transaction_id"`
+	Account       string          `gorm:"type:varchar(50);not null;index:idx_ledger_account" json:"account"`
+	MerchantID    int             `gorm:"type:int(11);not null;index:idx_ledger_account" json:"merchant_id"`
+	Debit         decimal.Decimal `gorm:"type:decimal(15,2);not null;default:0" json:"debit"`
+	Credit        decimal.Decimal `gorm:"type:decimal(15,2);not null;default:0" json:"credit"`
+	CreatedAt     time.Time       `gorm:"default:current_timestamp()" json:"created_at"`
+
+	Transaction *LedgerTransaction `gorm:"foreignKey:ID;references:TransactionID;-:migration" json:"transaction,omitempty"`
+}
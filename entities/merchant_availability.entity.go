@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// MerchantHour is when a merchant is open on a day of the week, Weekday
+// counting from 0 on Sunday. Merchants without any hours are always open,
+// otherwise the days they have no hours for are closed.
+type MerchantHour struct {
+	ID         int    `gorm:"type:int(11);primaryKey;autoIncrement" json:"-"`
+	MerchantID int    `gorm:"type:int(11);uniqueIndex:idx_merchant_weekday" json:"merchant_id"`
+	Weekday    int    `gorm:"type:tinyint;uniqueIndex:idx_merchant_weekday" json:"weekday"`
+	OpensAt    string `gorm:"type:varchar(5);not null" json:"opens_at"`
+	ClosesAt   string `gorm:"type:varchar(5);not null" json:"closes_at"`
+}
+
+// MerchantClosure closes a merchant from StartDate through EndDate, both
+// YYYY-MM-DD, whatever its hours, such as for holidays.
+type MerchantClosure struct {
+	ID         int       `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	MerchantID int       `gorm:"type:int(11);index:idx_merchant_closure" json:"merchant_id"`
+	StartDate  string    `gorm:"type:varchar(10);index:idx_merchant_closure" json:"start_date"`
+	EndDate    string    `gorm:"type:varchar(10)" json:"end_date"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `gorm:"default:current_timestamp()" json:"created_at"`
+}
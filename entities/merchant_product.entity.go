@@ -4,20 +4,30 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type MerchantProduct struct {
-	ID          int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
-	MerchantID  int             `json:"merchant_id"`
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	Price       decimal.Decimal `json:"price"`
-	QTY         int             `json:"qty"`
-	Status      string          `gorm:"default:'waiting'" json:"status"`
-	IsActive    bool            `gorm:"default:false" json:"is_active"`
-	CreatedAt   time.Time       `gorm:"default:current_timestamp()"  json:"created_at"`
-	UpdatedAt   time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
-	DeletedAt   time.Time       `json:"deleted_at"`
+	ID           int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	MerchantID   int             `json:"merchant_id"`
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Price        decimal.Decimal `json:"price"`
+	QTY          int             `json:"qty"`
+	CategoryID   *int            `gorm:"type:int(11);index" json:"category_id"`
+	PortionGrams int             `gorm:"type:int(11);not null;default:0" json:"portion_grams"`
+	Packaging    string          `gorm:"type:varchar(30)" json:"packaging"`
+	Status       string          `gorm:"default:'waiting';index" json:"status"`
+	IsActive     bool            `gorm:"default:false" json:"is_active"`
+	ReviewNote   string          `gorm:"type:text" json:"review_note"`
+	ReviewedBy   int             `gorm:"type:int(11)" json:"reviewed_by"`
+	ReviewedAt   *time.Time      `json:"reviewed_at"`
+	CreatedAt    time.Time       `gorm:"default:current_timestamp()"  json:"created_at"`
+	UpdatedAt    time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
+	DeletedAt    gorm.DeletedAt  `gorm:"index" json:"deleted_at"`
 
 	MerchantProductImage []MerchantProductImage `gorm:"foreignKey:MerchantProductID;references:ID" json:"images"`
+	Merchant             *Merchant              `gorm:"foreignKey:ID;references:MerchantID" json:"merchant,omitempty"`
+	Category             *ProductCategory       `gorm:"foreignKey:ID;references:CategoryID" json:"category,omitempty"`
+	Attributes           []ProductAttribute     `gorm:"foreignKey:MerchantProductID;references:ID" json:"attributes"`
 }
@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+)
+
+type Notification struct {
+	ID        int        `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	UserId    int        `gorm:"type:int(11);not null;index" json:"user_id"`
+	Type      string     `gorm:"type:varchar(100);not null;index" json:"type"`
+	Title     string     `gorm:"type:varchar(255);not null" json:"title"`
+	Message   string     `gorm:"type:text" json:"message"`
+	RefType   string     `gorm:"type:varchar(50)" json:"ref_type"`
+	RefID     int        `gorm:"type:int(11)" json:"ref_id"`
+	IsRead    bool       `gorm:"default:false;index" json:"is_read"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `gorm:"default:current_timestamp()"  json:"created_at"`
+	UpdatedAt time.Time  `gorm:"default:current_timestamp()" json:"updated_at"`
+}
@@ -12,6 +12,8 @@ type Oauth struct {
 	Phone     string    `gorm:"type:varchar(15);not null;unique" json:"phone"`
 	Password  string    `gorm:"type:varchar(255);not null" json:"password"`
 	Role      string    `gorm:"type:varchar(100);not null;default:'superadmin'" json:"role"`
+	Locale    string    `gorm:"type:varchar(5);not null;default:'id'" json:"locale"`
+	Channel   string    `gorm:"type:varchar(20);not null;default:'email'" json:"channel"`
 	IsActive  bool      `gorm:"default:true" json:"is_active"`
 	IsLocked  bool      `gorm:"default:false" json:"is_locked"`
 	CreatedAt time.Time `gorm:"default:current_timestamp()"  json:"created_at"`
@@ -2,14 +2,33 @@ package entities
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type Order struct {
-	ID                int       `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
-	CampaignID        int       `json:"campaign_id"`
-	MerchantProductID int       `json:"merchant_product_id"`
-	OrderStatus       string    `gorm:"default:'waiting'" json:"order_status"`
-	Note              string    `json:"note"`
-	CreatedAt         time.Time `gorm:"default:current_timestamp()"  json:"created_at"`
-	UpdatedAt         time.Time `gorm:"default:current_timestamp()" json:"updated_at"`
+	ID                int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	CampaignID        int             `json:"campaign_id"`
+	MerchantProductID int             `json:"merchant_product_id"`
+	Qty               int             `gorm:"not null;default:1" json:"qty"`
+	Price             decimal.Decimal `gorm:"type:decimal(15,2);not null;default:0" json:"price"`
+	OrderStatus       string          `gorm:"default:'waiting'" json:"order_status"`
+	Note              string          `json:"note"`
+	DeliveryNonce     string          `gorm:"type:varchar(64)" json:"-"`
+	DeliveredAt       *time.Time      `json:"delivered_at"`
+	DeliveredBy       int             `gorm:"type:int(11)" json:"delivered_by"`
+	DeliveryLatitude  float64         `gorm:"type:decimal(10,7)" json:"delivery_latitude"`
+	DeliveryLongitude float64         `gorm:"type:decimal(10,7)" json:"delivery_longitude"`
+	CreatedAt         time.Time       `gorm:"default:current_timestamp()"  json:"created_at"`
+	UpdatedAt         time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
+	DeletedAt         gorm.DeletedAt  `gorm:"index" json:"deleted_at"`
+
+	Campaign        *Campaign        `gorm:"foreignKey:ID;references:CampaignID" json:"campaign,omitempty"`
+	MerchantProduct *MerchantProduct `gorm:"foreignKey:ID;references:MerchantProductID" json:"merchant_product,omitempty"`
+}
+
+// Total is the cost of the order at the unit price it was placed with.
+func (order Order) Total() decimal.Decimal {
+	return order.Price.Mul(decimal.NewFromInt(int64(order.Qty)))
 }
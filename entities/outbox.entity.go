@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+)
+
+type Outbox struct {
+	ID            int        `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	Channel       string     `gorm:"type:varchar(50);not null;index" json:"channel"`
+	Type          string     `gorm:"type:varchar(100);not null" json:"type"`
+	Recipient     string     `gorm:"type:varchar(255);not null" json:"recipient"`
+	Locale        string     `gorm:"type:varchar(5)" json:"locale"`
+	Payload       string     `gorm:"type:text" json:"payload"`
+	Status        string     `gorm:"type:varchar(50);default:'pending';index" json:"status"`
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	MaxAttempts   int        `gorm:"default:5" json:"max_attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `gorm:"default:current_timestamp()"  json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"default:current_timestamp()" json:"updated_at"`
+}
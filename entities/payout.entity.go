@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PayoutBatch struct {
+	ID        int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	Status    string          `gorm:"type:varchar(50);not null;index" json:"status"`
+	Provider  string          `gorm:"type:varchar(50);not null" json:"provider"`
+	Total     decimal.Decimal `gorm:"type:decimal(15,2);not null;default:0" json:"total"`
+	Count     int             `gorm:"not null;default:0" json:"count"`
+	CreatedBy int             `gorm:"type:int(11)" json:"created_by"`
+	CreatedAt time.Time       `gorm:"default:current_timestamp()" json:"created_at"`
+	UpdatedAt time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
+
+	Payouts []Payout `gorm:"foreignKey:BatchID;references:ID" json:"payouts,omitempty"`
+}
+
+type Payout struct {
+	ID         int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	BatchID    int             `gorm:"type:int(11);not null;index" json:"batch_id"`
+	MerchantID int             `gorm:"type:int(11);not null;index" json:"merchant_id"`
+	Account    string          `gorm:"type:varchar(15);not null" json:"account"`
+	Amount     decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Status     string          `gorm:"type:varchar(50);not null;index" json:"status"`
+	Reference  string          `gorm:"type:varchar(100)" json:"reference"`
+	Error      string          `gorm:"type:text" json:"error"`
+	Attempts   int             `gorm:"not null;default:0" json:"attempts"`
+	PaidAt     *time.Time      `json:"paid_at"`
+	CreatedAt  time.Time       `gorm:"default:current_timestamp()" json:"created_at"`
+	UpdatedAt  time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
+
+	Merchant *Merchant `gorm:"foreignKey:ID;references:MerchantID;-:migration" json:"merchant,omitempty"`
+}
@@ -0,0 +1,9 @@
+package entities
+
+// ProductAttribute is a tag, dietary or allergen attribute of a product.
+type ProductAttribute struct {
+	ID                int    `gorm:"type:int(11);primaryKey;autoIncrement" json:"-"`
+	MerchantProductID int    `gorm:"type:int(11);not null;uniqueIndex:idx_product_attribute" json:"-"`
+	Kind              string `gorm:"type:varchar(20);not null;uniqueIndex:idx_product_attribute;index:idx_attribute_value" json:"kind"`
+	Value             string `gorm:"type:varchar(50);not null;uniqueIndex:idx_product_attribute;index:idx_attribute_value" json:"value"`
+}
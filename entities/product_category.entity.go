@@ -0,0 +1,14 @@
+package entities
+
+import (
+	"time"
+)
+
+type ProductCategory struct {
+	ID          int       `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	Slug        string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"slug"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"default:current_timestamp()" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"default:current_timestamp()" json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// ProductImportJob is a bulk import of merchant products from a spreadsheet.
+// Report holds the JSON encoded result of every row.
+type ProductImportJob struct {
+	ID         int        `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	MerchantID int        `gorm:"type:int(11);not null;index" json:"merchant_id"`
+	CreatedBy  int        `gorm:"type:int(11);not null" json:"created_by"`
+	Filename   string     `gorm:"type:varchar(255)" json:"filename"`
+	Status     string     `gorm:"type:varchar(20);not null;default:'queued'" json:"status"`
+	DryRun     bool       `gorm:"not null;default:false" json:"dry_run"`
+	Total      int        `json:"total"`
+	Valid      int        `json:"valid"`
+	Invalid    int        `json:"invalid"`
+	Created    int        `json:"created"`
+	Error      string     `gorm:"type:text" json:"error"`
+	Report     string     `gorm:"type:mediumtext" json:"-"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `gorm:"default:current_timestamp()" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"default:current_timestamp()" json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ProductPrice is a price of a merchant product from StartsAt on. A regular
+// price replaces the price of the product once applied, the applied ones
+// making up its price history. A promotional price only holds until EndsAt.
+type ProductPrice struct {
+	ID                int             `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	MerchantProductID int             `gorm:"type:int(11);index:idx_product_price" json:"merchant_product_id"`
+	Kind              string          `gorm:"type:varchar(20);index:idx_product_price" json:"kind"`
+	Price             decimal.Decimal `gorm:"type:decimal(15,2);not null" json:"price"`
+	StartsAt          time.Time       `gorm:"index:idx_product_price" json:"starts_at"`
+	EndsAt            *time.Time      `json:"ends_at"`
+	Note              string          `json:"note"`
+	AppliedAt         *time.Time      `gorm:"index" json:"applied_at"`
+	CreatedAt         time.Time       `gorm:"default:current_timestamp()" json:"created_at"`
+	UpdatedAt         time.Time       `gorm:"default:current_timestamp()" json:"updated_at"`
+}
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+type WebhookSubscription struct {
+	ID          int       `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	URL         string    `gorm:"type:varchar(255);not null" json:"url"`
+	Secret      string    `gorm:"type:varchar(255);not null" json:"-"`
+	Events      string    `gorm:"type:text;not null" json:"events"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	IsActive    bool      `gorm:"not null" json:"is_active"`
+	CreatedAt   time.Time `gorm:"default:current_timestamp()"  json:"created_at"`
+	UpdatedAt   time.Time `gorm:"default:current_timestamp()" json:"updated_at"`
+}
+
+// CreatedWebhookSubscription is returned once when a subscription is created,
+// the only time its signing secret is shown.
+type CreatedWebhookSubscription struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// Subscribes reports whether the subscription wants the event type.
+func (s WebhookSubscription) Subscribes(event string) bool {
+	for _, subscribed := range strings.Split(s.Events, ",") {
+		if strings.TrimSpace(subscribed) == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDelivery logs one attempt to deliver an event to a subscription.
+type WebhookDelivery struct {
+	ID             int       `gorm:"type:int(11);primaryKey;autoIncrement" json:"id"`
+	SubscriptionID int       `gorm:"type:int(11);not null;index" json:"subscription_id"`
+	OutboxID       int       `gorm:"type:int(11);index" json:"outbox_id"`
+	EventID        string    `gorm:"type:varchar(100);index" json:"event_id"`
+	Event          string    `gorm:"type:varchar(100);not null" json:"event"`
+	Attempt        int       `json:"attempt"`
+	URL            string    `gorm:"type:varchar(255)" json:"url"`
+	Payload        string    `gorm:"type:text" json:"payload"`
+	StatusCode     int       `json:"status_code"`
+	ResponseBody   string    `gorm:"type:text" json:"response_body"`
+	Error          string    `gorm:"type:text" json:"error"`
+	DurationMs     int64     `json:"duration_ms"`
+	Success        bool      `gorm:"default:false;index" json:"success"`
+	CreatedAt      time.Time `gorm:"default:current_timestamp()"  json:"created_at"`
+
+	Subscription *WebhookSubscription `gorm:"foreignKey:ID;references:SubscriptionID;-:migration" json:"subscription,omitempty"`
+}
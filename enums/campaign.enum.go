@@ -0,0 +1,17 @@
+package enums
+
+const (
+	CampaignStatusDraft       = "draft"
+	CampaignStatusReview      = "review"
+	CampaignStatusFundraising = "fundraising"
+	CampaignStatusFunded      = "funded"
+	CampaignStatusExecuting   = "executing"
+	CampaignStatusCompleted   = "completed"
+	CampaignStatusCancelled   = "cancelled"
+)
+
+const (
+	TimezoneWIB  = "WIB"
+	TimezoneWITA = "WITA"
+	TimezoneWIT  = "WIT"
+)
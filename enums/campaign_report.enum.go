@@ -0,0 +1,7 @@
+package enums
+
+const (
+	CampaignReportSubmitted = "submitted"
+	CampaignReportVerified  = "verified"
+	CampaignReportRejected  = "rejected"
+)
@@ -6,6 +6,8 @@ const (
 	ConfigCtxKey   ContextKey = "config.ctx.key"
 	GormCtxKey     ContextKey = "gorm.ctx.key"
 	LoggerCtxKey   ContextKey = "logger.ctx.key"
-	SmtpCtxKey     ContextKey = "smtp.ctx.key"
+	MailCtxKey     ContextKey = "mail.ctx.key"
 	TemplateCtxKey ContextKey = "template.ctx.key"
+	BrokerCtxKey   ContextKey = "broker.ctx.key"
+	PayoutCtxKey   ContextKey = "payout.ctx.key"
 )
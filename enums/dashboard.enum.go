@@ -0,0 +1,7 @@
+package enums
+
+const (
+	DashboardBucketDay   = "day"
+	DashboardBucketWeek  = "week"
+	DashboardBucketMonth = "month"
+)
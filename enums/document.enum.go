@@ -0,0 +1,6 @@
+package enums
+
+const (
+	DocumentReceipt     = "receipt"
+	DocumentCertificate = "certificate"
+)
@@ -0,0 +1,7 @@
+package enums
+
+const (
+	DonationStatusPending = "pending"
+	DonationStatusPaid    = "paid"
+	DonationStatusFailed  = "failed"
+)
@@ -13,4 +13,60 @@ var (
 	ErrInvalidRefreshToken      = errors.New("refresh token is invalid")
 	ErrExpiredToken             = errors.New("token has expired")
 	ErrEmailOrPasswordMissMatch = errors.New("email/password miss match")
+	ErrMailTransportMissing     = errors.New("mail transport is not configured")
+	ErrCampaignTransition       = errors.New("campaign cannot move to this status from its current status")
+	ErrCampaignLocked           = errors.New("campaign can no longer be changed in its current status")
+	ErrReportNotAllowed         = errors.New("campaign cannot be reported before its distribution has started")
+	ErrReportExists             = errors.New("campaign already has a report that is waiting for verification or verified")
+	ErrReportPhoto              = errors.New("report photos must be uploaded to the report media destination")
+	ErrReportDistance           = errors.New("report location is too far from the campaign location")
+	ErrReportReviewed           = errors.New("report has already been reviewed")
+	ErrOrderNotDeliverable      = errors.New("only approved orders of a funded or executing campaign can be delivered")
+	ErrDeliveryTokenExpired     = errors.New("delivery token has expired")
+	ErrDeliveryTokenUsed        = errors.New("delivery token has already been used or replaced")
+	ErrDeliveryCampaign         = errors.New("delivery token does not belong to this campaign")
+	ErrPayoutProviderMissing    = errors.New("payout provider is not configured")
+	ErrPayoutNothingDue         = errors.New("no merchant has a balance due for payout")
+	ErrFeePercentage            = errors.New("a percentage fee cannot exceed 100")
+	ErrBudgetExceeded           = errors.New("orders exceed the funds of the campaign")
+	ErrOrderLocked              = errors.New("orders can no longer be added to the campaign in its current status")
+	ErrEmailRegistered          = errors.New("email or phone is already registered")
+	ErrOTPExpired               = errors.New("OTP has expired, please request a new one")
+	ErrDonationClosed           = errors.New("campaign is not accepting donations")
+	ErrDonationReviewed         = errors.New("donation has already been reviewed")
+	ErrCertificateNotReady      = errors.New("certificate is issued once the campaign is completed")
+	ErrReceiptNotIssued         = errors.New("receipt is issued once the donation is paid")
+	ErrImportFormat             = errors.New("only CSV and XLSX files can be imported")
+	ErrImportTooLarge           = errors.New("file has more rows than can be imported at once")
+	ErrImportFileSize           = errors.New("file is larger than can be imported at once")
+	ErrImportInterrupted        = errors.New("import was interrupted, please upload the file again")
+	ErrImportColumns            = errors.New("file must have the columns name, description, price and qty")
+	ErrImportInvalid            = errors.New("some rows are invalid, no product was imported")
+	ErrImportNumber             = errors.New("must be a number")
+	ErrCategoryExists           = errors.New("product category already exists")
+	ErrCategoryNotFound         = errors.New("product category does not exist")
+	ErrProductNotApproved       = errors.New("only approved products can be activated")
+	ErrProductUnavailable       = errors.New("product is not approved, active or in stock")
+	ErrProductInUse             = errors.New("products with open orders cannot be deleted")
+	ErrCampaignNotDeletable     = errors.New("only draft and cancelled campaigns can be deleted")
+	ErrDetonatorInUse           = errors.New("detonators with campaigns in progress cannot be deleted")
+	ErrRestoreOwner             = errors.New("the merchant or detonator it belongs to must be restored first")
+	ErrAccountDeleted           = errors.New("account has been deleted")
+	ErrPriceStart               = errors.New("regular prices can only be scheduled in the future")
+	ErrPromoPeriod              = errors.New("promotional prices must end after they start and in the future")
+	ErrPromoOverlap             = errors.New("the product already has a promotional price in that period")
+	ErrPriceApplied             = errors.New("prices already in effect cannot be cancelled")
+	ErrMerchantHours            = errors.New("merchant must close after it opens, once per day")
+	ErrClosurePeriod            = errors.New("closure must end on or after the day it starts")
+	ErrMerchantClosed           = errors.New("merchant is closed at the time of the event")
+	ErrMerchantCapacity         = errors.New("merchant has no capacity left for the event date")
+	ErrCampaignLocation         = errors.New("campaign has no valid location to match merchants with")
+	ErrOTPMismatch              = errors.New("OTP doesn't match, please recheck your OTP code")
+	ErrOTPAttempts              = errors.New("too many wrong OTP codes, please request a new one")
+	ErrOTPCooldown              = errors.New("an OTP was sent recently, please wait before requesting another one")
+	ErrOutboxNotReplayable      = errors.New("only dead-lettered messages can be replayed")
+	ErrOrderNotWaiting          = errors.New("only waiting orders can be approved or rejected")
+	ErrCampaignDetonator        = errors.New("the detonator of a campaign cannot be changed")
+	ErrWebhookPingRedelivery    = errors.New("pings cannot be redelivered, send a new ping instead")
+	ErrMerchantBalance          = errors.New("merchants with an unpaid balance or a payout in progress cannot be deleted")
 )
@@ -0,0 +1,6 @@
+package enums
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
@@ -0,0 +1,11 @@
+package enums
+
+const (
+	FeeTypePercentage = "percentage"
+	FeeTypeFlat       = "flat"
+)
+
+const (
+	FeeBaseDonation = "donation"
+	FeeBaseOrder    = "order"
+)
@@ -0,0 +1,10 @@
+package enums
+
+const (
+	LocaleID      = "id"
+	LocaleEN      = "en"
+	DefaultLocale = LocaleID
+)
+
+// LocaleLocalsKey is the fiber locals key holding the negotiated request locale.
+const LocaleLocalsKey = "locale"
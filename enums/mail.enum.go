@@ -0,0 +1,7 @@
+package enums
+
+const (
+	MailTransportSMTP    = "smtp"
+	MailTransportCapture = "capture"
+	MailTransportMemory  = "memory"
+)
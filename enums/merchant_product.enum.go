@@ -0,0 +1,48 @@
+package enums
+
+const (
+	ProductStatusWaiting  = "waiting"
+	ProductStatusApproved = "approved"
+	ProductStatusRejected = "rejected"
+)
+
+// Kinds of product attributes. Tags are free text, dietary and allergen
+// attributes take one of the values below.
+const (
+	ProductAttributeTag      = "tag"
+	ProductAttributeDietary  = "dietary"
+	ProductAttributeAllergen = "allergen"
+)
+
+const (
+	DietaryHalal      = "halal"
+	DietaryVegetarian = "vegetarian"
+	DietaryVegan      = "vegan"
+	DietaryGlutenFree = "gluten_free"
+	DietaryDairyFree  = "dairy_free"
+)
+
+const (
+	AllergenPeanut    = "peanut"
+	AllergenTreeNut   = "tree_nut"
+	AllergenDairy     = "dairy"
+	AllergenEgg       = "egg"
+	AllergenFish      = "fish"
+	AllergenShellfish = "shellfish"
+	AllergenSoy       = "soy"
+	AllergenWheat     = "wheat"
+	AllergenSesame    = "sesame"
+)
+
+const (
+	PackagingBox        = "box"
+	PackagingPaperWrap  = "paper_wrap"
+	PackagingBananaLeaf = "banana_leaf"
+	PackagingPlastic    = "plastic"
+	PackagingContainer  = "container"
+)
+
+const (
+	ProductPriceRegular = "regular"
+	ProductPricePromo   = "promo"
+)
@@ -0,0 +1,5 @@
+package enums
+
+const (
+	MsgSuccess = "success"
+)
@@ -0,0 +1,30 @@
+package enums
+
+const (
+	NotificationRegistrationSubmitted = "registration_submitted"
+	NotificationRegistrationApproved  = "registration_approved"
+	NotificationRegistrationRejected  = "registration_rejected"
+	NotificationCampaignSubmitted     = "campaign_submitted"
+	NotificationCampaignApproved      = "campaign_approved"
+	NotificationCampaignRejected      = "campaign_rejected"
+	NotificationCampaignStatusChanged = "campaign_status_changed"
+	NotificationReportSubmitted       = "report_submitted"
+	NotificationReportVerified        = "report_verified"
+	NotificationReportRejected        = "report_rejected"
+	NotificationOrderReceived         = "order_received"
+	NotificationOrderStatusChanged    = "order_status_changed"
+	NotificationPayoutPaid            = "payout_paid"
+	NotificationPayoutFailed          = "payout_failed"
+	NotificationProductApproved       = "product_approved"
+	NotificationProductRejected       = "product_rejected"
+)
+
+const (
+	NotificationRefMerchant  = "merchant"
+	NotificationRefDetonator = "detonator"
+	NotificationRefCampaign  = "campaign"
+	NotificationRefOrder     = "order"
+	NotificationRefReport    = "campaign_report"
+	NotificationRefPayout    = "payout"
+	NotificationRefProduct   = "merchant_product"
+)
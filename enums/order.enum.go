@@ -0,0 +1,8 @@
+package enums
+
+const (
+	OrderStatusWaiting   = "waiting"
+	OrderStatusApproved  = "approved"
+	OrderStatusRejected  = "rejected"
+	OrderStatusDelivered = "delivered"
+)
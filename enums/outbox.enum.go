@@ -0,0 +1,20 @@
+package enums
+
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusProcessing = "processing"
+	OutboxStatusSent       = "sent"
+	OutboxStatusDead       = "dead"
+)
+
+const (
+	OutboxChannelEmail    = "email"
+	OutboxChannelSMS      = "sms"
+	OutboxChannelWhatsApp = "whatsapp"
+	OutboxChannelWebhook  = "webhook"
+)
+
+const (
+	MessageProviderFake = "fake"
+	MessageProviderHTTP = "http"
+)
@@ -0,0 +1,37 @@
+package enums
+
+// ledger accounts, kept per merchant
+const (
+	LedgerAccountOrderExpense    = "order_expense"
+	LedgerAccountMerchantPayable = "merchant_payable"
+	LedgerAccountPayoutClearing  = "payout_clearing"
+	LedgerAccountLinkAja         = "linkaja"
+)
+
+const (
+	LedgerOrderAccrual   = "order_accrual"
+	LedgerPayout         = "payout"
+	LedgerPayoutSettled  = "payout_settled"
+	LedgerPayoutReversed = "payout_reversed"
+	LedgerRefOrder       = "order"
+	LedgerRefPayout      = "payout"
+)
+
+const (
+	PayoutStatusPending    = "pending"
+	PayoutStatusProcessing = "processing"
+	PayoutStatusPaid       = "paid"
+	PayoutStatusFailed     = "failed"
+)
+
+const (
+	PayoutBatchProcessing = "processing"
+	PayoutBatchCompleted  = "completed"
+	PayoutBatchPartial    = "partial"
+	PayoutBatchFailed     = "failed"
+)
+
+const (
+	PayoutProviderFake    = "fake"
+	PayoutProviderLinkAja = "linkaja"
+)
@@ -0,0 +1,8 @@
+package enums
+
+const (
+	ProductImportQueued    = "queued"
+	ProductImportRunning   = "running"
+	ProductImportCompleted = "completed"
+	ProductImportFailed    = "failed"
+)
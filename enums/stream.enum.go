@@ -0,0 +1,12 @@
+package enums
+
+const (
+	StreamBrokerMemory = "memory"
+)
+
+const (
+	StreamTopicCampaign     = "campaign"
+	StreamTopicOrder        = "order"
+	StreamTopicNotification = "notification"
+	StreamTopicDonation     = "donation"
+)
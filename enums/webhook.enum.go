@@ -0,0 +1,22 @@
+package enums
+
+const (
+	WebhookEventPing              = "ping"
+	WebhookEventCampaignApproved  = "campaign.approved"
+	WebhookEventCampaignFunded    = "campaign.funded"
+	WebhookEventCampaignCompleted = "campaign.completed"
+)
+
+// WebhookEvents lists the event types partners can subscribe to.
+var WebhookEvents = []string{
+	WebhookEventCampaignApproved,
+	WebhookEventCampaignFunded,
+	WebhookEventCampaignCompleted,
+}
+
+const (
+	WebhookHeaderEvent     = "X-Foodia-Event"
+	WebhookHeaderDelivery  = "X-Foodia-Delivery"
+	WebhookHeaderTimestamp = "X-Foodia-Timestamp"
+	WebhookHeaderSignature = "X-Foodia-Signature"
+)
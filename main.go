@@ -12,7 +12,9 @@ import (
 	"foodia-be/common"
 	"foodia-be/configs"
 	"foodia-be/enums"
+	"foodia-be/middlewares"
 	"foodia-be/routers"
+	"foodia-be/services"
 
 	"github.com/goccy/go-json"
 
@@ -25,7 +27,6 @@ import (
 	"github.com/knadh/koanf/parsers/dotenv"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
-	"github.com/wneessen/go-mail"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
@@ -69,6 +70,11 @@ func init() {
 	if err := createDirIfNotExists(productPath); err != nil {
 		log.Fatalf("Error creating %s directory:%v", productPath, err)
 	}
+
+	reportPath := "storage/report"
+	if err := createDirIfNotExists(reportPath); err != nil {
+		log.Fatalf("Error creating %s directory:%v", reportPath, err)
+	}
 }
 
 func main() {
@@ -87,16 +93,8 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
-	smtp, err := mail.NewClient(
-		config.SmtpHost,
-		mail.WithPort(config.SmtpPort),
-		mail.WithSMTPAuth(mail.SMTPAuthPlain),
-		mail.WithUsername(config.SmtpUser),
-		mail.WithPassword(config.SmtpPass),
-		mail.WithSSL(),
-	)
-	if err != nil {
-		// log.Fatalf("failed connect to smtp server with error: %v", err)
+	if err := configs.Migrate(db); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
 	}
 
 	logfile, err := common.NewLogger(config.LogFile)
@@ -104,11 +102,28 @@ func main() {
 		log.Fatalf("failed connect to logger service with error: %v", err)
 	}
 
+	mailTransport, err := services.NewMailTransport(config, logfile)
+	if err != nil {
+		log.Fatalf("failed to configure mail transport with error: %v", err)
+	}
+
+	broker, err := services.NewBroker(config, logfile)
+	if err != nil {
+		log.Fatalf("failed to configure stream broker with error: %v", err)
+	}
+
+	payoutProvider, err := services.NewPayoutProvider(config, logfile)
+	if err != nil {
+		log.Fatalf("failed to configure payout provider with error: %v", err)
+	}
+
 	ctx := context.WithValue(context.Background(), enums.GormCtxKey, db)
 	ctx = context.WithValue(ctx, enums.ConfigCtxKey, config)
 	ctx = context.WithValue(ctx, enums.LoggerCtxKey, logfile)
 	ctx = context.WithValue(ctx, enums.TemplateCtxKey, templateFS)
-	ctx = context.WithValue(ctx, enums.SmtpCtxKey, smtp)
+	ctx = context.WithValue(ctx, enums.MailCtxKey, mailTransport)
+	ctx = context.WithValue(ctx, enums.BrokerCtxKey, broker)
+	ctx = context.WithValue(ctx, enums.PayoutCtxKey, payoutProvider)
 
 	app := fiber.New(fiber.Config{
 		ProxyHeader: fiber.HeaderXForwardedFor,
@@ -119,11 +134,12 @@ func main() {
 
 	app.Use(logger.New())
 	app.Use(recover.New())
+	app.Use(middlewares.NewLocaleMiddleware())
 
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,DELETE",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Accept-Language, Authorization",
 		ExposeHeaders:    "Content-Length",
 		AllowCredentials: true,
 	}))
@@ -136,6 +152,11 @@ func main() {
 
 	routers.UseRouter(ctx, app)
 
+	go services.NewOutboxDispatcher(ctx, db).Run(ctx)
+	go services.NewCampaignScheduler(ctx, db).Run(ctx)
+	go services.NewProductPriceScheduler(ctx, db).Run(ctx)
+	go services.NewProductImportService(ctx, db).FailStale()
+
 	if err = app.Listen(fmt.Sprintf(":%d", config.AppPort)); err != nil {
 		log.Fatal(err.Error())
 	}
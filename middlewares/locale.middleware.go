@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"foodia-be/common"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewLocaleMiddleware negotiates the response language from the Accept-Language
+// header and stores it in the context locals for controllers and services.
+func NewLocaleMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		locale := common.NegotiateLocale(c)
+
+		c.Locals(enums.LocaleLocalsKey, locale)
+		c.Set(fiber.HeaderContentLanguage, locale)
+		c.Vary(fiber.HeaderAcceptLanguage)
+
+		return c.Next()
+	}
+}
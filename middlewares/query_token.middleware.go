@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewQueryTokenMiddleware lets clients that cannot set request headers, such as
+// the browser EventSource, pass their JWT in the access_token query parameter.
+// The token is moved to the Authorization header before the RBAC middleware runs.
+func NewQueryTokenMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token := c.Query("access_token"); token != "" && c.Get(fiber.HeaderAuthorization) == "" {
+			c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+
+		return c.Next()
+	}
+}
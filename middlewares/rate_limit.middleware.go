@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// NewRateLimitMiddleware allows each client IP max requests per window on the
+// route, answering 429 once the limit is reached. The counters live in memory,
+// per instance.
+func NewRateLimitMiddleware(max int, window time.Duration) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.ApiResponse{
+				Code:    fiber.StatusTooManyRequests,
+				Message: common.StatusMessage(c, fiber.StatusTooManyRequests),
+			})
+		},
+	})
+}
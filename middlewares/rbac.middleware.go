@@ -1,32 +1,61 @@
 package middlewares
 
 import (
+	"context"
 	"strings"
 	"time"
 
 	"foodia-be/common"
+	"foodia-be/configs"
 	"foodia-be/dto"
+	"foodia-be/entities"
 	"foodia-be/enums"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 type RBACMiddleware struct {
 	Secret   string
 	Duration time.Duration
+	DB       *gorm.DB
 }
 
-func NewRBACMiddleware(secret string, duration time.Duration) *RBACMiddleware {
+func NewRBACMiddleware(ctx context.Context) *RBACMiddleware {
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+	db := ctx.Value(enums.GormCtxKey).(*gorm.DB)
+
 	return &RBACMiddleware{
-		Secret:   secret,
-		Duration: duration,
+		Secret:   config.JWTSecret,
+		Duration: config.JWTExpirationDuration,
+		DB:       db,
+	}
+}
+
+// exists tells whether the merchant or detonator of the session is still
+// there, so deleting one ends its sessions. Other roles have no such record.
+func (m RBACMiddleware) exists(claims *dto.JWTClaims) (bool, error) {
+	var model any
+	switch claims.Role {
+	case "merchant":
+		model = &entities.Merchant{}
+	case "detonator":
+		model = &entities.Detonator{}
+	default:
+		return true, nil
 	}
+
+	var count int64
+	err := m.DB.Model(model).Where("user_id = ?", claims.UserId).Count(&count).Error
+
+	return count > 0, err
 }
 
 // allowRole is a middleware function that validates and refreshes JWT tokens.
 // It checks the "Authorization" header in the request, validates the JWT token,
-// and refreshes the token if it is about to expire.
+// and refreshes the token if it is about to expire. Merchants and detonators
+// deleted since they logged in are turned away.
 // It returns a Fiber handler function that can be used as middleware.
 func (m RBACMiddleware) allowRole(allowed []string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -39,7 +68,7 @@ func (m RBACMiddleware) allowRole(allowed []string) fiber.Handler {
 			// Return unauthorized response if the authorization header is missing or incomplete
 			return c.Status(fiber.StatusUnauthorized).JSON(dto.ApiResponse{
 				Code:    fiber.ErrUnauthorized.Code,
-				Message: fiber.ErrUnauthorized.Message,
+				Message: common.StatusMessage(c, fiber.ErrUnauthorized.Code),
 				Error:   jwt.ErrTokenSignatureInvalid.Error(),
 			})
 		}
@@ -48,7 +77,7 @@ func (m RBACMiddleware) allowRole(allowed []string) fiber.Handler {
 			// Return unauthorized response if the authorization type is not "Bearer"
 			return c.Status(fiber.StatusUnauthorized).JSON(dto.ApiResponse{
 				Code:    fiber.ErrUnauthorized.Code,
-				Message: fiber.ErrUnauthorized.Message,
+				Message: common.StatusMessage(c, fiber.ErrUnauthorized.Code),
 				Error:   jwt.ErrTokenSignatureInvalid.Error(),
 			})
 		}
@@ -84,7 +113,26 @@ func (m RBACMiddleware) allowRole(allowed []string) fiber.Handler {
 		// Return forbidden response if the user's role does not match the required role
 		for _, allow := range allowed {
 			if claims.Session == common.GenerateSHA256(m.Secret, allow) {
-				// Set the updated authorization header and store the claims in the context locals
+				// Remember which role matched so handlers can tell users apart
+				claims.Role = allow
+
+				// Return unauthorized response if the account has been deleted since login
+				exists, err := m.exists(claims)
+				if err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(dto.ApiResponse{
+						Code:    fiber.StatusInternalServerError,
+						Message: err.Error(),
+					})
+				}
+
+				if !exists {
+					return c.Status(fiber.StatusUnauthorized).JSON(dto.ApiResponse{
+						Code:    fiber.ErrUnauthorized.Code,
+						Message: common.StatusMessage(c, fiber.ErrUnauthorized.Code),
+						Error:   common.ErrorMessage(c, enums.ErrAccountDeleted),
+					})
+				}
+
 				return c.Next()
 			}
 		}
@@ -92,8 +140,8 @@ func (m RBACMiddleware) allowRole(allowed []string) fiber.Handler {
 		// Return StatusUnauthorized if role user not in list allowd
 		return c.Status(fiber.StatusForbidden).JSON(dto.ApiResponse{
 			Code:    fiber.ErrUnauthorized.Code,
-			Message: fiber.ErrUnauthorized.Message,
-			Error:   enums.ErrAccessForbidden.Error(),
+			Message: common.StatusMessage(c, fiber.ErrUnauthorized.Code),
+			Error:   common.ErrorMessage(c, enums.ErrAccessForbidden),
 		})
 	}
 }
@@ -113,3 +161,12 @@ func (m RBACMiddleware) AllowDetonator() fiber.Handler {
 func (m RBACMiddleware) AllowAll() fiber.Handler {
 	return m.allowRole([]string{"superadmin", "detonator", "merchant"})
 }
+
+func (m RBACMiddleware) AllowDonor() fiber.Handler {
+	return m.allowRole([]string{"donor"})
+}
+
+// AllowEveryone lets in donors as well, for the routes every account has.
+func (m RBACMiddleware) AllowEveryone() fiber.Handler {
+	return m.allowRole([]string{"superadmin", "detonator", "merchant", "donor"})
+}
@@ -1,23 +1,23 @@
 package routers
 
 import (
-	// "time"
+	"time"
 
 	"foodia-be/controllers"
-	// "foodia-be/utils/enums"
-	// "foodia-be/middlewares"
+	"foodia-be/middlewares"
 
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/net/context"
 )
 
 func UseAuthRouter(ctx context.Context, r fiber.Router) {
-	// secret := ctx.Value(enums.JWTSecretCtxKey).(string)
-	// expired := ctx.Value(enums.JWTExpiredCtxKey).(time.Duration)
-	// auth := middlewares.NewRBACMiddleware(secret, expired)
+	auth := middlewares.NewRBACMiddleware(ctx)
 	ctrl := controllers.NewAuthController(ctx)
 
 	authGroup := r.Group("/auth")
 	authGroup.Post("/login", ctrl.BasicAuthentication)
-	authGroup.Post("/verify-otp", ctrl.ValidateOTP)
+	authGroup.Post("/verify-otp", middlewares.NewRateLimitMiddleware(10, time.Minute), ctrl.ValidateOTP)
+	authGroup.Put("/channel", auth.AllowEveryone(), ctrl.UpdateChannel)
+	authGroup.Post("/donor/registration", ctrl.DonorRegistration)
+	authGroup.Post("/donor/otp", middlewares.NewRateLimitMiddleware(5, time.Minute), ctrl.DonorOTP)
 }
@@ -1,9 +1,7 @@
 package routers
 
 import (
-	"foodia-be/configs"
 	"foodia-be/controllers"
-	"foodia-be/enums"
 	"foodia-be/middlewares"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,8 +9,7 @@ import (
 )
 
 func UseCampaignRouter(ctx context.Context, r fiber.Router) {
-	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
-	auth := middlewares.NewRBACMiddleware(config.JWTSecret, config.JWTExpirationDuration)
+	auth := middlewares.NewRBACMiddleware(ctx)
 	ctrl := controllers.NewCampaignController(ctx)
 
 	campaignGroup := r.Group("/campaign")
@@ -20,4 +17,14 @@ func UseCampaignRouter(ctx context.Context, r fiber.Router) {
 	campaignGroup.Get("/filter", ctrl.GetAll)
 	campaignGroup.Put("/update/:id", auth.AllowAll(), ctrl.CampaignUpdate)
 	campaignGroup.Get("/fetch/:id", auth.AllowAll(), ctrl.GetByID)
+	campaignGroup.Get("/detail/:id", ctrl.GetDetail)
+	campaignGroup.Put("/approval/:id", auth.AllowSuperAdmin(), ctrl.CampaignApproval)
+	campaignGroup.Put("/submit/:id", auth.AllowDetonator(), ctrl.CampaignSubmit)
+	campaignGroup.Put("/cancel/:id", auth.AllowAll(), ctrl.CampaignCancel)
+	campaignGroup.Get("/budget/:id", auth.AllowAll(), ctrl.CampaignBudget)
+	campaignGroup.Put("/budget-override/:id", auth.AllowSuperAdmin(), ctrl.CampaignBudgetOverride)
+	campaignGroup.Get("/match/:id", auth.AllowAll(), ctrl.CampaignMatch)
+	campaignGroup.Delete("/delete/:id", auth.AllowAll(), ctrl.CampaignDelete)
+	campaignGroup.Put("/restore/:id", auth.AllowSuperAdmin(), ctrl.CampaignRestore)
+	campaignGroup.Get("/deleted", auth.AllowSuperAdmin(), ctrl.GetDeleted)
 }
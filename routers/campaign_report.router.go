@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseCampaignReportRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewCampaignReportController(ctx)
+
+	reportGroup := r.Group("/campaign-report")
+	reportGroup.Post("/create", auth.AllowDetonator(), ctrl.ReportSubmit)
+	reportGroup.Get("/filter", auth.AllowSuperAdmin(), ctrl.GetAll)
+	reportGroup.Get("/fetch/:id", auth.AllowAll(), ctrl.GetByID)
+	reportGroup.Put("/review/:id", auth.AllowSuperAdmin(), ctrl.ReportReview)
+}
@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseDashboardRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewDashboardController(ctx)
+
+	dashboardGroup := r.Group("/dashboard")
+	dashboardGroup.Get("/registrations", auth.AllowSuperAdmin(), ctrl.Registrations)
+	dashboardGroup.Get("/campaigns", auth.AllowSuperAdmin(), ctrl.Campaigns)
+	dashboardGroup.Get("/orders", auth.AllowSuperAdmin(), ctrl.Orders)
+	dashboardGroup.Get("/donations", auth.AllowSuperAdmin(), ctrl.Donations)
+	dashboardGroup.Get("/top-merchants", auth.AllowSuperAdmin(), ctrl.TopMerchants)
+}
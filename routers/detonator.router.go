@@ -1,9 +1,7 @@
 package routers
 
 import (
-	"foodia-be/configs"
 	"foodia-be/controllers"
-	"foodia-be/enums"
 	"foodia-be/middlewares"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,8 +9,7 @@ import (
 )
 
 func UseDetonatorRouter(ctx context.Context, r fiber.Router) {
-	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
-	auth := middlewares.NewRBACMiddleware(config.JWTSecret, config.JWTExpirationDuration)
+	auth := middlewares.NewRBACMiddleware(ctx)
 	ctrl := controllers.NewDetonatorController(ctx)
 
 	detonatorGroup := r.Group("/detonator")
@@ -21,4 +18,7 @@ func UseDetonatorRouter(ctx context.Context, r fiber.Router) {
 	detonatorGroup.Get("/fetch/:id", auth.AllowAll(), ctrl.GetByID)
 	detonatorGroup.Put("/approval/:id", auth.AllowAll(), ctrl.DetonatorApproval)
 	detonatorGroup.Put("/update/:id", auth.AllowAll(), ctrl.DetonatorUpdate)
+	detonatorGroup.Delete("/delete/:id", auth.AllowSuperAdmin(), ctrl.DetonatorDelete)
+	detonatorGroup.Put("/restore/:id", auth.AllowSuperAdmin(), ctrl.DetonatorRestore)
+	detonatorGroup.Get("/deleted", auth.AllowSuperAdmin(), ctrl.GetDeleted)
 }
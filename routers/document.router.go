@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseDocumentRouter(ctx context.Context, r fiber.Router) {
+	ctrl := controllers.NewDocumentController(ctx)
+
+	documentGroup := r.Group("/document")
+	documentGroup.Get("/certificate/:campaign_id", ctrl.Certificate)
+	documentGroup.Get("/verify/:code", ctrl.Verify)
+}
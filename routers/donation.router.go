@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseDonationRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewDonationController(ctx)
+
+	donationGroup := r.Group("/donation")
+	donationGroup.Post("/create", auth.AllowDonor(), ctrl.DonationCreate)
+	donationGroup.Post("/guest", ctrl.GuestCreate)
+	donationGroup.Get("/mine", auth.AllowDonor(), ctrl.GetMine)
+	donationGroup.Get("/receipt/:id", auth.AllowDonor(), ctrl.Receipt)
+	donationGroup.Get("/guest/receipt/:id", ctrl.GuestReceipt)
+	donationGroup.Get("/campaign/:campaign_id", ctrl.GetByCampaign)
+	donationGroup.Get("/filter", auth.AllowSuperAdmin(), ctrl.GetAll)
+	donationGroup.Put("/review/:id", auth.AllowSuperAdmin(), ctrl.DonationReview)
+}
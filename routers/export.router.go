@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseExportRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewExportController(ctx)
+
+	exportGroup := r.Group("/export")
+	exportGroup.Get("/merchant", auth.AllowAll(), ctrl.Merchants)
+	exportGroup.Get("/detonator", auth.AllowAll(), ctrl.Detonators)
+	exportGroup.Get("/campaign", auth.AllowAll(), ctrl.Campaigns)
+	exportGroup.Get("/order", auth.AllowAll(), ctrl.Orders)
+	exportGroup.Get("/merchant-product", auth.AllowAll(), ctrl.Products)
+}
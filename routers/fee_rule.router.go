@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseFeeRuleRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewFeeRuleController(ctx)
+
+	feeRuleGroup := r.Group("/fee-rule")
+	feeRuleGroup.Post("/create", auth.AllowSuperAdmin(), ctrl.Create)
+	feeRuleGroup.Get("/filter", auth.AllowSuperAdmin(), ctrl.GetAll)
+	feeRuleGroup.Get("/fetch/:id", auth.AllowSuperAdmin(), ctrl.GetByID)
+	feeRuleGroup.Put("/update/:id", auth.AllowSuperAdmin(), ctrl.Update)
+	feeRuleGroup.Delete("/delete/:id", auth.AllowSuperAdmin(), ctrl.Delete)
+}
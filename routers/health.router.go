@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseHealthRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewHealthController(ctx)
+
+	healthGroup := r.Group("/health")
+	healthGroup.Get("/", ctrl.Health)
+	healthGroup.Get("/mail", auth.AllowSuperAdmin(), ctrl.MailHealth)
+}
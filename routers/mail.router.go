@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseMailRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewMailController(ctx)
+
+	mailGroup := r.Group("/mail")
+	mailGroup.Get("/templates", auth.AllowSuperAdmin(), ctrl.GetTemplates)
+	mailGroup.Get("/preview/:name", auth.AllowSuperAdmin(), ctrl.Preview)
+}
@@ -1,9 +1,7 @@
 package routers
 
 import (
-	"foodia-be/configs"
 	"foodia-be/controllers"
-	"foodia-be/enums"
 	"foodia-be/middlewares"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,8 +9,7 @@ import (
 )
 
 func UseMediaRouter(ctx context.Context, r fiber.Router) {
-	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
-	auth := middlewares.NewRBACMiddleware(config.JWTSecret, config.JWTExpirationDuration)
+	auth := middlewares.NewRBACMiddleware(ctx)
 	ctrl := controllers.NewMediaController(ctx)
 
 	mediaGroup := r.Group("/media")
@@ -1,9 +1,7 @@
 package routers
 
 import (
-	"foodia-be/configs"
 	"foodia-be/controllers"
-	"foodia-be/enums"
 	"foodia-be/middlewares"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,8 +9,7 @@ import (
 )
 
 func UseMerchantRouter(ctx context.Context, r fiber.Router) {
-	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
-	auth := middlewares.NewRBACMiddleware(config.JWTSecret, config.JWTExpirationDuration)
+	auth := middlewares.NewRBACMiddleware(ctx)
 	ctrl := controllers.NewMerchantController(ctx)
 
 	merchantGroup := r.Group("/merchant")
@@ -21,4 +18,12 @@ func UseMerchantRouter(ctx context.Context, r fiber.Router) {
 	merchantGroup.Get("/fetch/:id", auth.AllowAll(), ctrl.GetByID)
 	merchantGroup.Put("/approval/:id", auth.AllowAll(), ctrl.MerchantApproval)
 	merchantGroup.Put("/update/:id", auth.AllowAll(), ctrl.MerchantUpdate)
+	merchantGroup.Delete("/delete/:id", auth.AllowSuperAdmin(), ctrl.MerchantDelete)
+	merchantGroup.Put("/restore/:id", auth.AllowSuperAdmin(), ctrl.MerchantRestore)
+	merchantGroup.Get("/deleted", auth.AllowSuperAdmin(), ctrl.GetDeleted)
+	merchantGroup.Get("/schedule/:id", auth.AllowAll(), ctrl.GetSchedule)
+	merchantGroup.Put("/schedule/:id", auth.AllowAll(), ctrl.MerchantSchedule)
+	merchantGroup.Get("/availability/:id", auth.AllowAll(), ctrl.GetDay)
+	merchantGroup.Post("/closure/:id", auth.AllowAll(), ctrl.ClosureCreate)
+	merchantGroup.Delete("/closure/delete/:id", auth.AllowAll(), ctrl.ClosureDelete)
 }
@@ -1,9 +1,7 @@
 package routers
 
 import (
-	"foodia-be/configs"
 	"foodia-be/controllers"
-	"foodia-be/enums"
 	"foodia-be/middlewares"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,8 +9,7 @@ import (
 )
 
 func UseMerchantProductRouter(ctx context.Context, r fiber.Router) {
-	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
-	auth := middlewares.NewRBACMiddleware(config.JWTSecret, config.JWTExpirationDuration)
+	auth := middlewares.NewRBACMiddleware(ctx)
 	ctrl := controllers.NewMerchantProductController(ctx)
 
 	merchantGroup := r.Group("/merchant-product")
@@ -20,4 +17,15 @@ func UseMerchantProductRouter(ctx context.Context, r fiber.Router) {
 	merchantGroup.Get("/filter", auth.AllowAll(), ctrl.GetByMerchant)
 	merchantGroup.Put("/update/:id", auth.AllowAll(), ctrl.MerchantProductUpdate)
 	merchantGroup.Get("/fetch/:id", auth.AllowAll(), ctrl.GetByID)
+	merchantGroup.Get("/review", auth.AllowSuperAdmin(), ctrl.GetForReview)
+	merchantGroup.Put("/review/:id", auth.AllowSuperAdmin(), ctrl.MerchantProductReview)
+	merchantGroup.Put("/active/:id", auth.AllowAll(), ctrl.MerchantProductActivation)
+	merchantGroup.Post("/import", auth.AllowAll(), ctrl.MerchantProductImport)
+	merchantGroup.Get("/import/:id", auth.AllowAll(), ctrl.ImportStatus)
+	merchantGroup.Delete("/delete/:id", auth.AllowAll(), ctrl.MerchantProductDelete)
+	merchantGroup.Put("/restore/:id", auth.AllowSuperAdmin(), ctrl.MerchantProductRestore)
+	merchantGroup.Get("/deleted", auth.AllowSuperAdmin(), ctrl.GetDeleted)
+	merchantGroup.Get("/price/:id", auth.AllowAll(), ctrl.GetPrices)
+	merchantGroup.Post("/price/:id", auth.AllowAll(), ctrl.PriceSchedule)
+	merchantGroup.Delete("/price/cancel/:id", auth.AllowAll(), ctrl.PriceCancel)
 }
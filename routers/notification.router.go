@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseNotificationRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewNotificationController(ctx)
+
+	notificationGroup := r.Group("/notification")
+	notificationGroup.Get("/filter", auth.AllowEveryone(), ctrl.GetAll)
+	notificationGroup.Get("/unread-count", auth.AllowEveryone(), ctrl.UnreadCount)
+	notificationGroup.Put("/read/:id", auth.AllowEveryone(), ctrl.MarkRead)
+	notificationGroup.Put("/read-all", auth.AllowEveryone(), ctrl.MarkAllRead)
+}
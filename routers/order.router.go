@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseOrderRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewOrderController(ctx)
+
+	orderGroup := r.Group("/order")
+	orderGroup.Post("/create", auth.AllowDetonator(), ctrl.OrderCreate)
+	orderGroup.Get("/filter", auth.AllowAll(), ctrl.GetAll)
+	orderGroup.Get("/fetch/:id", auth.AllowAll(), ctrl.GetByID)
+	orderGroup.Put("/status/:id", auth.AllowMerchant(), ctrl.UpdateStatus)
+	orderGroup.Get("/delivery-token/:id", auth.AllowDetonator(), ctrl.DeliveryToken)
+	orderGroup.Put("/deliver", auth.AllowMerchant(), ctrl.Deliver)
+}
@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseOutboxRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewOutboxController(ctx)
+
+	outboxGroup := r.Group("/outbox")
+	outboxGroup.Get("/filter", auth.AllowSuperAdmin(), ctrl.GetAll)
+	outboxGroup.Get("/fetch/:id", auth.AllowSuperAdmin(), ctrl.GetByID)
+	outboxGroup.Put("/replay/:id", auth.AllowSuperAdmin(), ctrl.Replay)
+}
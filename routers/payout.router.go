@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UsePayoutRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewPayoutController(ctx)
+
+	payoutGroup := r.Group("/payout")
+	payoutGroup.Post("/batch/create", auth.AllowSuperAdmin(), ctrl.CreateBatch)
+	payoutGroup.Get("/batch/filter", auth.AllowSuperAdmin(), ctrl.GetBatches)
+	payoutGroup.Get("/batch/fetch/:id", auth.AllowSuperAdmin(), ctrl.GetBatch)
+	payoutGroup.Put("/batch/process/:id", auth.AllowSuperAdmin(), ctrl.ProcessBatch)
+	payoutGroup.Get("/filter", auth.AllowSuperAdmin(), ctrl.GetAll)
+	payoutGroup.Get("/statement/:merchant_id", auth.AllowSuperAdmin(), ctrl.MerchantStatement)
+	payoutGroup.Get("/mine", auth.AllowMerchant(), ctrl.GetMine)
+	payoutGroup.Get("/statement", auth.AllowMerchant(), ctrl.Statement)
+}
@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseProductCatalogRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewProductCatalogController(ctx)
+
+	catalogGroup := r.Group("/catalog")
+	catalogGroup.Get("/search", ctrl.Search)
+	catalogGroup.Get("/facets", ctrl.Facets)
+	catalogGroup.Get("/category", ctrl.GetCategories)
+	catalogGroup.Post("/category/create", auth.AllowSuperAdmin(), ctrl.CategoryCreate)
+	catalogGroup.Put("/category/update/:id", auth.AllowSuperAdmin(), ctrl.CategoryUpdate)
+	catalogGroup.Delete("/category/delete/:id", auth.AllowSuperAdmin(), ctrl.CategoryDelete)
+}
@@ -15,4 +15,19 @@ func UseRouter(ctx context.Context, r fiber.Router) {
 	UseMediaRouter(ctx, prefix)
 	UseMerchantProductRouter(ctx, prefix)
 	UseCampaignRouter(ctx, prefix)
+	UseCampaignReportRouter(ctx, prefix)
+	UseOutboxRouter(ctx, prefix)
+	UseMailRouter(ctx, prefix)
+	UseHealthRouter(ctx, prefix)
+	UseNotificationRouter(ctx, prefix)
+	UseOrderRouter(ctx, prefix)
+	UseStreamRouter(ctx, prefix)
+	UseWebhookRouter(ctx, prefix)
+	UsePayoutRouter(ctx, prefix)
+	UseFeeRuleRouter(ctx, prefix)
+	UseDonationRouter(ctx, prefix)
+	UseDocumentRouter(ctx, prefix)
+	UseDashboardRouter(ctx, prefix)
+	UseExportRouter(ctx, prefix)
+	UseProductCatalogRouter(ctx, prefix)
 }
@@ -0,0 +1,17 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseStreamRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewStreamController(ctx)
+
+	streamGroup := r.Group("/stream")
+	streamGroup.Get("/events", middlewares.NewQueryTokenMiddleware(), auth.AllowEveryone(), ctrl.Events)
+}
@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"foodia-be/controllers"
+	"foodia-be/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/net/context"
+)
+
+func UseWebhookRouter(ctx context.Context, r fiber.Router) {
+	auth := middlewares.NewRBACMiddleware(ctx)
+	ctrl := controllers.NewWebhookController(ctx)
+
+	webhookGroup := r.Group("/webhook")
+	webhookGroup.Post("/create", auth.AllowSuperAdmin(), ctrl.Create)
+	webhookGroup.Get("/filter", auth.AllowSuperAdmin(), ctrl.GetAll)
+	webhookGroup.Get("/fetch/:id", auth.AllowSuperAdmin(), ctrl.GetByID)
+	webhookGroup.Put("/update/:id", auth.AllowSuperAdmin(), ctrl.Update)
+	webhookGroup.Delete("/delete/:id", auth.AllowSuperAdmin(), ctrl.Delete)
+	webhookGroup.Post("/ping/:id", auth.AllowSuperAdmin(), ctrl.Ping)
+	webhookGroup.Get("/deliveries", auth.AllowSuperAdmin(), ctrl.GetDeliveries)
+	webhookGroup.Get("/delivery/:id", auth.AllowSuperAdmin(), ctrl.GetDelivery)
+	webhookGroup.Put("/redeliver/:id", auth.AllowSuperAdmin(), ctrl.Redeliver)
+}
@@ -2,8 +2,7 @@ package services
 
 import (
 	"context"
-	"embed"
-	"strings"
+	"crypto/subtle"
 	"time"
 
 	"foodia-be/common"
@@ -15,29 +14,37 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog"
-	"github.com/wneessen/go-mail"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+const (
+	// DonorOTPTTL is how long the login code of a donor stays valid.
+	DonorOTPTTL = 10 * time.Minute
+	// RegistrationOTPTTL is how long the code sent on registration stays valid.
+	RegistrationOTPTTL = 24 * time.Hour
+	// DonorOTPCooldown is how long a donor waits before asking for another code.
+	DonorOTPCooldown = time.Minute
+	// OTPMaxAttempts is how many wrong codes an OTP takes before it is void.
+	OTPMaxAttempts = 5
+)
+
 type AuthService struct {
-	DB     *gorm.DB
-	Log    *zerolog.Logger
-	Mail   *MailService
-	Config *configs.EnvConfig
+	DB      *gorm.DB
+	Log     *zerolog.Logger
+	Config  *configs.EnvConfig
+	Channel *ChannelService
 }
 
 func NewAuthService(ctx context.Context, db *gorm.DB) *AuthService {
 	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
 	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
-	smtp := ctx.Value(enums.SmtpCtxKey).(*mail.Client)
-	template := ctx.Value(enums.TemplateCtxKey).(embed.FS)
 
 	return &AuthService{
-		DB:     db,
-		Log:    logger,
-		Config: config,
-		Mail:   NewMailService(config.SmtpSender, smtp, template),
+		DB:      db,
+		Log:     logger,
+		Config:  config,
+		Channel: NewChannelService(ctx, db),
 	}
 }
 
@@ -57,6 +64,11 @@ func (service AuthService) BasicAuthentication(input dto.AuthRequest) (*dto.Auth
 		}
 	}
 
+	user, fail := service.account(oauth)
+	if fail != nil {
+		return nil, fail
+	}
+
 	claims := &dto.JWTClaims{
 		UserId:  oauth.ID,
 		Session: common.GenerateSHA256(service.Config.JWTSecret, oauth.Role),
@@ -75,57 +87,27 @@ func (service AuthService) BasicAuthentication(input dto.AuthRequest) (*dto.Auth
 		}
 	}
 
-	var status string
-	var note string
-	var userId int
-
-	if oauth.Role == "merchant" {
-		var merchant entities.Merchant
-		if err := service.DB.Find(&merchant, "user_id", oauth.ID).Error; err != nil {
-			return nil, &dto.ApiError{
-				StatusCode: fiber.ErrNotFound,
-				Message:    err.Error(),
-			}
-		}
-
-		status = merchant.Status
-		note = merchant.Note
-		userId = merchant.ID
-	}
-
-	if oauth.Role == "detonator" {
-		var detonator entities.Detonator
-		if err := service.DB.Find(&detonator, "user_id", oauth.ID).Error; err != nil {
-			return nil, &dto.ApiError{
-				StatusCode: fiber.ErrNotFound,
-				Message:    err.Error(),
-			}
-		}
-
-		status = detonator.Status
-		note = detonator.Note
-		userId = detonator.ID
-	}
-
 	oauthResponse := dto.AuthResponse{
 		Fullname: oauth.Fullname,
 		Phone:    oauth.Phone,
 		Email:    oauth.Email,
 		Role:     oauth.Role,
 		Token:    token.TokenString,
-		User: &dto.User{
-			Status: status,
-			Note:   note,
-			ID:     userId,
-		},
+		User:     user,
 	}
 
 	return &oauthResponse, nil
 }
 
-func (service AuthService) SendOTP(input dto.OTPRequest) *dto.ApiError {
-	tx := service.DB.Begin()
-	defer tx.Rollback()
+// SendOTP creates a new OTP for the email and queues its delivery in the outbox
+// on the user's preferred channel, falling back to email.
+// It writes through tx so the OTP and its message are committed together with
+// the caller's transaction and nothing is sent for a rolled back OTP.
+func (service AuthService) SendOTP(tx *gorm.DB, input dto.OTPRequest) *dto.ApiError {
+	// every code expires, those sent without an expiry get the shortest one
+	if input.ExpiredAt.IsZero() {
+		input.ExpiredAt = time.Now().Add(DonorOTPTTL)
+	}
 
 	OTP := entities.OauthOTP{
 		Email:     input.Email,
@@ -141,13 +123,18 @@ func (service AuthService) SendOTP(input dto.OTPRequest) *dto.ApiError {
 		}
 	}
 
-	go func() {
-		if err := service.Mail.SendOTP(input.Email, OTP.OTPCode); err != nil {
-			service.Log.Error().Msg(err.Error())
-		}
-	}()
+	mail := dto.OTPMail{
+		Code: OTP.OTPCode,
+	}
 
-	if err := tx.Commit().Error; err != nil {
+	recipient := dto.Recipient{
+		Email:   input.Email,
+		Phone:   input.Phone,
+		Locale:  input.Locale,
+		Channel: input.Channel,
+	}
+
+	if err := service.Channel.Notify(tx, recipient, mail); err != nil {
 		service.Log.Error().Msg(err.Error())
 		return &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -168,8 +155,9 @@ func (service AuthService) ValidateOTP(input dto.ValidateOTPRequest) (*dto.AuthR
 		}
 	}
 
+	// donors get a new OTP on every login, only the latest one counts
 	var otp entities.OauthOTP
-	if err := service.DB.First(&otp, "email", input.Email).Error; err != nil {
+	if err := service.DB.Order("id desc").First(&otp, "email", input.Email).Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
@@ -177,13 +165,36 @@ func (service AuthService) ValidateOTP(input dto.ValidateOTPRequest) (*dto.AuthR
 		}
 	}
 
-	if strings.Compare(input.Code, otp.OTPCode) != 0 {
+	if err := checkOTP(otp, input.Code, time.Now()); err != nil {
+		if err == enums.ErrOTPMismatch {
+			if err := service.DB.Model(&otp).UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+				service.Log.Error().Msg(err.Error())
+			}
+		}
+
+		return nil, otpError(err)
+	}
+
+	// a code is used once; the guarded delete also stops a concurrent login
+	// with the same code
+	consumed := service.DB.Where("id = ? AND attempts = ?", otp.ID, otp.Attempts).Delete(&entities.OauthOTP{})
+	if consumed.Error != nil {
+		service.Log.Error().Msg(consumed.Error.Error())
 		return nil, &dto.ApiError{
-			StatusCode: fiber.ErrBadRequest,
-			Message:    "OTP doesn't match, please recheck your OTP code",
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    consumed.Error.Error(),
 		}
 	}
 
+	if consumed.RowsAffected == 0 {
+		return nil, otpError(enums.ErrOTPMismatch)
+	}
+
+	user, fail := service.account(oauth)
+	if fail != nil {
+		return nil, fail
+	}
+
 	claims := &dto.JWTClaims{
 		UserId:  oauth.ID,
 		Session: common.GenerateSHA256(service.Config.JWTSecret, oauth.Role),
@@ -211,7 +222,261 @@ func (service AuthService) ValidateOTP(input dto.ValidateOTPRequest) (*dto.AuthR
 		Email:    oauth.Email,
 		Role:     oauth.Role,
 		Token:    token.TokenString,
+		User:     user,
 	}
 
 	return &oauthResponse, nil
 }
+
+// account makes sure oauth may still log in and returns the merchant or
+// detonator it belongs to. Accounts whose merchant or detonator was deleted
+// are refused.
+func (service AuthService) account(oauth entities.Oauth) (*dto.User, *dto.ApiError) {
+	if !oauth.IsActive || oauth.IsLocked {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	var model any
+	switch oauth.Role {
+	case "merchant":
+		model = &entities.Merchant{}
+	case "detonator":
+		model = &entities.Detonator{}
+	default:
+		return &dto.User{}, nil
+	}
+
+	var user dto.User
+	if err := service.DB.Model(model).Select("id", "status", "note").Where("user_id = ?", oauth.ID).Scan(&user).Error; err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if user.ID == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccountDeleted.Error(),
+		}
+	}
+
+	return &user, nil
+}
+
+// checkOTP tells whether code is accepted for the OTP at now. The attempts are
+// checked first so a voided code stays void even when guessed right.
+func checkOTP(otp entities.OauthOTP, code string, now time.Time) error {
+	if otp.Attempts >= OTPMaxAttempts {
+		return enums.ErrOTPAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(code), []byte(otp.OTPCode)) != 1 {
+		return enums.ErrOTPMismatch
+	}
+
+	// codes stored before every code had an expiry are not accepted anymore
+	if otp.ExpiredAt.IsZero() || now.After(otp.ExpiredAt) {
+		return enums.ErrOTPExpired
+	}
+
+	return nil
+}
+
+// otpError turns an error of checkOTP into its response.
+func otpError(err error) *dto.ApiError {
+	status := fiber.ErrBadRequest
+	if err == enums.ErrOTPAttempts {
+		status = fiber.ErrTooManyRequests
+	}
+
+	return &dto.ApiError{
+		StatusCode: status,
+		Message:    err.Error(),
+	}
+}
+
+// DonorRegistration creates a donor account, emails it a login OTP and welcomes it.
+// Donors have no password, they log in with a new OTP every time.
+func (service AuthService) DonorRegistration(input dto.DonorRegistration, locale string) (*dto.AuthResponse, *dto.ApiError) {
+	var registered int64
+	if err := service.DB.Model(&entities.Oauth{}).
+		Where("email = ? OR phone = ?", input.Email, input.Phone).
+		Count(&registered).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if registered > 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrConflict,
+			Message:    enums.ErrEmailRegistered.Error(),
+		}
+	}
+
+	// the password is never used but the column is required
+	secret, err := common.GenerateSecret(32)
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	oauth := entities.Oauth{
+		Fullname: input.Fullname,
+		Email:    input.Email,
+		Phone:    input.Phone,
+		UserId:   common.GenerateUUID(),
+		Password: string(password),
+		Role:     "donor",
+		Locale:   locale,
+	}
+
+	if err := tx.Create(&oauth).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if fail := service.sendDonorOTP(tx, &oauth); fail != nil {
+		return nil, fail
+	}
+
+	// donors need no review, so they are welcomed right away
+	welcome := dto.WelcomeMail{
+		Fullname: oauth.Fullname,
+	}
+
+	if err := service.Channel.Notify(tx, RecipientOf(&oauth), welcome); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &dto.AuthResponse{
+		Fullname: oauth.Fullname,
+		Phone:    oauth.Phone,
+		Email:    oauth.Email,
+		Role:     oauth.Role,
+	}, nil
+}
+
+// DonorOTP sends a login OTP to a donor. Other accounts log in with their
+// password and cannot ask for one.
+func (service AuthService) DonorOTP(input dto.DonorOTPRequest) *dto.ApiError {
+	var oauth entities.Oauth
+	if err := service.DB.First(&oauth, "email = ? AND role = ?", input.Email, "donor").Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if !oauth.IsActive || oauth.IsLocked {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	var recent int64
+	if err := service.DB.Model(&entities.OauthOTP{}).
+		Where("email = ? AND created_at > ?", oauth.Email, time.Now().Add(-DonorOTPCooldown)).
+		Count(&recent).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if recent > 0 {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrTooManyRequests,
+			Message:    enums.ErrOTPCooldown.Error(),
+		}
+	}
+
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	if fail := service.sendDonorOTP(tx, &oauth); fail != nil {
+		return fail
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// sendDonorOTP always mails the OTP, whatever channel the donor prefers for
+// their other messages.
+func (service AuthService) sendDonorOTP(tx *gorm.DB, oauth *entities.Oauth) *dto.ApiError {
+	return service.SendOTP(tx, dto.OTPRequest{
+		Email:     oauth.Email,
+		Locale:    oauth.Locale,
+		Channel:   enums.OutboxChannelEmail,
+		ExpiredAt: time.Now().Add(DonorOTPTTL),
+	})
+}
+
+// UpdateChannel stores the channel the user wants to receive OTPs and alerts on.
+func (service AuthService) UpdateChannel(userId int, input dto.ChannelPreference) *dto.ApiError {
+	var oauth entities.Oauth
+	if err := service.DB.First(&oauth, "id", userId).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := service.DB.Model(&oauth).Update("channel", input.Channel).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
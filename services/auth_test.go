@@ -0,0 +1,97 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCheckOTP(t *testing.T) {
+	now := time.Date(2023, 9, 17, 10, 0, 0, 0, time.UTC)
+	valid := now.Add(time.Minute)
+
+	tests := []struct {
+		name string
+		otp  entities.OauthOTP
+		code string
+		want error
+	}{
+		{"valid", entities.OauthOTP{OTPCode: "123456", ExpiredAt: valid}, "123456", nil},
+		{"no expiry", entities.OauthOTP{OTPCode: "123456"}, "123456", enums.ErrOTPExpired},
+		{"wrong code", entities.OauthOTP{OTPCode: "123456", ExpiredAt: valid}, "654321", enums.ErrOTPMismatch},
+		{"empty code", entities.OauthOTP{OTPCode: "123456", ExpiredAt: valid}, "", enums.ErrOTPMismatch},
+		{"prefix of the code", entities.OauthOTP{OTPCode: "123456", ExpiredAt: valid}, "12345", enums.ErrOTPMismatch},
+		{"expired", entities.OauthOTP{OTPCode: "123456", ExpiredAt: now.Add(-time.Second)}, "123456", enums.ErrOTPExpired},
+		{"last attempt left", entities.OauthOTP{OTPCode: "123456", ExpiredAt: valid, Attempts: OTPMaxAttempts - 1}, "123456", nil},
+		{"attempts used up", entities.OauthOTP{OTPCode: "123456", ExpiredAt: valid, Attempts: OTPMaxAttempts}, "123456", enums.ErrOTPAttempts},
+		{"attempts used up and wrong", entities.OauthOTP{OTPCode: "123456", ExpiredAt: valid, Attempts: OTPMaxAttempts}, "000000", enums.ErrOTPAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkOTP(tt.otp, tt.code, now); got != tt.want {
+				t.Fatalf("checkOTP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateOTP(t *testing.T) {
+	seen := map[string]bool{}
+
+	for i := 0; i < 50; i++ {
+		code := common.GenerateOTP()
+
+		if len(code) != 6 {
+			t.Fatalf("code %q is not 6 digits long", code)
+		}
+
+		for _, digit := range code {
+			if digit < '0' || digit > '9' {
+				t.Fatalf("code %q is not numeric", code)
+			}
+		}
+
+		seen[code] = true
+	}
+
+	if len(seen) < 45 {
+		t.Fatalf("only %d distinct codes out of 50", len(seen))
+	}
+}
+
+func TestAccount(t *testing.T) {
+	logger := zerolog.Nop()
+	service := AuthService{DB: dryRun(t), Log: &logger}
+
+	tests := []struct {
+		name  string
+		oauth entities.Oauth
+		want  error
+	}{
+		{"donor", entities.Oauth{ID: 1, Role: "donor", IsActive: true}, nil},
+		{"superadmin", entities.Oauth{ID: 2, Role: "superadmin", IsActive: true}, nil},
+		{"inactive", entities.Oauth{ID: 3, Role: "donor"}, enums.ErrAccessForbidden},
+		{"locked", entities.Oauth{ID: 4, Role: "donor", IsActive: true, IsLocked: true}, enums.ErrAccessForbidden},
+		{"locked merchant", entities.Oauth{ID: 5, Role: "merchant", IsActive: true, IsLocked: true}, enums.ErrAccessForbidden},
+		{"locked detonator", entities.Oauth{ID: 6, Role: "detonator", IsActive: true, IsLocked: true}, enums.ErrAccessForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, fail := service.account(tt.oauth)
+
+			switch {
+			case tt.want == nil && fail != nil:
+				t.Fatalf("unexpected error: %s", fail.Message)
+			case tt.want != nil && (fail == nil || fail.Message != tt.want.Error()):
+				t.Fatalf("account() = %+v, want %v", fail, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var percent = decimal.NewFromInt(100)
+
+type BudgetService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewBudgetService(ctx context.Context, db *gorm.DB) *BudgetService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &BudgetService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// Fees applies the active fee rules matching the event type to the donations
+// and order costs of a campaign.
+func (service BudgetService) Fees(tx *gorm.DB, eventType string, donations, orders decimal.Decimal) ([]dto.FeeLine, decimal.Decimal, error) {
+	var rules []entities.FeeRule
+	if err := tx.
+		Where("is_active = ? AND (event_type = ? OR event_type = ?)", true, "", eventType).
+		Order("id asc").
+		Find(&rules).Error; err != nil {
+		return nil, decimal.Zero, err
+	}
+
+	lines := []dto.FeeLine{}
+	total := decimal.Zero
+
+	for _, rule := range rules {
+		amount := rule.Value
+		if rule.Type == enums.FeeTypePercentage {
+			base := donations
+			if rule.Base == enums.FeeBaseOrder {
+				base = orders
+			}
+			amount = base.Mul(rule.Value).Div(percent).Round(2)
+		}
+
+		lines = append(lines, dto.FeeLine{
+			RuleID: rule.ID,
+			Name:   rule.Name,
+			Amount: amount,
+		})
+		total = total.Add(amount)
+	}
+
+	return lines, total, nil
+}
+
+// compute builds the budget of the campaign, counting extra as an order about
+// to be committed. Until the campaign is approved nothing is collected yet,
+// so its funds are the donation target.
+func (service BudgetService) compute(tx *gorm.DB, campaign *entities.Campaign, extra decimal.Decimal) (*dto.CampaignBudget, error) {
+	budget := dto.CampaignBudget{
+		CampaignID:     campaign.ID,
+		DonationTarget: campaign.DonationTarget,
+		Collected:      campaign.Collected,
+		Override:       campaign.BudgetOverride,
+	}
+
+	if err := tx.Model(&entities.Order{}).
+		Select("COALESCE(SUM(price * qty), 0)").
+		Where("campaign_id = ? AND order_status <> ?", campaign.ID, enums.OrderStatusRejected).
+		Scan(&budget.Committed).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&entities.Order{}).
+		Select("COALESCE(SUM(price * qty), 0)").
+		Where("campaign_id = ? AND order_status = ?", campaign.ID, enums.OrderStatusDelivered).
+		Scan(&budget.Delivered).Error; err != nil {
+		return nil, err
+	}
+
+	budget.Committed = budget.Committed.Add(extra)
+
+	funds := campaign.Collected
+	if campaign.Status == enums.CampaignStatusDraft || campaign.Status == enums.CampaignStatusReview {
+		funds = campaign.DonationTarget
+	}
+
+	fees, feeTotal, err := service.Fees(tx, campaign.EventType, funds, budget.Committed)
+	if err != nil {
+		return nil, err
+	}
+
+	budget.Fees = fees
+	budget.FeeTotal = feeTotal
+	budget.Available = funds.Sub(feeTotal)
+	budget.Remaining = budget.Available.Sub(budget.Committed)
+
+	return &budget, nil
+}
+
+// Covers tells whether the funds of the campaign cover its orders plus extra,
+// within tx. Campaigns an admin overrode are always covered.
+func (service BudgetService) Covers(tx *gorm.DB, campaign *entities.Campaign, extra decimal.Decimal) (bool, error) {
+	if campaign.BudgetOverride {
+		return true, nil
+	}
+
+	budget, err := service.compute(tx, campaign, extra)
+	if err != nil {
+		return false, err
+	}
+
+	return !budget.Remaining.IsNegative(), nil
+}
+
+// Budget is the budget allocation of a campaign. When userId is set the
+// campaign must belong to the detonator of that user.
+func (service BudgetService) Budget(userId int, id string) (*dto.CampaignBudget, *dto.ApiError) {
+	var campaign entities.Campaign
+	if err := service.DB.
+		Preload("Detonator").
+		First(&campaign, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if userId != 0 && (campaign.Detonator == nil || campaign.Detonator.UserId != userId) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	budget, err := service.compute(service.DB, &campaign, decimal.Zero)
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return budget, nil
+}
+
+// Override lets orders of the campaign exceed its funds, or stops it again.
+func (service BudgetService) Override(id string, input dto.BudgetOverrideRequest) (*dto.CampaignBudget, *dto.ApiError) {
+	result := service.DB.Model(&entities.Campaign{}).
+		Where("id = ?", id).
+		Update("budget_override", *input.Override)
+	if result.Error != nil {
+		service.Log.Error().Msg(result.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    result.Error.Error(),
+		}
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    enums.ErrNotFound.Error(),
+		}
+	}
+
+	service.Log.Info().Msg(fmt.Sprintf("budget override of campaign %s set to %t: %s", id, *input.Override, input.Note))
+
+	return service.Budget(0, id)
+}
+
+func (service BudgetService) CreateRule(input dto.FeeRuleRequest) (*entities.FeeRule, *dto.ApiError) {
+	if input.Type == enums.FeeTypePercentage && input.Value > 100 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrFeePercentage.Error(),
+		}
+	}
+
+	base := input.Base
+	if base == "" {
+		base = enums.FeeBaseDonation
+	}
+
+	rule := entities.FeeRule{
+		Name:      input.Name,
+		Type:      input.Type,
+		Base:      base,
+		Value:     decimal.NewFromFloat(input.Value),
+		EventType: input.EventType,
+		IsActive:  input.IsActive == nil || *input.IsActive,
+	}
+
+	if err := service.DB.Create(&rule).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &rule, nil
+}
+
+func (service BudgetService) GetRules(c *fiber.Ctx, pagination *common.Pagination) ([]entities.FeeRule, *dto.ApiError) {
+	var rules []entities.FeeRule
+
+	query := service.DB.Order("created_at desc")
+
+	if eventType := c.Query("event_type"); eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+
+	switch c.Query("is_active") {
+	case "true":
+		query = query.Where("is_active = ?", true)
+	case "false":
+		query = query.Where("is_active = ?", false)
+	}
+
+	query = query.Find(&rules)
+
+	if err := query.Scopes(common.Paginate(query, entities.FeeRule{}, pagination)).Find(&rules); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return rules, nil
+}
+
+func (service BudgetService) GetRule(id string) (*entities.FeeRule, *dto.ApiError) {
+	var rule entities.FeeRule
+
+	if err := service.DB.Where("id", id).First(&rule).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &rule, nil
+}
+
+func (service BudgetService) UpdateRule(id string, input dto.FeeRuleRequest) (*entities.FeeRule, *dto.ApiError) {
+	rule, fail := service.GetRule(id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	if input.Type == enums.FeeTypePercentage && input.Value > 100 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrFeePercentage.Error(),
+		}
+	}
+
+	base := input.Base
+	if base == "" {
+		base = enums.FeeBaseDonation
+	}
+
+	update := map[string]any{
+		"name":       input.Name,
+		"type":       input.Type,
+		"base":       base,
+		"value":      decimal.NewFromFloat(input.Value),
+		"event_type": input.EventType,
+	}
+
+	if input.IsActive != nil {
+		update["is_active"] = *input.IsActive
+	}
+
+	if err := service.DB.Model(rule).Updates(update).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetRule(id)
+}
+
+func (service BudgetService) DeleteRule(id string) *dto.ApiError {
+	rule, fail := service.GetRule(id)
+	if fail != nil {
+		return fail
+	}
+
+	if err := service.DB.Delete(rule).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
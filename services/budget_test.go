@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+
+	"foodia-be/dto"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+func TestCreateRuleActive(t *testing.T) {
+	active, inactive := true, false
+
+	tests := []struct {
+		name     string
+		isActive *bool
+		want     bool
+	}{
+		{"active by default", nil, true},
+		{"active", &active, true},
+		{"inactive", &inactive, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dryRun(t)
+
+			var statement *gorm.Statement
+			if err := db.Callback().Create().After("gorm:create").Register("test:statement", func(tx *gorm.DB) {
+				statement = tx.Statement
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			logger := zerolog.Nop()
+			service := BudgetService{DB: db, Log: &logger}
+
+			rule, fail := service.CreateRule(dto.FeeRuleRequest{
+				Name:     "Platform fee",
+				Type:     enums.FeeTypePercentage,
+				Value:    5,
+				IsActive: tt.isActive,
+			})
+			if fail != nil {
+				t.Fatalf("unexpected error: %s", fail.Message)
+			}
+
+			if rule.IsActive != tt.want {
+				t.Fatalf("rule.IsActive = %v, want %v", rule.IsActive, tt.want)
+			}
+
+			if value, ok := inserted(t, statement, "is_active"); !ok || value != tt.want {
+				t.Fatalf("is_active is written as %v (%v), want %v", value, ok, tt.want)
+			}
+		})
+	}
+}
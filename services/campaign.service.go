@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"foodia-be/common"
 	"foodia-be/dto"
@@ -12,32 +13,125 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// CampaignTransitions lists the statuses a campaign may move to from each status.
+var CampaignTransitions = map[string][]string{
+	enums.CampaignStatusDraft:       {enums.CampaignStatusReview, enums.CampaignStatusCancelled},
+	enums.CampaignStatusReview:      {enums.CampaignStatusFundraising, enums.CampaignStatusDraft, enums.CampaignStatusCancelled},
+	enums.CampaignStatusFundraising: {enums.CampaignStatusFunded, enums.CampaignStatusReview, enums.CampaignStatusCancelled},
+	enums.CampaignStatusFunded:      {enums.CampaignStatusExecuting, enums.CampaignStatusCancelled},
+	enums.CampaignStatusExecuting:   {enums.CampaignStatusCompleted},
+}
+
+// CanTransition reports whether a campaign may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, allowed := range CampaignTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// campaignActive tells whether campaigns in the status are shown as running.
+func campaignActive(status string) bool {
+	switch status {
+	case enums.CampaignStatusFundraising, enums.CampaignStatusFunded, enums.CampaignStatusExecuting:
+		return true
+	}
+
+	return false
+}
+
+// campaignEditable tells whether the details of campaigns in the status may still change.
+func campaignEditable(status string) bool {
+	switch status {
+	case enums.CampaignStatusDraft, enums.CampaignStatusReview, enums.CampaignStatusFundraising:
+		return true
+	}
+
+	return false
+}
+
 type CampaignService struct {
-	DB  *gorm.DB
-	Log *zerolog.Logger
+	DB           *gorm.DB
+	Log          *zerolog.Logger
+	Channel      *ChannelService
+	Notification *NotificationService
+	Stream       *StreamService
+	Webhook      *WebhookService
+	Budget       *BudgetService
 }
 
 func NewCampaignService(ctx context.Context, db *gorm.DB) *CampaignService {
 	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
 
 	return &CampaignService{
-		DB:  db,
-		Log: logger,
+		DB:           db,
+		Log:          logger,
+		Channel:      NewChannelService(ctx, db),
+		Notification: NewNotificationService(ctx, db),
+		Stream:       NewStreamService(ctx),
+		Webhook:      NewWebhookService(ctx, db),
+		Budget:       NewBudgetService(ctx, db),
 	}
 }
 
-func (service CampaignService) Create(input dto.CampaignRequest) (*dto.CampaignRequest, *dto.ApiError) {
+// Create opens a campaign for the detonator of ownerId. Superadmins pass an
+// ownerId of 0 and pick the detonator with input.DetonatorID.
+func (service CampaignService) Create(ownerId int, input dto.CampaignRequest) (*dto.CampaignRequest, *dto.ApiError) {
+	if ownerId != 0 {
+		var detonator entities.Detonator
+		if err := service.DB.Where("user_id = ?", ownerId).First(&detonator).Error; err != nil {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrForbidden,
+				Message:    enums.ErrAccessForbidden.Error(),
+			}
+		}
+
+		input.DetonatorID = detonator.ID
+	} else if input.DetonatorID == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBadParamInput.Error(),
+		}
+	}
+
+	timezone := input.Timezone
+	if timezone == "" {
+		timezone = enums.TimezoneWIB
+	}
+
+	eventAt, err := common.ParseEventTime(input.EventDate, input.EventTime, timezone)
+	if err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    err.Error(),
+		}
+	}
+
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
+	events := service.Stream.Batch()
+
+	status := enums.CampaignStatusReview
+	if input.IsDraft {
+		status = enums.CampaignStatusDraft
+	}
+
+	now := time.Now()
 	campaign := entities.Campaign{
 		DetonatorID:    input.DetonatorID,
 		EventName:      input.EventName,
 		EventType:      input.EventType,
 		EventDate:      input.EventDate,
 		EventTime:      input.EventTime,
+		EventAt:        &eventAt,
+		Timezone:       timezone,
 		Description:    input.Description,
 		DonationTarget: decimal.NewFromFloat(input.DonationTarget),
 		Province:       input.Province,
@@ -48,6 +142,8 @@ func (service CampaignService) Create(input dto.CampaignRequest) (*dto.CampaignR
 		Latitude:       input.Latitude,
 		Longitude:      input.Longitude,
 		ImageURL:       input.ImageURL,
+		Status:         status,
+		StatusAt:       &now,
 	}
 
 	if err := tx.Create(&campaign).Error; err != nil {
@@ -59,11 +155,68 @@ func (service CampaignService) Create(input dto.CampaignRequest) (*dto.CampaignR
 	}
 
 	var orders []entities.Order
+	portions := map[int]int{}
+	requested := map[int]int{}
 
 	for _, product := range input.Products {
+		// products are locked until commit so concurrent orders cannot both
+		// take the last of their stock
+		var merchantProduct entities.MerchantProduct
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&merchantProduct, "id", product.MerchantProductID).Error; err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    err.Error(),
+			}
+		}
+
+		qty := 1
+		if product.Qty != nil {
+			qty = *product.Qty
+		}
+
+		held, err := reserved(tx, merchantProduct.ID)
+		if err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		// a product listed more than once takes its stock for every line
+		requested[merchantProduct.ID] += qty
+		if !available(merchantProduct, held+requested[merchantProduct.ID]) {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrProductUnavailable.Error(),
+			}
+		}
+
+		// products of the same merchant share its capacity for the day
+		portions[merchantProduct.MerchantID] += qty
+		if err := checkAvailability(tx, merchantProduct.MerchantID, campaign, portions[merchantProduct.MerchantID]); err != nil {
+			return nil, availabilityError(service.Log, err)
+		}
+
+		price, err := effectivePrice(tx, merchantProduct, time.Now())
+		if err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		// the price is kept on the order so later price changes do not alter
+		// what the campaign committed to pay
 		orders = append(orders, entities.Order{
 			MerchantProductID: product.MerchantProductID,
 			CampaignID:        campaign.ID,
+			Qty:               qty,
+			Price:             price,
 		})
 	}
 
@@ -75,6 +228,29 @@ func (service CampaignService) Create(input dto.CampaignRequest) (*dto.CampaignR
 		}
 	}
 
+	if covered, err := service.Budget.Covers(tx, &campaign, decimal.Zero); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	} else if !covered {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBudgetExceeded.Error(),
+		}
+	}
+
+	if status == enums.CampaignStatusReview {
+		if err := service.notifySubmitted(tx, events, &campaign); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
@@ -83,27 +259,20 @@ func (service CampaignService) Create(input dto.CampaignRequest) (*dto.CampaignR
 		}
 	}
 
+	events.Publish()
+
 	return &input, nil
 }
 
 func (service CampaignService) GetAll(c *fiber.Ctx, pagination *common.Pagination) ([]entities.Campaign, *dto.ApiError) {
 	var campaigns []entities.Campaign
 
-	var where string
-	detonatorId := c.Query("detonator_id", "")
-
-	where = `1 = 1`
-
-	if detonatorId != "" {
-		where += ` AND detonator_id = '` + detonatorId + `' `
-	}
-
 	query := service.DB.
 		Preload("Detonator").
 		Preload("Detonator.Oauth").
-		Order("created_at desc").
-		Where(where).
-		Find(&campaigns)
+		Order("created_at desc")
+
+	query = filterCampaigns(c, query).Find(&campaigns)
 
 	if err := query.Scopes(common.Paginate(query, entities.Campaign{}, pagination)).Find(&campaigns); err.Error != nil {
 		service.Log.Error().Msg(err.Error.Error())
@@ -116,12 +285,28 @@ func (service CampaignService) GetAll(c *fiber.Ctx, pagination *common.Paginatio
 	return campaigns, nil
 }
 
+// filterCampaigns narrows query down to the campaigns matching the filters of
+// the campaign list.
+func filterCampaigns(c *fiber.Ctx, query *gorm.DB) *gorm.DB {
+	if detonatorId := c.Query("detonator_id"); detonatorId != "" {
+		query = query.Where("detonator_id = ?", detonatorId)
+	}
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	return query
+}
+
 func (service CampaignService) GetByID(id string) (*entities.Campaign, *dto.ApiError) {
 	var campaign entities.Campaign
 
 	if err := service.DB.
 		Preload("Detonator").
 		Preload("Detonator.Oauth").
+		Preload("Report").
+		Preload("Report.Photos").
 		Where("id", id).
 		First(&campaign); err.Error != nil {
 		service.Log.Error().Msg(err.Error.Error())
@@ -134,12 +319,40 @@ func (service CampaignService) GetByID(id string) (*entities.Campaign, *dto.ApiE
 	return &campaign, nil
 }
 
-func (service CampaignService) Update(id string, input dto.CampaignRequest) (*dto.CampaignRequest, *dto.ApiError) {
+// GetDetail is the public campaign detail. It leaves out the detonator's
+// account and only includes the distribution report once it is verified.
+func (service CampaignService) GetDetail(id string) (*entities.Campaign, *dto.ApiError) {
+	var campaign entities.Campaign
+
+	if err := service.DB.
+		Preload("Report", "status = ?", enums.CampaignReportVerified).
+		Preload("Report.Photos").
+		Where("id", id).
+		First(&campaign); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return &campaign, nil
+}
+
+// Update changes the details of a campaign that is still editable. When
+// ownerId is set the campaign must belong to the detonator of that user.
+// Fundraising campaigns whose target or event changes go back to review, and
+// a new event time must still suit the merchants of its open orders.
+func (service CampaignService) Update(ownerId int, id string, input dto.CampaignRequest) (*dto.CampaignRequest, *dto.ApiError) {
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
+	events := service.Stream.Batch()
+
 	var campaign entities.Campaign
-	if err := tx.First(&campaign, "id", id).Error; err != nil {
+	if err := tx.
+		Preload("Detonator").
+		First(&campaign, "id", id).Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
@@ -147,12 +360,47 @@ func (service CampaignService) Update(id string, input dto.CampaignRequest) (*dt
 		}
 	}
 
+	if ownerId != 0 && (campaign.Detonator == nil || campaign.Detonator.UserId != ownerId) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	if input.DetonatorID != 0 && input.DetonatorID != campaign.DetonatorID {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrCampaignDetonator.Error(),
+		}
+	}
+
+	if !campaignEditable(campaign.Status) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrCampaignLocked.Error(),
+		}
+	}
+
+	timezone := input.Timezone
+	if timezone == "" {
+		timezone = campaign.Timezone
+	}
+
+	eventAt, err := common.ParseEventTime(input.EventDate, input.EventTime, timezone)
+	if err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    err.Error(),
+		}
+	}
+
 	update := entities.Campaign{
-		DetonatorID:    input.DetonatorID,
 		EventName:      input.EventName,
 		EventType:      input.EventType,
 		EventDate:      input.EventDate,
 		EventTime:      input.EventTime,
+		EventAt:        &eventAt,
+		Timezone:       timezone,
 		Description:    input.Description,
 		DonationTarget: decimal.NewFromFloat(input.DonationTarget),
 		Province:       input.Province,
@@ -165,6 +413,15 @@ func (service CampaignService) Update(id string, input dto.CampaignRequest) (*dt
 		ImageURL:       input.ImageURL,
 	}
 
+	retargeted := !update.DonationTarget.Equal(campaign.DonationTarget)
+	rescheduled := campaign.EventAt == nil || !campaign.EventAt.Equal(eventAt) || campaign.Timezone != timezone
+
+	if rescheduled {
+		if fail := service.checkReschedule(tx, campaign, update); fail != nil {
+			return nil, fail
+		}
+	}
+
 	if err := tx.Model(&campaign).Updates(&update).Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
@@ -173,6 +430,37 @@ func (service CampaignService) Update(id string, input dto.CampaignRequest) (*dt
 		}
 	}
 
+	// donors gave to what was approved, so changes to it are reviewed again
+	if campaign.Status == enums.CampaignStatusFundraising && (retargeted || rescheduled) {
+		if err := service.transition(tx, events, &campaign, enums.CampaignStatusReview, ""); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	// before approval the target is what pays for the orders, so a lower
+	// target must still cover them
+	if campaign.Status == enums.CampaignStatusDraft || campaign.Status == enums.CampaignStatusReview {
+		campaign.DonationTarget = update.DonationTarget
+		campaign.EventType = update.EventType
+
+		if covered, err := service.Budget.Covers(tx, &campaign, decimal.Zero); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		} else if !covered {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrBudgetExceeded.Error(),
+			}
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
@@ -181,5 +469,466 @@ func (service CampaignService) Update(id string, input dto.CampaignRequest) (*dt
 		}
 	}
 
+	events.Publish()
+
 	return &input, nil
 }
+
+// checkReschedule makes sure the merchants of the open orders of the campaign
+// are open at the new event time of update and, when the event moves to
+// another day, have room for the portions there. It runs before the campaign
+// row changes, so the portions are not yet counted on the new day.
+func (service CampaignService) checkReschedule(tx *gorm.DB, campaign entities.Campaign, update entities.Campaign) *dto.ApiError {
+	var portions []struct {
+		MerchantID int
+		Qty        int
+	}
+
+	if err := tx.Model(&entities.Order{}).
+		Select("merchant_products.merchant_id, SUM(orders.qty) AS qty").
+		Joins("JOIN merchant_products ON merchant_products.id = orders.merchant_product_id").
+		Where("orders.campaign_id = ? AND orders.order_status IN ?", campaign.ID, []string{enums.OrderStatusWaiting, enums.OrderStatusApproved}).
+		Group("merchant_products.merchant_id").
+		Scan(&portions).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	moved := campaign
+	moved.EventDate = update.EventDate
+	moved.EventTime = update.EventTime
+	moved.EventAt = update.EventAt
+	moved.Timezone = update.Timezone
+
+	from, _ := eventDay(campaign)
+	to, _ := eventDay(moved)
+
+	for _, portion := range portions {
+		qty := portion.Qty
+		if from == to {
+			qty = 0
+		}
+
+		if err := checkAvailability(tx, portion.MerchantID, moved, qty); err != nil {
+			return availabilityError(service.Log, err)
+		}
+	}
+
+	return nil
+}
+
+// Approval records the review of a campaign: approved campaigns start
+// fundraising, rejected ones go back to draft for the detonator to rework.
+func (service CampaignService) Approval(id string, input dto.CampaignApproval) (*entities.Campaign, *dto.ApiError) {
+	to := enums.CampaignStatusFundraising
+	if input.Status == "rejected" {
+		to = enums.CampaignStatusDraft
+	}
+
+	return service.Advance(id, 0, to, input.Note)
+}
+
+// Submit sends a draft campaign of the detonator's to review.
+func (service CampaignService) Submit(userId int, id string) (*entities.Campaign, *dto.ApiError) {
+	return service.Advance(id, userId, enums.CampaignStatusReview, "")
+}
+
+// Cancel stops a campaign that has not completed yet. When userId is set the
+// campaign must belong to that detonator.
+func (service CampaignService) Cancel(userId int, id string, input dto.CampaignCancel) (*entities.Campaign, *dto.ApiError) {
+	return service.Advance(id, userId, enums.CampaignStatusCancelled, input.Note)
+}
+
+// Advance moves the campaign to status in its own transaction. When ownerId is
+// set the campaign must belong to the detonator of that user.
+func (service CampaignService) Advance(id string, ownerId int, to, note string) (*entities.Campaign, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	var campaign entities.Campaign
+	if err := tx.
+		Preload("Detonator").
+		Preload("Detonator.Oauth").
+		First(&campaign, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if ownerId != 0 && (campaign.Detonator == nil || campaign.Detonator.UserId != ownerId) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	if !CanTransition(campaign.Status, to) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrCampaignTransition.Error(),
+		}
+	}
+
+	if err := service.transition(tx, events, &campaign, to, note); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	events.Publish()
+
+	return &campaign, nil
+}
+
+// transition stores the new status within tx and tells everyone concerned.
+// The update is guarded on the current status so a concurrent change, e.g. by
+// the scheduler on another instance, makes it fail instead of being overwritten.
+func (service CampaignService) transition(tx *gorm.DB, events *EventBatch, campaign *entities.Campaign, to, note string) error {
+	if !CanTransition(campaign.Status, to) {
+		return enums.ErrCampaignTransition
+	}
+
+	now := time.Now()
+	result := tx.Model(&entities.Campaign{}).
+		Where("id = ? AND status = ?", campaign.ID, campaign.Status).
+		Updates(map[string]any{
+			"status":      to,
+			"status_note": note,
+			"status_at":   now,
+			"is_active":   campaignActive(to),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return enums.ErrCampaignTransition
+	}
+
+	from := campaign.Status
+	campaign.Status = to
+	campaign.StatusNote = note
+	campaign.StatusAt = &now
+	campaign.IsActive = campaignActive(to)
+
+	events.Add(dto.StreamEvent{
+		Topic: enums.StreamTopicCampaign,
+		Type:  to,
+		Data: map[string]any{
+			"campaign_id": campaign.ID,
+			"from":        from,
+			"status":      to,
+		},
+	})
+
+	switch {
+	case to == enums.CampaignStatusReview:
+		return service.notifySubmitted(tx, events, campaign)
+	case from == enums.CampaignStatusReview && to == enums.CampaignStatusFundraising:
+		if err := service.notifyReview(tx, events, campaign, "approved", note); err != nil {
+			return err
+		}
+
+		if err := service.notifyOrders(tx, events, campaign); err != nil {
+			return err
+		}
+
+		return service.Webhook.Publish(tx, enums.WebhookEventCampaignApproved, webhookCampaign(campaign))
+	case from == enums.CampaignStatusReview && to == enums.CampaignStatusDraft:
+		return service.notifyReview(tx, events, campaign, "rejected", note)
+	case to == enums.CampaignStatusFunded:
+		if err := service.Webhook.Publish(tx, enums.WebhookEventCampaignFunded, webhookCampaign(campaign)); err != nil {
+			return err
+		}
+	case to == enums.CampaignStatusCompleted:
+		if err := service.Webhook.Publish(tx, enums.WebhookEventCampaignCompleted, webhookCampaign(campaign)); err != nil {
+			return err
+		}
+	}
+
+	return service.notifyStatusChanged(tx, events, campaign)
+}
+
+// notifySubmitted asks the superadmins to review a campaign.
+func (service CampaignService) notifySubmitted(tx *gorm.DB, events *EventBatch, campaign *entities.Campaign) error {
+	submitted := dto.NotificationEvent{
+		Type:    enums.NotificationCampaignSubmitted,
+		RefType: enums.NotificationRefCampaign,
+		RefID:   campaign.ID,
+		Params: map[string]any{
+			"EventName": campaign.EventName,
+			"EventDate": campaign.EventDate,
+		},
+	}
+
+	return service.Notification.PushRole(tx, events, "superadmin", submitted)
+}
+
+// notifyOrders tells each merchant about the order placed on their product
+// once the campaign is approved.
+func (service CampaignService) notifyOrders(tx *gorm.DB, events *EventBatch, campaign *entities.Campaign) error {
+	var orders []entities.Order
+	if err := tx.
+		Preload("MerchantProduct").
+		Preload("MerchantProduct.Merchant").
+		Preload("MerchantProduct.Merchant.Oauth").
+		Where("campaign_id = ?", campaign.ID).
+		Find(&orders).Error; err != nil {
+		return err
+	}
+
+	for i := range orders {
+		if err := notifyOrderReceived(tx, events, service.Notification, service.Channel, campaign, &orders[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyReview tells the detonator about the outcome of the campaign review.
+func (service CampaignService) notifyReview(tx *gorm.DB, events *EventBatch, campaign *entities.Campaign, status, note string) error {
+	if campaign.Detonator == nil || campaign.Detonator.Oauth == nil {
+		return nil
+	}
+
+	oauth := campaign.Detonator.Oauth
+	event := dto.NotificationEvent{
+		RefType: enums.NotificationRefCampaign,
+		RefID:   campaign.ID,
+		Params: map[string]any{
+			"EventName": campaign.EventName,
+			"Note":      note,
+		},
+	}
+
+	switch status {
+	case "approved":
+		event.Type = enums.NotificationCampaignApproved
+		if err := service.Channel.Notify(tx, RecipientOf(oauth), dto.CampaignApprovedMail{
+			Fullname:  oauth.Fullname,
+			EventName: campaign.EventName,
+			EventDate: campaign.EventDate,
+		}); err != nil {
+			return err
+		}
+	case "rejected":
+		event.Type = enums.NotificationCampaignRejected
+	default:
+		return nil
+	}
+
+	return service.Notification.Push(tx, events, oauth, event)
+}
+
+// notifyStatusChanged tells the detonator about any other status change.
+func (service CampaignService) notifyStatusChanged(tx *gorm.DB, events *EventBatch, campaign *entities.Campaign) error {
+	if campaign.Detonator == nil || campaign.Detonator.Oauth == nil {
+		return nil
+	}
+
+	return service.Notification.Push(tx, events, campaign.Detonator.Oauth, dto.NotificationEvent{
+		Type:    enums.NotificationCampaignStatusChanged,
+		RefType: enums.NotificationRefCampaign,
+		RefID:   campaign.ID,
+		Params: map[string]any{
+			"EventName": campaign.EventName,
+			"Status":    campaign.Status,
+			"Note":      campaign.StatusNote,
+		},
+	})
+}
+
+// webhookCampaign is the campaign as shared with webhook subscribers.
+func webhookCampaign(campaign *entities.Campaign) dto.WebhookCampaignData {
+	return dto.WebhookCampaignData{
+		ID:             campaign.ID,
+		EventName:      campaign.EventName,
+		EventType:      campaign.EventType,
+		EventDate:      campaign.EventDate,
+		EventTime:      campaign.EventTime,
+		DonationTarget: campaign.DonationTarget,
+		Province:       campaign.Province,
+		City:           campaign.City,
+		Status:         campaign.Status,
+	}
+}
+
+// Delete soft deletes a draft or cancelled campaign along with its orders.
+// When ownerId is set the campaign must belong to the detonator of that user.
+func (service CampaignService) Delete(ownerId int, id string) *dto.ApiError {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	var campaign entities.Campaign
+	if err := tx.Preload("Detonator").First(&campaign, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if ownerId != 0 && (campaign.Detonator == nil || campaign.Detonator.UserId != ownerId) {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	if campaign.Status != enums.CampaignStatusDraft && campaign.Status != enums.CampaignStatusCancelled {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrCampaignNotDeletable.Error(),
+		}
+	}
+
+	if err := deleteCampaigns(tx, []int{campaign.ID}, time.Now()); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// Restore brings back a deleted campaign with the orders deleted along with
+// it, unless its detonator is deleted too.
+func (service CampaignService) Restore(id string) (*entities.Campaign, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	var campaign entities.Campaign
+	if err := tx.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&campaign).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	var detonators int64
+	if err := tx.Model(&entities.Detonator{}).Where("id = ?", campaign.DetonatorID).Count(&detonators).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if detonators == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrRestoreOwner.Error(),
+		}
+	}
+
+	if err := restoreCampaigns(tx, []int{campaign.ID}, campaign.DeletedAt.Time); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetByID(id)
+}
+
+// GetDeleted lists the deleted campaigns, of the ?detonator_id= when given.
+func (service CampaignService) GetDeleted(c *fiber.Ctx, pagination *common.Pagination) ([]entities.Campaign, *dto.ApiError) {
+	var campaigns []entities.Campaign
+
+	query := service.DB.Unscoped().
+		Preload("Detonator", unscoped).
+		Preload("Detonator.Oauth").
+		Order("deleted_at desc").
+		Where("deleted_at IS NOT NULL")
+
+	if detonatorId := c.Query("detonator_id"); detonatorId != "" {
+		query = query.Where("detonator_id = ?", detonatorId)
+	}
+
+	query = query.Find(&campaigns)
+
+	if err := query.Scopes(common.Paginate(query, entities.Campaign{}, pagination)).Find(&campaigns); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return campaigns, nil
+}
+
+// deleteCampaigns soft deletes the campaigns and their orders, marking them
+// all with the same time so restoring one brings back what went with it.
+func deleteCampaigns(tx *gorm.DB, ids []int, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// kept to the second so the marks compare equal whatever the precision of
+	// each deleted_at column
+	at = at.Truncate(time.Second)
+
+	if err := tx.Model(&entities.Order{}).Where("campaign_id IN ?", ids).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&entities.Campaign{}).Where("id IN ?", ids).Update("deleted_at", at).Error
+}
+
+// restoreCampaigns brings back the campaigns and orders deleted at the time.
+func restoreCampaigns(tx *gorm.DB, ids []int, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := tx.Unscoped().Model(&entities.Order{}).
+		Where("campaign_id IN ? AND deleted_at = ?", ids, at).
+		Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Model(&entities.Campaign{}).
+		Where("id IN ? AND deleted_at = ?", ids, at).
+		Update("deleted_at", nil).Error
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultReportMaxDistance = 1000
+	reportMediaDestination   = "report"
+)
+
+type CampaignReportService struct {
+	DB           *gorm.DB
+	Log          *zerolog.Logger
+	MaxDistance  float64
+	Notification *NotificationService
+	Stream       *StreamService
+}
+
+func NewCampaignReportService(ctx context.Context, db *gorm.DB) *CampaignReportService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+
+	maxDistance := config.ReportMaxDistance
+	if maxDistance <= 0 {
+		maxDistance = DefaultReportMaxDistance
+	}
+
+	return &CampaignReportService{
+		DB:           db,
+		Log:          logger,
+		MaxDistance:  maxDistance,
+		Notification: NewNotificationService(ctx, db),
+		Stream:       NewStreamService(ctx),
+	}
+}
+
+// Submit stores the proof of distribution of a campaign of the detonator's.
+// A rejected report is replaced by the new submission.
+func (service CampaignReportService) Submit(userId int, input dto.CampaignReportRequest) (*entities.CampaignReport, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	var campaign entities.Campaign
+	if err := tx.
+		Preload("Detonator").
+		First(&campaign, "id", input.CampaignID).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if campaign.Detonator == nil || campaign.Detonator.UserId != userId {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	if campaign.Status != enums.CampaignStatusExecuting && campaign.Status != enums.CampaignStatusCompleted {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrReportNotAllowed.Error(),
+		}
+	}
+
+	for _, photo := range input.Photos {
+		if !service.uploaded(photo) {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrReportPhoto.Error(),
+			}
+		}
+	}
+
+	report := entities.CampaignReport{
+		CampaignID:    campaign.ID,
+		DetonatorID:   campaign.DetonatorID,
+		Beneficiaries: input.Beneficiaries,
+		Latitude:      *input.Latitude,
+		Longitude:     *input.Longitude,
+		Notes:         input.Notes,
+		Status:        enums.CampaignReportSubmitted,
+	}
+
+	// campaigns with unreadable coordinates are still reported, the admin
+	// sees the location was not checked
+	if latitude, longitude, err := common.ParseCoordinates(campaign.Latitude, campaign.Longitude); err == nil {
+		report.DistanceMeters = common.Distance(latitude, longitude, *input.Latitude, *input.Longitude)
+		report.LocationChecked = true
+
+		if report.DistanceMeters > service.MaxDistance {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrReportDistance.Error(),
+			}
+		}
+	}
+
+	var previous entities.CampaignReport
+	err := tx.Where("campaign_id = ?", campaign.ID).First(&previous).Error
+	switch {
+	case err == nil:
+		if previous.Status != enums.CampaignReportRejected {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrReportExists.Error(),
+			}
+		}
+
+		if err := tx.Where("campaign_report_id = ?", previous.ID).Delete(&entities.CampaignReportPhoto{}).Error; err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		if err := tx.Delete(&previous).Error; err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	for _, photo := range input.Photos {
+		report.Photos = append(report.Photos, entities.CampaignReportPhoto{ImageURL: photo})
+	}
+
+	if err := tx.Create(&report).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := service.Notification.PushRole(tx, events, "superadmin", dto.NotificationEvent{
+		Type:    enums.NotificationReportSubmitted,
+		RefType: enums.NotificationRefReport,
+		RefID:   report.ID,
+		Params: map[string]any{
+			"EventName":       campaign.EventName,
+			"LocationChecked": report.LocationChecked,
+		},
+	}); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	events.Publish()
+
+	return &report, nil
+}
+
+// uploaded tells whether path is a file uploaded through the media endpoint
+// to the report destination.
+func (service CampaignReportService) uploaded(path string) bool {
+	clean := filepath.ToSlash(filepath.Clean(path))
+	if clean != path || !strings.HasPrefix(clean, reportMediaDestination+"/") {
+		return false
+	}
+
+	info, err := os.Stat(filepath.Join(StorageRoot, clean))
+
+	return err == nil && !info.IsDir()
+}
+
+func (service CampaignReportService) GetAll(c *fiber.Ctx, pagination *common.Pagination) ([]entities.CampaignReport, *dto.ApiError) {
+	var reports []entities.CampaignReport
+
+	query := service.DB.
+		Preload("Photos").
+		Order("created_at desc")
+
+	if campaignId := c.Query("campaign_id"); campaignId != "" {
+		query = query.Where("campaign_id = ?", campaignId)
+	}
+
+	if detonatorId := c.Query("detonator_id"); detonatorId != "" {
+		query = query.Where("detonator_id = ?", detonatorId)
+	}
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query = query.Find(&reports)
+
+	if err := query.Scopes(common.Paginate(query, entities.CampaignReport{}, pagination)).Find(&reports); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return reports, nil
+}
+
+// GetByID returns a report. When ownerId is set only verified reports, which are
+// public, and the reports of that user's detonator are returned.
+func (service CampaignReportService) GetByID(ownerId int, id string) (*entities.CampaignReport, *dto.ApiError) {
+	var report entities.CampaignReport
+
+	query := service.DB
+	if ownerId != 0 {
+		query = query.Where("status = ? OR detonator_id IN (?)", enums.CampaignReportVerified, service.DB.
+			Model(&entities.Detonator{}).
+			Select("id").
+			Where("user_id = ?", ownerId))
+	}
+
+	if err := query.
+		Preload("Photos").
+		Where("id", id).
+		First(&report); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return &report, nil
+}
+
+// Review verifies or rejects a submitted report. Verified reports are shown
+// on the public campaign detail.
+func (service CampaignReportService) Review(userId int, id string, input dto.CampaignReportReview) (*entities.CampaignReport, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	var report entities.CampaignReport
+	if err := tx.First(&report, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if report.Status != enums.CampaignReportSubmitted {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrReportReviewed.Error(),
+		}
+	}
+
+	now := time.Now()
+	result := tx.Model(&entities.CampaignReport{}).
+		Where("id = ? AND status = ?", report.ID, enums.CampaignReportSubmitted).
+		Updates(map[string]any{
+			"status":      input.Status,
+			"review_note": input.Note,
+			"reviewed_by": userId,
+			"reviewed_at": now,
+		})
+	if result.Error != nil {
+		service.Log.Error().Msg(result.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    result.Error.Error(),
+		}
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrReportReviewed.Error(),
+		}
+	}
+
+	var campaign entities.Campaign
+	if err := tx.
+		Preload("Detonator").
+		Preload("Detonator.Oauth").
+		First(&campaign, "id", report.CampaignID).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if campaign.Detonator != nil && campaign.Detonator.Oauth != nil {
+		event := dto.NotificationEvent{
+			Type:    enums.NotificationReportVerified,
+			RefType: enums.NotificationRefReport,
+			RefID:   report.ID,
+			Params: map[string]any{
+				"EventName": campaign.EventName,
+				"Note":      input.Note,
+			},
+		}
+
+		if input.Status == enums.CampaignReportRejected {
+			event.Type = enums.NotificationReportRejected
+		}
+
+		if err := service.Notification.Push(tx, events, campaign.Detonator.Oauth, event); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	events.Publish()
+
+	return service.GetByID(0, id)
+}
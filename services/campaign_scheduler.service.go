@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"foodia-be/configs"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultCampaignInterval      = time.Minute
+	DefaultCampaignFundingCutoff = 24 * time.Hour
+	DefaultCampaignExecution     = 12 * time.Hour
+)
+
+// CampaignScheduler moves campaigns along their lifecycle when targets are hit
+// or deadlines pass:
+//
+//   - fundraising campaigns that collected their target become funded
+//   - fundraising campaigns still short FundingCutoff before the event are cancelled
+//   - funded campaigns start executing at the event time
+//   - executing campaigns complete ExecutionWindow after the event time
+//   - draft and review campaigns whose event time passed are cancelled
+type CampaignScheduler struct {
+	DB              *gorm.DB
+	Log             *zerolog.Logger
+	Interval        time.Duration
+	FundingCutoff   time.Duration
+	ExecutionWindow time.Duration
+	Campaign        *CampaignService
+}
+
+func NewCampaignScheduler(ctx context.Context, db *gorm.DB) *CampaignScheduler {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+
+	scheduler := &CampaignScheduler{
+		DB:              db,
+		Log:             logger,
+		Interval:        config.CampaignInterval,
+		FundingCutoff:   config.CampaignFundingCutoff,
+		ExecutionWindow: config.CampaignExecution,
+		Campaign:        NewCampaignService(ctx, db),
+	}
+
+	if scheduler.Interval <= 0 {
+		scheduler.Interval = DefaultCampaignInterval
+	}
+
+	if scheduler.FundingCutoff <= 0 {
+		scheduler.FundingCutoff = DefaultCampaignFundingCutoff
+	}
+
+	if scheduler.ExecutionWindow <= 0 {
+		scheduler.ExecutionWindow = DefaultCampaignExecution
+	}
+
+	return scheduler
+}
+
+// Run checks the campaigns until ctx is cancelled.
+func (scheduler *CampaignScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.Interval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.tick(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (scheduler *CampaignScheduler) tick(now time.Time) {
+	// funding is checked before the cutoff so a campaign funded at the last
+	// minute is not cancelled
+	scheduler.advance(
+		scheduler.DB.Where("status = ? AND collected >= donation_target", enums.CampaignStatusFundraising),
+		enums.CampaignStatusFunded, "donation target reached",
+	)
+
+	scheduler.advance(
+		scheduler.DB.Where("status = ? AND event_at <= ?", enums.CampaignStatusFundraising, now.Add(scheduler.FundingCutoff)),
+		enums.CampaignStatusCancelled, "donation target not reached before the funding deadline",
+	)
+
+	scheduler.advance(
+		scheduler.DB.Where("status = ? AND event_at <= ?", enums.CampaignStatusFunded, now),
+		enums.CampaignStatusExecuting, "",
+	)
+
+	scheduler.advance(
+		scheduler.DB.Where("status = ? AND event_at <= ?", enums.CampaignStatusExecuting, now.Add(-scheduler.ExecutionWindow)),
+		enums.CampaignStatusCompleted, "",
+	)
+
+	scheduler.advance(
+		scheduler.DB.Where("status IN ? AND event_at <= ?", []string{enums.CampaignStatusDraft, enums.CampaignStatusReview}, now),
+		enums.CampaignStatusCancelled, "event time passed before the campaign was approved",
+	)
+}
+
+func (scheduler *CampaignScheduler) advance(query *gorm.DB, to, note string) {
+	var ids []int
+	if err := query.Model(&entities.Campaign{}).Pluck("id", &ids).Error; err != nil {
+		scheduler.Log.Error().Msg(err.Error())
+		return
+	}
+
+	for _, id := range ids {
+		if _, fail := scheduler.Campaign.Advance(strconv.Itoa(id), 0, to, note); fail != nil {
+			scheduler.Log.Error().Msg(fmt.Sprintf("campaign %d could not move to %s: %s", id, to, fail.Message))
+		}
+	}
+}
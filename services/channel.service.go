@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"gorm.io/gorm"
+)
+
+// ChannelService routes notifications to the channel each user prefers.
+type ChannelService struct {
+	Outbox *OutboxService
+}
+
+func NewChannelService(ctx context.Context, db *gorm.DB) *ChannelService {
+	return &ChannelService{
+		Outbox: NewOutboxService(ctx, db),
+	}
+}
+
+// RecipientOf returns the notification recipient for an account.
+func RecipientOf(oauth *entities.Oauth) dto.Recipient {
+	return dto.Recipient{
+		Email:   oauth.Email,
+		Phone:   oauth.Phone,
+		Locale:  oauth.Locale,
+		Channel: oauth.Channel,
+	}
+}
+
+// Notify queues data on the recipient's preferred channel within tx. SMS and
+// WhatsApp are only used for templates that have a short message version and
+// carry the email address so the dispatcher can fall back to email.
+func (service ChannelService) Notify(tx *gorm.DB, to dto.Recipient, data dto.MailData) error {
+	tmpl, ok := FindMailTemplate(data.MailTemplate())
+
+	switch to.Channel {
+	case enums.OutboxChannelSMS, enums.OutboxChannelWhatsApp:
+		if ok && tmpl.ShortMessage && to.Phone != "" {
+			payload := dto.ShortMessagePayload{
+				FallbackEmail: to.Email,
+				Data:          data,
+			}
+
+			return service.Outbox.Enqueue(tx, to.Channel, data.MailTemplate(), to.Phone, to.Locale, payload)
+		}
+	}
+
+	return service.Outbox.EnqueueMail(tx, to.Email, to.Locale, data)
+}
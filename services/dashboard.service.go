@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const DefaultTopMerchants = 10
+
+// donationBuckets are the SQL expressions giving the first day of the bucket
+// a donation falls in, e.g. the monday of its week.
+var donationBuckets = map[string]string{
+	enums.DashboardBucketDay:   "DATE_FORMAT(paid_at, '%Y-%m-%d')",
+	enums.DashboardBucketWeek:  "DATE_FORMAT(DATE_SUB(DATE(paid_at), INTERVAL WEEKDAY(paid_at) DAY), '%Y-%m-%d')",
+	enums.DashboardBucketMonth: "DATE_FORMAT(paid_at, '%Y-%m-01')",
+}
+
+// DashboardService sums up the platform for the superadmins. Everything is
+// computed by the database with aggregate queries.
+type DashboardService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewDashboardService(ctx context.Context, db *gorm.DB) *DashboardService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &DashboardService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// Registrations counts merchants and detonators per status.
+func (service DashboardService) Registrations() (*dto.RegistrationStats, *dto.ApiError) {
+	stats := dto.RegistrationStats{
+		Merchants:  []dto.StatusCount{},
+		Detonators: []dto.StatusCount{},
+	}
+
+	if err := service.DB.Model(&entities.Merchant{}).
+		Select("status, COUNT(*) AS total").
+		Group("status").
+		Order("status").
+		Scan(&stats.Merchants).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := service.DB.Model(&entities.Detonator{}).
+		Select("status, COUNT(*) AS total").
+		Group("status").
+		Order("status").
+		Scan(&stats.Detonators).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &stats, nil
+}
+
+// Campaigns counts campaigns per status and per province, optionally only
+// the ones with the given ?status=.
+func (service DashboardService) Campaigns(c *fiber.Ctx) (*dto.CampaignStats, *dto.ApiError) {
+	stats := dto.CampaignStats{
+		ByStatus:   []dto.StatusCount{},
+		ByProvince: []dto.ProvinceCount{},
+	}
+
+	if err := service.DB.Model(&entities.Campaign{}).
+		Select("status, COUNT(*) AS total").
+		Group("status").
+		Order("status").
+		Scan(&stats.ByStatus).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	query := service.DB.Model(&entities.Campaign{}).
+		Select("province, COUNT(*) AS total").
+		Group("province").
+		Order("total desc")
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Scan(&stats.ByProvince).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &stats, nil
+}
+
+// Orders sums orders per status, for those placed between ?from= and ?to=
+// when given.
+func (service DashboardService) Orders(c *fiber.Ctx) ([]dto.OrderVolume, *dto.ApiError) {
+	volumes := []dto.OrderVolume{}
+
+	query := service.DB.Model(&entities.Order{}).
+		Select("order_status AS status, COUNT(*) AS orders, COALESCE(SUM(qty), 0) AS portions, COALESCE(SUM(price * qty), 0) AS amount").
+		Group("order_status").
+		Order("order_status")
+
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, to, err := dateRange(c, time.Time{})
+		if err != nil {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    err.Error(),
+			}
+		}
+
+		query = query.Where("created_at >= ? AND created_at < ?", from, to)
+	}
+
+	if err := query.Scan(&volumes).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return volumes, nil
+}
+
+// Donations sums paid donations per ?bucket= of day (the default), week or
+// month between ?from= and ?to=. Without a range it covers the last 30 days,
+// 12 weeks or 12 months.
+func (service DashboardService) Donations(c *fiber.Ctx) ([]dto.DonationBucket, *dto.ApiError) {
+	bucket := c.Query("bucket", enums.DashboardBucketDay)
+
+	period, ok := donationBuckets[bucket]
+	if !ok {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBadParamInput.Error(),
+		}
+	}
+
+	location, _ := common.Timezone(enums.TimezoneWIB)
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	since := today.AddDate(0, 0, -29)
+	switch bucket {
+	case enums.DashboardBucketWeek:
+		since = today.AddDate(0, 0, -7*12+1)
+	case enums.DashboardBucketMonth:
+		since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location).AddDate(0, -11, 0)
+	}
+
+	from, to, err := dateRange(c, since)
+	if err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    err.Error(),
+		}
+	}
+
+	buckets := []dto.DonationBucket{}
+	if err := service.DB.Model(&entities.Donation{}).
+		Select(period+" AS period, COUNT(*) AS donations, COALESCE(SUM(amount), 0) AS amount").
+		Where("status = ? AND paid_at >= ? AND paid_at < ?", enums.DonationStatusPaid, from, to).
+		Group("period").
+		Order("period").
+		Scan(&buckets).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return buckets, nil
+}
+
+// TopMerchants ranks merchants by the number of orders they got that were not
+// rejected, up to ?limit= of them.
+func (service DashboardService) TopMerchants(c *fiber.Ctx) ([]dto.TopMerchant, *dto.ApiError) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		limit = DefaultTopMerchants
+	}
+
+	merchants := []dto.TopMerchant{}
+	if err := service.DB.Table("orders").
+		Select("merchants.id AS merchant_id, oauths.fullname, COUNT(orders.id) AS orders, COALESCE(SUM(orders.qty), 0) AS portions, COALESCE(SUM(orders.price * orders.qty), 0) AS amount").
+		Joins("JOIN merchant_products ON merchant_products.id = orders.merchant_product_id").
+		Joins("JOIN merchants ON merchants.id = merchant_products.merchant_id").
+		Joins("LEFT JOIN oauths ON oauths.id = merchants.user_id").
+		Where("orders.order_status <> ? AND orders.deleted_at IS NULL", enums.OrderStatusRejected).
+		Group("merchants.id, oauths.fullname").
+		Order("orders desc, amount desc").
+		Limit(limit).
+		Scan(&merchants).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return merchants, nil
+}
+
+// dateRange reads the ?from= and ?to= dates in WIB, both included. from
+// defaults to since and to to today.
+func dateRange(c *fiber.Ctx, since time.Time) (time.Time, time.Time, error) {
+	location, _ := common.Timezone(enums.TimezoneWIB)
+	now := time.Now().In(location)
+
+	from := since
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location).AddDate(0, 0, 1)
+
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			return from, to, err
+		}
+		from = parsed
+	}
+
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			return from, to, err
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	return from, to, nil
+}
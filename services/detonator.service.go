@@ -2,9 +2,7 @@ package services
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"path/filepath"
+	"time"
 
 	"foodia-be/common"
 	"foodia-be/dto"
@@ -18,28 +16,41 @@ import (
 )
 
 type DetonatorService struct {
-	DB          *gorm.DB
-	Log         *zerolog.Logger
-	AuthService *AuthService
+	DB           *gorm.DB
+	Log          *zerolog.Logger
+	AuthService  *AuthService
+	Storage      *StorageService
+	Channel      *ChannelService
+	Notification *NotificationService
+	Stream       *StreamService
 }
 
 func NewDetonatorService(ctx context.Context, db *gorm.DB) *DetonatorService {
 	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
 
 	return &DetonatorService{
-		DB:          db,
-		Log:         logger,
-		AuthService: NewAuthService(ctx, db),
+		DB:           db,
+		Log:          logger,
+		AuthService:  NewAuthService(ctx, db),
+		Storage:      NewStorageService(logger),
+		Channel:      NewChannelService(ctx, db),
+		Notification: NewNotificationService(ctx, db),
+		Stream:       NewStreamService(ctx),
 	}
 }
 
-func (service DetonatorService) DetonatorRegistration(c *fiber.Ctx, input dto.DetonatorRegistration) (*entities.Detonator, *dto.ApiError) {
+func (service DetonatorService) DetonatorRegistration(input dto.DetonatorRegistration, locale string) (*entities.Detonator, *dto.ApiError) {
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
+	files := service.Storage.Batch()
+	defer files.Rollback()
+
+	events := service.Stream.Batch()
+
 	// store self photo file to the storage
-	selfPhoto := "detonator/" + common.GenerateUUID() + filepath.Ext(input.SelfPhoto.Filename)
-	if err := c.SaveFile(input.SelfPhoto, fmt.Sprintf("./storage/%s", selfPhoto)); err != nil {
+	selfPhoto, err := files.Store("detonator", input.SelfPhoto)
+	if err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -48,8 +59,8 @@ func (service DetonatorService) DetonatorRegistration(c *fiber.Ctx, input dto.De
 	}
 
 	// store ktp photo file to the storage
-	ktpPhoto := "detonator/" + common.GenerateUUID() + filepath.Ext(input.SelfPhoto.Filename)
-	if err := c.SaveFile(input.SelfPhoto, fmt.Sprintf("./storage/%s", ktpPhoto)); err != nil {
+	ktpPhoto, err := files.Store("detonator", input.KTPPhoto)
+	if err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -74,6 +85,8 @@ func (service DetonatorService) DetonatorRegistration(c *fiber.Ctx, input dto.De
 		UserId:   common.GenerateUUID(),
 		Password: string(password),
 		Role:     "detonator",
+		Locale:   locale,
+		Channel:  input.Channel,
 	}
 
 	if err := tx.Create(&ouath).Error; err != nil {
@@ -103,10 +116,14 @@ func (service DetonatorService) DetonatorRegistration(c *fiber.Ctx, input dto.De
 
 	// send OTP
 	OTP := dto.OTPRequest{
-		Email: ouath.Email,
+		Email:     ouath.Email,
+		Phone:     ouath.Phone,
+		Channel:   ouath.Channel,
+		Locale:    ouath.Locale,
+		ExpiredAt: time.Now().Add(RegistrationOTPTTL),
 	}
 
-	if err := service.AuthService.SendOTP(OTP); err != nil {
+	if err := service.AuthService.SendOTP(tx, OTP); err != nil {
 		service.Log.Error().Msg(err.StatusCode.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -114,6 +131,37 @@ func (service DetonatorService) DetonatorRegistration(c *fiber.Ctx, input dto.De
 		}
 	}
 
+	received := dto.RegistrationReceivedMail{
+		Fullname: ouath.Fullname,
+		Role:     ouath.Role,
+	}
+
+	if err := service.Channel.Notify(tx, RecipientOf(&ouath), received); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	submitted := dto.NotificationEvent{
+		Type:    enums.NotificationRegistrationSubmitted,
+		RefType: enums.NotificationRefDetonator,
+		RefID:   detonator.ID,
+		Params: map[string]any{
+			"Fullname": ouath.Fullname,
+			"Role":     ouath.Role,
+		},
+	}
+
+	if err := service.Notification.PushRole(tx, events, "superadmin", submitted); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
@@ -122,6 +170,9 @@ func (service DetonatorService) DetonatorRegistration(c *fiber.Ctx, input dto.De
 		}
 	}
 
+	files.Commit()
+	events.Publish()
+
 	return &detonator, nil
 }
 
@@ -165,8 +216,10 @@ func (service DetonatorService) DetonatorApproval(id string, input dto.Detonator
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
+	events := service.Stream.Batch()
+
 	var detonator entities.Detonator
-	if err := tx.First(&detonator, "id", id).Error; err != nil {
+	if err := tx.Preload("Oauth").First(&detonator, "id", id).Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
@@ -187,6 +240,14 @@ func (service DetonatorService) DetonatorApproval(id string, input dto.Detonator
 		}
 	}
 
+	if err := service.notifyReview(tx, events, &detonator, input.Status, input.Note); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
@@ -195,37 +256,44 @@ func (service DetonatorService) DetonatorApproval(id string, input dto.Detonator
 		}
 	}
 
+	events.Publish()
+
 	return &update, nil
 }
 
-func (service DetonatorService) DetonatorUpdate(c *fiber.Ctx, id string, input dto.DetonatorUpdate) (*entities.Detonator, *dto.ApiError) {
+func (service DetonatorService) DetonatorUpdate(id string, input dto.DetonatorUpdate) (*entities.Detonator, *dto.ApiError) {
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
+	files := service.Storage.Batch()
+	defer files.Rollback()
+
 	var selfPhoto string
 	// store self photo file to the storage
 	if input.SelfPhoto != nil {
-		selfPhoto = "detonator/" + common.GenerateUUID() + filepath.Ext(input.SelfPhoto.Filename)
-		if err := c.SaveFile(input.SelfPhoto, fmt.Sprintf("./storage/%s", selfPhoto)); err != nil {
+		path, err := files.Store("detonator", input.SelfPhoto)
+		if err != nil {
 			service.Log.Error().Msg(err.Error())
 			return nil, &dto.ApiError{
 				StatusCode: fiber.ErrInternalServerError,
 				Message:    err.Error(),
 			}
 		}
+		selfPhoto = path
 	}
 
 	var ktpPhoto string
 	// store ktp photo file to the storage
 	if input.KTPPhoto != nil {
-		ktpPhoto = "detonator/" + common.GenerateUUID() + filepath.Ext(input.KTPPhoto.Filename)
-		if err := c.SaveFile(input.SelfPhoto, fmt.Sprintf("./storage/%s", ktpPhoto)); err != nil {
+		path, err := files.Store("detonator", input.KTPPhoto)
+		if err != nil {
 			service.Log.Error().Msg(err.Error())
 			return nil, &dto.ApiError{
 				StatusCode: fiber.ErrInternalServerError,
 				Message:    err.Error(),
 			}
 		}
+		ktpPhoto = path
 	}
 
 	var detonator entities.Detonator
@@ -233,10 +301,12 @@ func (service DetonatorService) DetonatorUpdate(c *fiber.Ctx, id string, input d
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
-			Message:    errors.New("detonator not found").Error(),
+			Message:    enums.ErrNotFound.Error(),
 		}
 	}
 
+	previousSelfPhoto, previousKTPPhoto := detonator.SelfPhoto, detonator.KTPPhoto
+
 	detonatorUpdate := entities.Detonator{
 		KTPNumber: input.KTPNumber,
 		KTPPhoto:  ktpPhoto,
@@ -256,7 +326,7 @@ func (service DetonatorService) DetonatorUpdate(c *fiber.Ctx, id string, input d
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
-			Message:    errors.New("ouath not found").Error(),
+			Message:    enums.ErrNotFound.Error(),
 		}
 	}
 
@@ -282,7 +352,230 @@ func (service DetonatorService) DetonatorUpdate(c *fiber.Ctx, id string, input d
 		}
 	}
 
+	files.Commit()
+
+	// drop the replaced photos once nothing references their content anymore
+	if selfPhoto != "" && selfPhoto != previousSelfPhoto {
+		service.Storage.Release(previousSelfPhoto, service.photoInUse)
+	}
+	if ktpPhoto != "" && ktpPhoto != previousKTPPhoto {
+		service.Storage.Release(previousKTPPhoto, service.photoInUse)
+	}
+
 	detonator.Oauth = &ouath
 
 	return &detonator, nil
 }
+
+// photoInUse reports whether any detonator still references the stored photo.
+func (service DetonatorService) photoInUse(path string) bool {
+	var count int64
+	if err := service.DB.Model(&entities.Detonator{}).
+		Where("self_photo = ? OR ktp_photo = ?", path, path).
+		Count(&count).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		// keep the file when in doubt
+		return true
+	}
+
+	return count > 0
+}
+
+// notifyReview tells the detonator about the outcome of the registration review,
+// both in the app and on their preferred channel.
+func (service DetonatorService) notifyReview(tx *gorm.DB, events *EventBatch, detonator *entities.Detonator, status, note string) error {
+	oauth := detonator.Oauth
+	if oauth == nil {
+		return nil
+	}
+
+	event := dto.NotificationEvent{
+		RefType: enums.NotificationRefDetonator,
+		RefID:   detonator.ID,
+		Params: map[string]any{
+			"Role": oauth.Role,
+			"Note": note,
+		},
+	}
+
+	var mail dto.MailData
+	switch status {
+	case "approved":
+		event.Type = enums.NotificationRegistrationApproved
+		mail = dto.RegistrationApprovedMail{
+			Fullname: oauth.Fullname,
+			Role:     oauth.Role,
+			Note:     note,
+		}
+	case "rejected":
+		event.Type = enums.NotificationRegistrationRejected
+		mail = dto.RegistrationRejectedMail{
+			Fullname: oauth.Fullname,
+			Role:     oauth.Role,
+			Note:     note,
+		}
+	default:
+		return nil
+	}
+
+	if err := service.Notification.Push(tx, events, oauth, event); err != nil {
+		return err
+	}
+
+	return service.Channel.Notify(tx, RecipientOf(oauth), mail)
+}
+
+// Delete soft deletes a detonator along with their draft campaigns. Detonators
+// with campaigns under review or running are kept until those are done.
+func (service DetonatorService) Delete(id string) *dto.ApiError {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	var detonator entities.Detonator
+	if err := tx.First(&detonator, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	var running int64
+	if err := tx.Model(&entities.Campaign{}).
+		Where("detonator_id = ? AND status IN ?", detonator.ID, []string{
+			enums.CampaignStatusReview,
+			enums.CampaignStatusFundraising,
+			enums.CampaignStatusFunded,
+			enums.CampaignStatusExecuting,
+		}).
+		Count(&running).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if running > 0 {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDetonatorInUse.Error(),
+		}
+	}
+
+	var drafts []int
+	if err := tx.Model(&entities.Campaign{}).
+		Where("detonator_id = ? AND status = ?", detonator.ID, enums.CampaignStatusDraft).
+		Pluck("id", &drafts).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	// the drafts are marked with the time of the detonator so they are
+	// restored with it
+	now := time.Now().Truncate(time.Second)
+
+	if err := deleteCampaigns(tx, drafts, now); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Model(&detonator).Update("deleted_at", now).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// Restore brings back a deleted detonator with the draft campaigns deleted
+// along with them.
+func (service DetonatorService) Restore(id string) (*entities.Detonator, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	var detonator entities.Detonator
+	if err := tx.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&detonator).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	var drafts []int
+	if err := tx.Unscoped().Model(&entities.Campaign{}).
+		Where("detonator_id = ? AND deleted_at = ?", detonator.ID, detonator.DeletedAt.Time).
+		Pluck("id", &drafts).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := restoreCampaigns(tx, drafts, detonator.DeletedAt.Time); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Unscoped().Model(&detonator).Update("deleted_at", nil).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetByID(id)
+}
+
+func (service DetonatorService) GetDeleted(pagination *common.Pagination) ([]entities.Detonator, *dto.ApiError) {
+	var detonators []entities.Detonator
+
+	query := service.DB.Unscoped().
+		Preload("Oauth").
+		Order("deleted_at desc").
+		Where("deleted_at IS NOT NULL").
+		Find(&detonators)
+
+	if err := query.Scopes(common.Paginate(query, entities.Detonator{}, pagination)).Find(&detonators); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return detonators, nil
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// documentLabels are the texts printed on receipts and certificates per locale.
+var documentLabels = map[string]map[string]string{
+	enums.LocaleEN: {
+		"receipt":       "DONATION RECEIPT",
+		"certificate":   "CAMPAIGN DISTRIBUTION CERTIFICATE",
+		"number":        "Number",
+		"donor":         "Donor",
+		"campaign":      "Campaign",
+		"amount":        "Amount",
+		"date":          "Date",
+		"status":        "Status",
+		"paid":          "PAID",
+		"detonator":     "Detonator",
+		"event_date":    "Event date",
+		"location":      "Location",
+		"target":        "Donation target",
+		"collected":     "Collected",
+		"donors":        "Donors",
+		"distributed":   "Distributed to merchants",
+		"orders":        "Delivered orders",
+		"portions":      "Portions delivered",
+		"beneficiaries": "Beneficiaries reported",
+		"not_reported":  "not reported",
+		"code":          "Verification code",
+		"verify":        "Check this document with its code at",
+		"issued":        "Issued",
+		"thanks":        "Thank you for sharing food through Foodia.",
+	},
+	enums.LocaleID: {
+		"receipt":       "KUITANSI DONASI",
+		"certificate":   "SERTIFIKAT PENYALURAN CAMPAIGN",
+		"number":        "Nomor",
+		"donor":         "Donatur",
+		"campaign":      "Campaign",
+		"amount":        "Jumlah",
+		"date":          "Tanggal",
+		"status":        "Status",
+		"paid":          "LUNAS",
+		"detonator":     "Detonator",
+		"event_date":    "Tanggal acara",
+		"location":      "Lokasi",
+		"target":        "Target donasi",
+		"collected":     "Terkumpul",
+		"donors":        "Donatur",
+		"distributed":   "Disalurkan ke merchant",
+		"orders":        "Pesanan terkirim",
+		"portions":      "Porsi terkirim",
+		"beneficiaries": "Penerima manfaat dilaporkan",
+		"not_reported":  "belum dilaporkan",
+		"code":          "Kode verifikasi",
+		"verify":        "Periksa keaslian dokumen ini dengan kodenya di",
+		"issued":        "Diterbitkan",
+		"thanks":        "Terima kasih telah berbagi makanan melalui Foodia.",
+	},
+}
+
+// DefaultDocumentVerifyURL is where the public can check a verification code
+// when no full URL is configured.
+const DefaultDocumentVerifyURL = "/api/v1/document/verify/"
+
+type DocumentService struct {
+	DB        *gorm.DB
+	Log       *zerolog.Logger
+	VerifyURL string
+}
+
+func NewDocumentService(ctx context.Context, db *gorm.DB) *DocumentService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+
+	verifyURL := config.DocumentVerifyURL
+	if verifyURL == "" {
+		verifyURL = DefaultDocumentVerifyURL
+	}
+
+	return &DocumentService{
+		DB:        db,
+		Log:       logger,
+		VerifyURL: verifyURL,
+	}
+}
+
+// issue returns the document of the given type for ref, recording it with a
+// new verification code the first time.
+func (service DocumentService) issue(document entities.Document) (*entities.Document, error) {
+	secret, err := common.GenerateSecret(5)
+	if err != nil {
+		return nil, err
+	}
+
+	secret = strings.ToUpper(secret)
+	document.Code = secret[:5] + "-" + secret[5:]
+	document.IssuedAt = time.Now()
+
+	if err := service.DB.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&document).Error; err != nil {
+		return nil, err
+	}
+
+	var issued entities.Document
+	if err := service.DB.First(&issued, "type = ? AND ref_id = ?", document.Type, document.RefID).Error; err != nil {
+		return nil, err
+	}
+
+	return &issued, nil
+}
+
+// Receipt renders the PDF receipt of a paid donation with its campaign loaded.
+func (service DocumentService) Receipt(donation *entities.Donation, locale string) ([]byte, *dto.ApiError) {
+	receipt := ReceiptMail(donation)
+
+	document, err := service.issue(entities.Document{
+		Type:    enums.DocumentReceipt,
+		RefID:   donation.ID,
+		Number:  receipt.ReceiptNumber,
+		Holder:  receipt.DonorName,
+		Subject: receipt.EventName,
+		Amount:  receipt.Amount,
+	})
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	labels := labelsOf(locale)
+	pdf, y := service.header(labels["receipt"])
+
+	y = documentRow(pdf, y, labels["number"], receipt.ReceiptNumber)
+	y = documentRow(pdf, y, labels["donor"], receipt.DonorName)
+	y = documentRow(pdf, y, labels["campaign"], receipt.EventName)
+	y = documentRow(pdf, y, labels["amount"], common.FormatRupiah(receipt.Amount))
+	y = documentRow(pdf, y, labels["date"], documentTime(receipt.DonatedAt))
+	y = documentRow(pdf, y, labels["status"], labels["paid"])
+
+	service.footer(pdf, y, labels, document)
+
+	return pdf.Bytes(), nil
+}
+
+// Certificate renders the PDF summary certificate of a completed campaign.
+func (service DocumentService) Certificate(campaignId string, locale string) ([]byte, *dto.ApiError) {
+	var campaign entities.Campaign
+	if err := service.DB.
+		Preload("Detonator.Oauth").
+		Preload("Report", "status = ?", enums.CampaignReportVerified).
+		First(&campaign, "id", campaignId).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if campaign.Status != enums.CampaignStatusCompleted {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrCertificateNotReady.Error(),
+		}
+	}
+
+	var donors int64
+	if err := service.DB.Model(&entities.Donation{}).
+		Where("campaign_id = ? AND status = ?", campaign.ID, enums.DonationStatusPaid).
+		Count(&donors).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	var delivered struct {
+		Orders   int64
+		Portions int64
+		Total    decimal.Decimal
+	}
+
+	if err := service.DB.Model(&entities.Order{}).
+		Select("COUNT(*) AS orders, COALESCE(SUM(qty), 0) AS portions, COALESCE(SUM(price * qty), 0) AS total").
+		Where("campaign_id = ? AND order_status = ?", campaign.ID, enums.OrderStatusDelivered).
+		Scan(&delivered).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	detonator := ""
+	if campaign.Detonator != nil && campaign.Detonator.Oauth != nil {
+		detonator = campaign.Detonator.Oauth.Fullname
+	}
+
+	document, err := service.issue(entities.Document{
+		Type:    enums.DocumentCertificate,
+		RefID:   campaign.ID,
+		Number:  fmt.Sprintf("FC-%06d", campaign.ID),
+		Holder:  detonator,
+		Subject: campaign.EventName,
+		Amount:  campaign.Collected,
+	})
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	labels := labelsOf(locale)
+	pdf, y := service.header(labels["certificate"])
+
+	beneficiaries := labels["not_reported"]
+	if campaign.Report != nil {
+		beneficiaries = strconv.Itoa(campaign.Report.Beneficiaries)
+	}
+
+	y = documentRow(pdf, y, labels["number"], document.Number)
+	y = documentRow(pdf, y, labels["campaign"], campaign.EventName)
+	y = documentRow(pdf, y, labels["detonator"], detonator)
+	y = documentRow(pdf, y, labels["event_date"], fmt.Sprintf("%s %s %s", campaign.EventDate, campaign.EventTime, campaign.Timezone))
+	y = documentRow(pdf, y, labels["location"], fmt.Sprintf("%s, %s, %s", campaign.SubDistrict, campaign.City, campaign.Province))
+	y = documentRow(pdf, y, labels["target"], common.FormatRupiah(campaign.DonationTarget))
+	y = documentRow(pdf, y, labels["collected"], common.FormatRupiah(campaign.Collected))
+	y = documentRow(pdf, y, labels["donors"], strconv.FormatInt(donors, 10))
+	y = documentRow(pdf, y, labels["distributed"], common.FormatRupiah(delivered.Total))
+	y = documentRow(pdf, y, labels["orders"], strconv.FormatInt(delivered.Orders, 10))
+	y = documentRow(pdf, y, labels["portions"], strconv.FormatInt(delivered.Portions, 10))
+	y = documentRow(pdf, y, labels["beneficiaries"], beneficiaries)
+
+	service.footer(pdf, y, labels, document)
+
+	return pdf.Bytes(), nil
+}
+
+// Verify looks up the document a verification code was issued for.
+func (service DocumentService) Verify(code string) (*dto.DocumentVerification, *dto.ApiError) {
+	var document entities.Document
+	if err := service.DB.First(&document, "code = ?", strings.ToUpper(strings.TrimSpace(code))).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &dto.DocumentVerification{
+		Code:     document.Code,
+		Type:     document.Type,
+		Number:   document.Number,
+		Holder:   document.Holder,
+		Subject:  document.Subject,
+		Amount:   document.Amount,
+		IssuedAt: document.IssuedAt,
+	}, nil
+}
+
+// header starts a document with its title and returns where the body begins.
+func (service DocumentService) header(title string) (*common.PDF, float64) {
+	pdf := common.NewPDF()
+
+	pdf.Text(60, 80, 22, true, "Foodia")
+	pdf.Text(60, 110, 16, true, title)
+	pdf.Line(60, 125, common.PDFPageWidth-60, 125)
+
+	return pdf, 160
+}
+
+// footer closes a document with its verification code.
+func (service DocumentService) footer(pdf *common.PDF, y float64, labels map[string]string, document *entities.Document) {
+	y += 20
+	pdf.Line(60, y, common.PDFPageWidth-60, y)
+
+	y += 30
+	documentRow(pdf, y, labels["code"], document.Code)
+	documentRow(pdf, y+22, labels["issued"], documentTime(document.IssuedAt))
+
+	pdf.Text(60, y+60, 9, false, labels["verify"])
+	pdf.Text(60, y+74, 9, false, service.VerifyURL+document.Code)
+	pdf.Text(60, y+110, 11, false, labels["thanks"])
+}
+
+// documentRow prints a label and its value and returns the next line.
+func documentRow(pdf *common.PDF, y float64, label, value string) float64 {
+	pdf.Text(60, y, 11, false, label)
+	pdf.Text(220, y, 11, true, value)
+
+	return y + 22
+}
+
+// documentTime prints a time in WIB.
+func documentTime(at time.Time) string {
+	location, _ := common.Timezone(enums.TimezoneWIB)
+
+	return at.In(location).Format("02-01-2006 15:04") + " " + enums.TimezoneWIB
+}
+
+func labelsOf(locale string) map[string]string {
+	if labels, ok := documentLabels[locale]; ok {
+		return labels
+	}
+
+	return documentLabels[enums.DefaultLocale]
+}
@@ -0,0 +1,442 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// anonymousDonor is shown instead of the name of anonymous donors.
+const anonymousDonor = "Anonymous"
+
+type DonationService struct {
+	DB      *gorm.DB
+	Log     *zerolog.Logger
+	Channel *ChannelService
+	Stream  *StreamService
+}
+
+func NewDonationService(ctx context.Context, db *gorm.DB) *DonationService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &DonationService{
+		DB:      db,
+		Log:     logger,
+		Channel: NewChannelService(ctx, db),
+		Stream:  NewStreamService(ctx),
+	}
+}
+
+// Create records a donation waiting for its payment to be confirmed. userId is
+// the donor account giving it, or 0 for a guest, who gets an access token to
+// download the receipt with later.
+func (service DonationService) Create(userId int, input dto.DonationRequest) (*dto.DonationCreated, *dto.ApiError) {
+	var campaign entities.Campaign
+	if err := service.DB.First(&campaign, "id", input.CampaignID).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    err.Error(),
+		}
+	}
+
+	if campaign.Status != enums.CampaignStatusFundraising {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDonationClosed.Error(),
+		}
+	}
+
+	donation := entities.Donation{
+		CampaignID:  campaign.ID,
+		DonorID:     userId,
+		Name:        input.Name,
+		Email:       input.Email,
+		IsAnonymous: input.IsAnonymous,
+		Amount:      decimal.NewFromFloat(input.Amount).Round(2),
+		Message:     input.Message,
+		Status:      enums.DonationStatusPending,
+	}
+
+	if userId != 0 {
+		var oauth entities.Oauth
+		if err := service.DB.First(&oauth, "id", userId).Error; err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrNotFound,
+				Message:    err.Error(),
+			}
+		}
+
+		donation.Name = oauth.Fullname
+		donation.Email = oauth.Email
+	} else {
+		token, err := common.GenerateSecret(32)
+		if err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		donation.AccessToken = token
+	}
+
+	if err := service.DB.Create(&donation).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &dto.DonationCreated{
+		ID:          donation.ID,
+		CampaignID:  donation.CampaignID,
+		Amount:      donation.Amount,
+		Status:      donation.Status,
+		AccessToken: donation.AccessToken,
+	}, nil
+}
+
+// Review confirms or rejects the payment of a pending donation. A paid
+// donation gets its receipt number, counts towards the collected amount of
+// its campaign and has its receipt mailed to the donor.
+func (service DonationService) Review(userId int, id string, input dto.DonationReview) (*entities.Donation, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	var donation entities.Donation
+	if err := tx.First(&donation, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	// lock the campaign so concurrent confirmations add up
+	var campaign entities.Campaign
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&campaign, "id", donation.CampaignID).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	// money can only be counted for a campaign still raising or spending it
+	if input.Status == enums.DonationStatusPaid &&
+		campaign.Status != enums.CampaignStatusFundraising && campaign.Status != enums.CampaignStatusFunded {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDonationClosed.Error(),
+		}
+	}
+
+	now := time.Now()
+	update := map[string]any{
+		"status":      input.Status,
+		"note":        input.Note,
+		"reviewed_by": userId,
+	}
+
+	if input.Status == enums.DonationStatusPaid {
+		update["receipt_number"] = receiptNumber(donation.ID, now)
+		update["paid_at"] = now
+	}
+
+	result := tx.Model(&entities.Donation{}).
+		Where("id = ? AND status = ?", donation.ID, enums.DonationStatusPending).
+		Updates(update)
+	if result.Error != nil {
+		service.Log.Error().Msg(result.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    result.Error.Error(),
+		}
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDonationReviewed.Error(),
+		}
+	}
+
+	if err := tx.First(&donation, "id", donation.ID).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if donation.Status == enums.DonationStatusPaid {
+		if err := service.collect(tx, events, &campaign, &donation); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	events.Publish()
+
+	return &donation, nil
+}
+
+// collect adds a paid donation to its campaign within tx, publishes the new
+// progress and queues the receipt. Whether the campaign is funded now is left
+// to the campaign scheduler.
+func (service DonationService) collect(tx *gorm.DB, events *EventBatch, campaign *entities.Campaign, donation *entities.Donation) error {
+	campaign.Collected = campaign.Collected.Add(donation.Amount)
+	if err := tx.Model(&entities.Campaign{}).
+		Where("id = ?", campaign.ID).
+		Update("collected", gorm.Expr("collected + ?", donation.Amount)).Error; err != nil {
+		return err
+	}
+
+	var donors int64
+	if err := tx.Model(&entities.Donation{}).
+		Where("campaign_id = ? AND status = ?", campaign.ID, enums.DonationStatusPaid).
+		Count(&donors).Error; err != nil {
+		return err
+	}
+
+	events.Add(dto.StreamEvent{
+		Topic: enums.StreamTopicDonation,
+		Type:  enums.DonationStatusPaid,
+		Data: dto.DonationProgressEvent{
+			CampaignID:     campaign.ID,
+			DonationTarget: campaign.DonationTarget.InexactFloat64(),
+			Collected:      campaign.Collected.InexactFloat64(),
+			Donors:         donors,
+		},
+	})
+
+	if donation.Email == "" {
+		return nil
+	}
+
+	recipient := dto.Recipient{
+		Email:   donation.Email,
+		Locale:  enums.DefaultLocale,
+		Channel: enums.OutboxChannelEmail,
+	}
+
+	if donation.DonorID != 0 {
+		var oauth entities.Oauth
+		if err := tx.First(&oauth, "id", donation.DonorID).Error; err != nil {
+			return err
+		}
+
+		recipient.Locale = oauth.Locale
+	}
+
+	donation.Campaign = campaign
+
+	return service.Channel.Notify(tx, recipient, ReceiptMail(donation))
+}
+
+// receiptNumber numbers the receipt of a donation paid at the given time,
+// e.g. FD-20230917-0001.
+func receiptNumber(id int, paidAt time.Time) string {
+	location, _ := common.Timezone(enums.TimezoneWIB)
+
+	return fmt.Sprintf("FD-%s-%04d", paidAt.In(location).Format("20060102"), id)
+}
+
+// ReceiptMail is the receipt of a paid donation, with its campaign loaded.
+func ReceiptMail(donation *entities.Donation) dto.DonationReceiptMail {
+	receipt := dto.DonationReceiptMail{
+		DonorName: donation.Name,
+		Amount:    donation.Amount,
+	}
+
+	if receipt.DonorName == "" {
+		receipt.DonorName = anonymousDonor
+	}
+
+	if donation.ReceiptNumber != nil {
+		receipt.ReceiptNumber = *donation.ReceiptNumber
+	}
+
+	if donation.PaidAt != nil {
+		receipt.DonatedAt = *donation.PaidAt
+	}
+
+	if donation.Campaign != nil {
+		receipt.EventName = donation.Campaign.EventName
+	}
+
+	return receipt
+}
+
+// GetAll lists donations for the admins, filtered by campaign, donor and status.
+func (service DonationService) GetAll(c *fiber.Ctx, pagination *common.Pagination) ([]entities.Donation, *dto.ApiError) {
+	var donations []entities.Donation
+
+	query := service.DB.Order("created_at desc")
+
+	if campaignId := c.Query("campaign_id"); campaignId != "" {
+		query = query.Where("campaign_id = ?", campaignId)
+	}
+
+	if donorId := c.Query("donor_id"); donorId != "" {
+		query = query.Where("donor_id = ?", donorId)
+	}
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query = query.Find(&donations)
+
+	if err := query.Scopes(common.Paginate(query, entities.Donation{}, pagination)).Find(&donations); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return donations, nil
+}
+
+// GetMine is the donation history of a donor.
+func (service DonationService) GetMine(c *fiber.Ctx, userId int, pagination *common.Pagination) ([]entities.Donation, *dto.ApiError) {
+	var donations []entities.Donation
+
+	query := service.DB.
+		Preload("Campaign", unscoped).
+		Where("donor_id = ?", userId).
+		Order("created_at desc")
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query = query.Find(&donations)
+
+	if err := query.Scopes(common.Paginate(query, entities.Donation{}, pagination)).Find(&donations); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return donations, nil
+}
+
+// GetByCampaign lists the paid donations of a campaign for its public page,
+// hiding the names of anonymous donors.
+func (service DonationService) GetByCampaign(campaignId string, pagination *common.Pagination) ([]dto.PublicDonation, *dto.ApiError) {
+	var donations []entities.Donation
+
+	query := service.DB.
+		Where("campaign_id = ? AND status = ?", campaignId, enums.DonationStatusPaid).
+		Order("paid_at desc")
+
+	query = query.Find(&donations)
+
+	if err := query.Scopes(common.Paginate(query, entities.Donation{}, pagination)).Find(&donations); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	public := []dto.PublicDonation{}
+	for _, donation := range donations {
+		name := donation.Name
+		if donation.IsAnonymous || name == "" {
+			name = anonymousDonor
+		}
+
+		public = append(public, dto.PublicDonation{
+			Name:    name,
+			Amount:  donation.Amount,
+			Message: donation.Message,
+			PaidAt:  donation.PaidAt,
+		})
+	}
+
+	return public, nil
+}
+
+// Receipt is a paid donation of the donor, with its campaign.
+func (service DonationService) Receipt(userId int, id string) (*entities.Donation, *dto.ApiError) {
+	var donation entities.Donation
+	if err := service.DB.
+		Preload("Campaign", unscoped).
+		First(&donation, "id = ? AND donor_id = ?", id, userId).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return paidDonation(&donation)
+}
+
+// GuestReceipt is a paid guest donation, looked up with the access token
+// handed out when it was made.
+func (service DonationService) GuestReceipt(id, token string) (*entities.Donation, *dto.ApiError) {
+	if token == "" {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    enums.ErrNotFound.Error(),
+		}
+	}
+
+	var donation entities.Donation
+	if err := service.DB.
+		Preload("Campaign", unscoped).
+		First(&donation, "id = ? AND donor_id = ? AND access_token = ?", id, 0, token).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return paidDonation(&donation)
+}
+
+func paidDonation(donation *entities.Donation) (*entities.Donation, *dto.ApiError) {
+	if donation.Status != enums.DonationStatusPaid || donation.ReceiptNumber == nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrReceiptNotIssued.Error(),
+		}
+	}
+
+	return donation, nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many records an export loads at a time.
+const exportBatchSize = 500
+
+const exportTimeFormat = "2006-01-02 15:04:05"
+
+// Export writes the rows of an export to sheet. It runs while the response
+// streams, after the handler returned, so it must not use the request.
+type Export func(sheet common.SheetWriter)
+
+// ExportService streams lists as spreadsheets with the filters of their
+// /filter endpoints, loading the records in batches.
+type ExportService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewExportService(ctx context.Context, db *gorm.DB) *ExportService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &ExportService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// writeBatches writes header and a row per record of query, one batch at a
+// time. Batches are read in primary key order.
+func writeBatches[T any](log *zerolog.Logger, query *gorm.DB, sheet common.SheetWriter, header []string, row func(record T) []string) {
+	if err := sheet.Write(header); err != nil {
+		log.Error().Msg(err.Error())
+		return
+	}
+
+	var batch []T
+	result := query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, number int) error {
+		for _, record := range batch {
+			if err := sheet.Write(row(record)); err != nil {
+				return err
+			}
+		}
+
+		return sheet.Flush()
+	})
+	if result.Error != nil {
+		// the response has started, all that is left is to cut it short
+		log.Error().Msg(result.Error.Error())
+		return
+	}
+
+	if err := sheet.Close(); err != nil {
+		log.Error().Msg(err.Error())
+	}
+}
+
+// ktp shows the KTP number in full to superadmins only.
+func ktp(number string, unmask bool) string {
+	if unmask {
+		return number
+	}
+
+	return common.MaskKTP(number)
+}
+
+func exportTime(at *time.Time) string {
+	if at == nil || at.IsZero() {
+		return ""
+	}
+
+	return at.Format(exportTimeFormat)
+}
+
+func (service ExportService) Merchants(unmask bool) Export {
+	query := service.DB.Preload("Oauth")
+
+	header := []string{"id", "fullname", "email", "phone", "ktp_number", "status", "province", "city", "sub_district", "postal_code", "address", "no_link_aja", "created_at"}
+
+	return func(sheet common.SheetWriter) {
+		writeBatches(service.Log, query, sheet, header, func(merchant entities.Merchant) []string {
+			var fullname, email, phone string
+			if merchant.Oauth != nil {
+				fullname, email, phone = merchant.Oauth.Fullname, merchant.Oauth.Email, merchant.Oauth.Phone
+			}
+
+			return []string{
+				strconv.Itoa(merchant.ID), fullname, email, phone, ktp(merchant.KTPNumber, unmask),
+				merchant.Status, merchant.Province, merchant.City, merchant.SubDistrict, merchant.PostalCode,
+				merchant.Address, merchant.NoLinkAja, exportTime(&merchant.CreatedAt),
+			}
+		})
+	}
+}
+
+func (service ExportService) Detonators(unmask bool) Export {
+	query := service.DB.Preload("Oauth")
+
+	header := []string{"id", "fullname", "email", "phone", "ktp_number", "status", "created_at"}
+
+	return func(sheet common.SheetWriter) {
+		writeBatches(service.Log, query, sheet, header, func(detonator entities.Detonator) []string {
+			var fullname, email, phone string
+			if detonator.Oauth != nil {
+				fullname, email, phone = detonator.Oauth.Fullname, detonator.Oauth.Email, detonator.Oauth.Phone
+			}
+
+			return []string{
+				strconv.Itoa(detonator.ID), fullname, email, phone, ktp(detonator.KTPNumber, unmask),
+				detonator.Status, exportTime(&detonator.CreatedAt),
+			}
+		})
+	}
+}
+
+func (service ExportService) Campaigns(c *fiber.Ctx) Export {
+	query := filterCampaigns(c, service.DB.Preload("Detonator.Oauth"))
+
+	header := []string{"id", "event_name", "event_type", "event_date", "event_time", "timezone", "detonator_id", "detonator", "status", "donation_target", "collected", "province", "city", "sub_district", "address", "created_at"}
+
+	return func(sheet common.SheetWriter) {
+		writeBatches(service.Log, query, sheet, header, func(campaign entities.Campaign) []string {
+			var detonator string
+			if campaign.Detonator != nil && campaign.Detonator.Oauth != nil {
+				detonator = campaign.Detonator.Oauth.Fullname
+			}
+
+			return []string{
+				strconv.Itoa(campaign.ID), campaign.EventName, campaign.EventType, campaign.EventDate, campaign.EventTime,
+				campaign.Timezone, strconv.Itoa(campaign.DetonatorID), detonator, campaign.Status,
+				campaign.DonationTarget.StringFixed(2), campaign.Collected.StringFixed(2),
+				campaign.Province, campaign.City, campaign.SubDistrict, campaign.Address, exportTime(&campaign.CreatedAt),
+			}
+		})
+	}
+}
+
+func (service ExportService) Orders(c *fiber.Ctx) Export {
+	query := filterOrders(c, service.DB, service.DB.Preload("Campaign", unscoped).Preload("MerchantProduct", unscoped))
+
+	header := []string{"id", "campaign_id", "event_name", "merchant_id", "merchant_product_id", "product", "qty", "price", "total", "order_status", "delivered_at", "created_at"}
+
+	return func(sheet common.SheetWriter) {
+		writeBatches(service.Log, query, sheet, header, func(order entities.Order) []string {
+			var event, merchantId, product string
+			if order.Campaign != nil {
+				event = order.Campaign.EventName
+			}
+
+			if order.MerchantProduct != nil {
+				merchantId = strconv.Itoa(order.MerchantProduct.MerchantID)
+				product = order.MerchantProduct.Name
+			}
+
+			return []string{
+				strconv.Itoa(order.ID), strconv.Itoa(order.CampaignID), event, merchantId,
+				strconv.Itoa(order.MerchantProductID), product, strconv.Itoa(order.Qty),
+				order.Price.StringFixed(2), order.Total().StringFixed(2), order.OrderStatus,
+				exportTime(order.DeliveredAt), exportTime(&order.CreatedAt),
+			}
+		})
+	}
+}
+
+// Products exports the products of the ?merchant_id=, or of every merchant
+// when it is left out.
+func (service ExportService) Products(c *fiber.Ctx) Export {
+	query := service.DB
+
+	if merchantId := c.Query("merchant_id"); merchantId != "" {
+		query = query.Where("merchant_id = ?", merchantId)
+	}
+
+	header := []string{"id", "merchant_id", "name", "description", "price", "qty", "category_id", "portion_grams", "packaging", "status", "is_active", "created_at"}
+
+	return func(sheet common.SheetWriter) {
+		writeBatches(service.Log, query, sheet, header, func(product entities.MerchantProduct) []string {
+			var categoryId string
+			if product.CategoryID != nil {
+				categoryId = strconv.Itoa(*product.CategoryID)
+			}
+
+			return []string{
+				strconv.Itoa(product.ID), strconv.Itoa(product.MerchantID), product.Name, product.Description,
+				product.Price.StringFixed(2), strconv.Itoa(product.QTY), categoryId,
+				strconv.Itoa(product.PortionGrams), product.Packaging, product.Status,
+				strconv.FormatBool(product.IsActive), exportTime(&product.CreatedAt),
+			}
+		})
+	}
+}
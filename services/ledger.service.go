@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// LedgerService books merchant money movements as balanced double entries.
+// What the platform owes a merchant is the credit balance of their
+// merchant_payable account.
+type LedgerService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewLedgerService(ctx context.Context, db *gorm.DB) *LedgerService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &LedgerService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// Post books a transaction within tx. Entries must balance; a transaction
+// already booked for the same type and reference is left as is.
+func (service LedgerService) Post(tx *gorm.DB, kind, refType string, refId int, description string, entries ...entities.LedgerEntry) error {
+	debit, credit := decimal.Zero, decimal.Zero
+	for _, entry := range entries {
+		debit = debit.Add(entry.Debit)
+		credit = credit.Add(entry.Credit)
+	}
+
+	if !debit.Equal(credit) {
+		return fmt.Errorf("ledger %s of %s %d is not balanced: debit %s, credit %s", kind, refType, refId, debit, credit)
+	}
+
+	var booked int64
+	if err := tx.Model(&entities.LedgerTransaction{}).
+		Where("type = ? AND ref_type = ? AND ref_id = ?", kind, refType, refId).
+		Count(&booked).Error; err != nil {
+		return err
+	}
+
+	if booked > 0 {
+		return nil
+	}
+
+	return tx.Create(&entities.LedgerTransaction{
+		Type:        kind,
+		RefType:     refType,
+		RefID:       refId,
+		Description: description,
+		Entries:     entries,
+	}).Error
+}
+
+// transfer is a single movement of amount from one account of the merchant to
+// another.
+func transfer(merchantId int, from, to string, amount decimal.Decimal) []entities.LedgerEntry {
+	return []entities.LedgerEntry{
+		{Account: from, MerchantID: merchantId, Debit: amount},
+		{Account: to, MerchantID: merchantId, Credit: amount},
+	}
+}
+
+// AccrueOrder makes the cost of a delivered order payable to its merchant.
+func (service LedgerService) AccrueOrder(tx *gorm.DB, order *entities.Order) error {
+	if order.MerchantProduct == nil {
+		return fmt.Errorf("order %d has no product to accrue", order.ID)
+	}
+
+	amount := order.Total()
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	return service.Post(tx, enums.LedgerOrderAccrual, enums.LedgerRefOrder, order.ID,
+		fmt.Sprintf("order #%d, %d x %s", order.ID, order.Qty, order.MerchantProduct.Name),
+		transfer(order.MerchantProduct.MerchantID, enums.LedgerAccountOrderExpense, enums.LedgerAccountMerchantPayable, amount)...,
+	)
+}
+
+// Balances returns the credit balance of account per merchant, for the given
+// merchants or all of them.
+func (service LedgerService) Balances(tx *gorm.DB, account string, merchantIds ...int) (map[int]decimal.Decimal, error) {
+	var rows []struct {
+		MerchantID int
+		Balance    decimal.Decimal
+	}
+
+	query := tx.Model(&entities.LedgerEntry{}).
+		Select("merchant_id, SUM(credit) - SUM(debit) AS balance").
+		Where("account = ?", account).
+		Group("merchant_id")
+
+	if len(merchantIds) > 0 {
+		query = query.Where("merchant_id IN ?", merchantIds)
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	balances := map[int]decimal.Decimal{}
+	for _, row := range rows {
+		balances[row.MerchantID] = row.Balance
+	}
+
+	return balances, nil
+}
+
+// Statement lists the movements of the merchant's payable balance between
+// from and to, with a running balance.
+func (service LedgerService) Statement(merchantId int, from, to time.Time) (*dto.MerchantStatement, *dto.ApiError) {
+	statement := dto.MerchantStatement{
+		MerchantID: merchantId,
+		From:       from,
+		To:         to,
+		Lines:      []dto.MerchantStatementLine{},
+	}
+
+	if err := service.DB.Model(&entities.LedgerEntry{}).
+		Select("COALESCE(SUM(credit) - SUM(debit), 0)").
+		Where("account = ? AND merchant_id = ? AND created_at < ?", enums.LedgerAccountMerchantPayable, merchantId, from).
+		Scan(&statement.Opening).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	var entries []entities.LedgerEntry
+	if err := service.DB.
+		Preload("Transaction").
+		Where("account = ? AND merchant_id = ? AND created_at >= ? AND created_at < ?", enums.LedgerAccountMerchantPayable, merchantId, from, to).
+		Order("created_at asc, id asc").
+		Find(&entries).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	balance := statement.Opening
+	statement.Accrued = decimal.Zero
+	statement.PaidOut = decimal.Zero
+
+	for _, entry := range entries {
+		balance = balance.Add(entry.Credit).Sub(entry.Debit)
+
+		line := dto.MerchantStatementLine{
+			Date:    entry.CreatedAt,
+			Debit:   entry.Debit,
+			Credit:  entry.Credit,
+			Balance: balance,
+		}
+
+		if entry.Transaction != nil {
+			line.Type = entry.Transaction.Type
+			line.RefType = entry.Transaction.RefType
+			line.RefID = entry.Transaction.RefID
+			line.Description = entry.Transaction.Description
+
+			switch entry.Transaction.Type {
+			case enums.LedgerOrderAccrual:
+				statement.Accrued = statement.Accrued.Add(entry.Credit)
+			case enums.LedgerPayout, enums.LedgerPayoutReversed:
+				// a reversed payout gives back what its payout took
+				statement.PaidOut = statement.PaidOut.Add(entry.Debit).Sub(entry.Credit)
+			}
+		}
+
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	statement.Closing = balance
+
+	inTransit, err := service.Balances(service.DB, enums.LedgerAccountPayoutClearing, merchantId)
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	statement.InTransit = inTransit[merchantId]
+
+	return &statement, nil
+}
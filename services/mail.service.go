@@ -1,45 +1,273 @@
 package services
 
 import (
+	"bytes"
 	"embed"
-	"html/template"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
 
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/enums"
+
+	"github.com/shopspring/decimal"
 	"github.com/wneessen/go-mail"
 )
 
+const (
+	mailTemplateDir         = "templates/email"
+	shortMessageTemplateDir = "templates/sms"
+)
+
+// MailTemplate describes one entry of the transactional email catalogue.
+// Sample is used both to render previews and to know the payload type when
+// the message is read back from the outbox. ShortMessage marks templates that
+// also have an SMS/WhatsApp version under templates/sms.
+type MailTemplate struct {
+	Subject      map[string]string
+	Sample       dto.MailData
+	ShortMessage bool
+}
+
+// MailTemplates is the catalogue of every transactional email the platform sends.
+var MailTemplates = []MailTemplate{
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "Foodia One-Time Passcode",
+			enums.LocaleID: "Kode OTP Foodia",
+		},
+		Sample:       dto.OTPMail{Code: "123456"},
+		ShortMessage: true,
+	},
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "Welcome to Foodia",
+			enums.LocaleID: "Selamat datang di Foodia",
+		},
+		Sample: dto.WelcomeMail{Fullname: "Budi Santoso"},
+	},
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "We have received your Foodia registration",
+			enums.LocaleID: "Pendaftaran Foodia Anda telah kami terima",
+		},
+		Sample: dto.RegistrationReceivedMail{Fullname: "Budi Santoso", Role: "merchant"},
+	},
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "Your Foodia registration has been approved",
+			enums.LocaleID: "Pendaftaran Foodia Anda telah disetujui",
+		},
+		Sample:       dto.RegistrationApprovedMail{Fullname: "Budi Santoso", Role: "merchant"},
+		ShortMessage: true,
+	},
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "Your Foodia registration could not be approved",
+			enums.LocaleID: "Pendaftaran Foodia Anda belum dapat disetujui",
+		},
+		Sample:       dto.RegistrationRejectedMail{Fullname: "Budi Santoso", Role: "merchant", Note: "KTP photo is not readable"},
+		ShortMessage: true,
+	},
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "Your campaign has been approved",
+			enums.LocaleID: "Campaign Anda telah disetujui",
+		},
+		Sample:       dto.CampaignApprovedMail{Fullname: "Siti Rahma", EventName: "Berbagi Makan Siang", EventDate: "2023-09-17"},
+		ShortMessage: true,
+	},
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "You have a new order",
+			enums.LocaleID: "Anda menerima pesanan baru",
+		},
+		Sample: dto.OrderReceivedMail{
+			MerchantName: "Warung Bu Sri",
+			OrderID:      42,
+			EventName:    "Berbagi Makan Siang",
+			EventDate:    "2023-09-17",
+			ProductName:  "Nasi Kotak Ayam",
+			Quantity:     100,
+			Address:      "Jl. Merdeka No. 1, Bandung",
+		},
+		ShortMessage: true,
+	},
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "Your order status has changed",
+			enums.LocaleID: "Status pesanan Anda telah berubah",
+		},
+		Sample: dto.OrderStatusChangedMail{
+			Fullname:    "Siti Rahma",
+			OrderID:     42,
+			EventName:   "Berbagi Makan Siang",
+			ProductName: "Nasi Kotak Ayam",
+			Status:      "approved",
+		},
+		ShortMessage: true,
+	},
+	{
+		Subject: map[string]string{
+			enums.LocaleEN: "Your Foodia donation receipt",
+			enums.LocaleID: "Bukti donasi Foodia Anda",
+		},
+		Sample: dto.DonationReceiptMail{
+			DonorName:     "Andi Wijaya",
+			ReceiptNumber: "FD-20230917-0001",
+			EventName:     "Berbagi Makan Siang",
+			Amount:        decimal.NewFromInt(250000),
+			DonatedAt:     time.Date(2023, 9, 10, 9, 30, 0, 0, time.Local),
+		},
+	},
+}
+
+// FindMailTemplate looks up a catalogue entry by template name.
+func FindMailTemplate(name string) (*MailTemplate, bool) {
+	for _, tmpl := range MailTemplates {
+		if tmpl.Sample.MailTemplate() == name {
+			return &tmpl, true
+		}
+	}
+
+	return nil, false
+}
+
+type RenderedMail struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
 type MailService struct {
-	Client   *mail.Client
-	Sender   string
-	Template embed.FS
+	Transport MailTransport
+	Sender    string
+	Template  embed.FS
 }
 
-func NewMailService(sender string, client *mail.Client, template embed.FS) *MailService {
+func NewMailService(sender string, transport MailTransport, template embed.FS) *MailService {
 	return &MailService{
-		Client:   client,
-		Sender:   sender,
-		Template: template,
+		Transport: transport,
+		Sender:    sender,
+		Template:  template,
 	}
 }
 
-func (s MailService) SendOTP(destination string, code string) error {
-	tmpl, err := template.ParseFS(s.Template, "templates/email/otp.html")
+// Render produces the subject, HTML and plain-text parts of an email in the
+// given locale. Every template is rendered inside the shared layout and the
+// locale's partials; unsupported locales fall back to the default one.
+func (s MailService) Render(locale string, data dto.MailData) (*RenderedMail, error) {
+	tmpl, ok := FindMailTemplate(data.MailTemplate())
+	if !ok {
+		return nil, fmt.Errorf("unknown mail template %s", data.MailTemplate())
+	}
+
+	if !common.IsSupportedLocale(locale) {
+		locale = enums.DefaultLocale
+	}
+
+	funcs := map[string]any{
+		"rupiah": common.FormatRupiah,
+	}
+
+	name := data.MailTemplate()
+	dir := mailTemplateDir + "/" + locale
+
+	html, err := htmltemplate.New(name).Funcs(funcs).ParseFS(s.Template,
+		mailTemplateDir+"/layout.html",
+		dir+"/partials/*.html",
+		dir+"/"+name+".html",
+	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	text, err := texttemplate.New(name).Funcs(funcs).ParseFS(s.Template,
+		mailTemplateDir+"/layout.txt",
+		dir+"/partials/*.txt",
+		dir+"/"+name+".txt",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var htmlBody, textBody bytes.Buffer
+	if err := html.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return nil, err
 	}
 
-	data := map[string]any{
-		"code": code,
+	if err := text.ExecuteTemplate(&textBody, "layout", data); err != nil {
+		return nil, err
+	}
+
+	return &RenderedMail{
+		Subject: tmpl.Subject[locale],
+		HTML:    htmlBody.String(),
+		Text:    textBody.String(),
+	}, nil
+}
+
+// RenderShortMessage renders the SMS/WhatsApp text of a template in the given locale.
+func (s MailService) RenderShortMessage(locale string, data dto.MailData) (string, error) {
+	tmpl, ok := FindMailTemplate(data.MailTemplate())
+	if !ok || !tmpl.ShortMessage {
+		return "", fmt.Errorf("mail template %s has no short message", data.MailTemplate())
+	}
+
+	if !common.IsSupportedLocale(locale) {
+		locale = enums.DefaultLocale
+	}
+
+	name := data.MailTemplate()
+
+	text, err := texttemplate.New(name+".txt").Funcs(map[string]any{
+		"rupiah": common.FormatRupiah,
+	}).ParseFS(s.Template, shortMessageTemplateDir+"/"+locale+"/"+name+".txt")
+	if err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	if err := text.Execute(&body, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(body.String()), nil
+}
+
+// Send renders the email in the recipient's locale and delivers it with both a
+// plain-text and an HTML part.
+func (s MailService) Send(destination, locale string, data dto.MailData) error {
+	rendered, err := s.Render(locale, data)
+	if err != nil {
+		return err
 	}
 
 	msg := mail.NewMsg()
-	msg.To(destination)
-	msg.From(s.Sender)
-	msg.Subject("Foodia One-Time Passcode")
-	msg.SetBodyHTMLTemplate(tmpl, data)
+	if err := msg.To(destination); err != nil {
+		return err
+	}
+	if err := msg.From(s.Sender); err != nil {
+		return err
+	}
+	msg.Subject(rendered.Subject)
+	msg.SetBodyString(mail.TypeTextPlain, rendered.Text)
+	msg.AddAlternativeString(mail.TypeTextHTML, rendered.HTML)
+
+	if s.Transport == nil {
+		return enums.ErrMailTransportMissing
+	}
 
-	if err := s.Client.DialAndSend(msg); err != nil {
+	if err := s.Transport.Send(msg); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (s MailService) SendOTP(destination, locale, code string) error {
+	return s.Send(destination, locale, dto.OTPMail{Code: code})
+}
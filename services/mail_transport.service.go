@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+	"github.com/wneessen/go-mail"
+)
+
+// MailTransport delivers an already rendered message.
+type MailTransport interface {
+	Name() string
+	Send(msg *mail.Msg) error
+	// Ping checks whether the transport is currently able to deliver mail.
+	Ping(ctx context.Context) error
+}
+
+// NewMailTransport builds the transport selected by MAIL_TRANSPORT, wrapped so
+// that delivery outcomes are reported by the health endpoint.
+func NewMailTransport(config *configs.EnvConfig, logger *zerolog.Logger) (*MonitoredMailTransport, error) {
+	var transport MailTransport
+
+	switch config.MailTransport {
+	case "", enums.MailTransportSMTP:
+		client, err := mail.NewClient(
+			config.SmtpHost,
+			mail.WithPort(config.SmtpPort),
+			mail.WithSMTPAuth(mail.SMTPAuthPlain),
+			mail.WithUsername(config.SmtpUser),
+			mail.WithPassword(config.SmtpPass),
+			mail.WithSSL(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		transport = &SMTPTransport{Client: client}
+	case enums.MailTransportCapture:
+		transport = &CaptureTransport{Dir: config.MailCaptureDir, Log: logger}
+	case enums.MailTransportMemory:
+		transport = &MemoryTransport{}
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", config.MailTransport)
+	}
+
+	return &MonitoredMailTransport{MailTransport: transport}, nil
+}
+
+// SMTPTransport sends mail through the configured SMTP server. The client
+// holds a single connection, so deliveries and pings are serialised.
+type SMTPTransport struct {
+	Client *mail.Client
+	mu     sync.Mutex
+}
+
+func (t *SMTPTransport) Name() string {
+	return enums.MailTransportSMTP
+}
+
+func (t *SMTPTransport) Send(msg *mail.Msg) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.Client.DialAndSend(msg)
+}
+
+func (t *SMTPTransport) Ping(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.Client.DialWithContext(ctx); err != nil {
+		return err
+	}
+
+	return t.Client.Close()
+}
+
+// CaptureTransport never delivers mail. Messages are written as .eml files to
+// Dir, or printed to stdout when Dir is empty, so they can be inspected during
+// development.
+type CaptureTransport struct {
+	Dir string
+	Log *zerolog.Logger
+}
+
+func (t *CaptureTransport) Name() string {
+	return enums.MailTransportCapture
+}
+
+func (t *CaptureTransport) Send(msg *mail.Msg) error {
+	subject := strings.Join(msg.GetGenHeader(mail.HeaderSubject), " ")
+	recipients := strings.Join(msg.GetToString(), ", ")
+
+	if t.Dir == "" {
+		fmt.Printf("---- captured mail to %s: %s ----\n", recipients, subject)
+		if _, err := msg.WriteTo(os.Stdout); err != nil {
+			return err
+		}
+		fmt.Println()
+		return nil
+	}
+
+	if err := os.MkdirAll(t.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), common.GenerateUUID())
+	path := filepath.Join(t.Dir, name)
+	if err := msg.WriteToFile(path); err != nil {
+		return err
+	}
+
+	t.Log.Info().Msg(fmt.Sprintf("captured mail to %s: %s (%s)", recipients, subject, path))
+
+	return nil
+}
+
+func (t *CaptureTransport) Ping(ctx context.Context) error {
+	if t.Dir == "" {
+		return nil
+	}
+
+	return os.MkdirAll(t.Dir, os.ModePerm)
+}
+
+// MemoryTransport keeps sent messages in memory, for tests.
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []*mail.Msg
+}
+
+func (t *MemoryTransport) Name() string {
+	return enums.MailTransportMemory
+}
+
+func (t *MemoryTransport) Send(msg *mail.Msg) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = append(t.messages, msg)
+	return nil
+}
+
+func (t *MemoryTransport) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Messages returns the messages sent so far.
+func (t *MemoryTransport) Messages() []*mail.Msg {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*mail.Msg(nil), t.messages...)
+}
+
+// Reset forgets every sent message.
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = nil
+}
+
+// MonitoredMailTransport records the outcome of every delivery of the wrapped transport.
+type MonitoredMailTransport struct {
+	MailTransport
+
+	mu            sync.Mutex
+	lastSuccessAt *time.Time
+	lastFailureAt *time.Time
+	lastError     string
+}
+
+func (t *MonitoredMailTransport) Send(msg *mail.Msg) error {
+	err := t.MailTransport.Send(msg)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if err != nil {
+		t.lastFailureAt = &now
+		t.lastError = err.Error()
+	} else {
+		t.lastSuccessAt = &now
+	}
+
+	return err
+}
+
+// Health pings the transport and combines the result with the latest delivery
+// outcome: down when the transport is unreachable, degraded when it is reachable
+// but the last delivery failed, up otherwise.
+func (t *MonitoredMailTransport) Health(ctx context.Context) dto.MailHealth {
+	pingErr := t.Ping(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := dto.MailHealth{
+		Transport:     t.Name(),
+		Status:        enums.HealthStatusUp,
+		LastSuccessAt: t.lastSuccessAt,
+		LastFailureAt: t.lastFailureAt,
+		LastError:     t.lastError,
+	}
+
+	switch {
+	case pingErr != nil:
+		health.Status = enums.HealthStatusDown
+		health.LastError = pingErr.Error()
+	case t.failing():
+		health.Status = enums.HealthStatusDegraded
+	}
+
+	return health
+}
+
+// Status reports the state of the transport from the recorded deliveries only,
+// without contacting it: degraded when the last delivery failed, up otherwise.
+func (t *MonitoredMailTransport) Status() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.failing() {
+		return enums.HealthStatusDegraded
+	}
+
+	return enums.HealthStatusUp
+}
+
+// failing reports whether the last delivery failed; the caller must hold mu.
+func (t *MonitoredMailTransport) failing() bool {
+	return t.lastFailureAt != nil && (t.lastSuccessAt == nil || t.lastFailureAt.After(*t.lastSuccessAt))
+}
@@ -2,9 +2,7 @@ package services
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"path/filepath"
+	"time"
 
 	"foodia-be/common"
 	"foodia-be/dto"
@@ -15,31 +13,47 @@ import (
 	"github.com/rs/zerolog"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MerchantService struct {
-	DB          *gorm.DB
-	Log         *zerolog.Logger
-	AuthService *AuthService
+	DB           *gorm.DB
+	Log          *zerolog.Logger
+	AuthService  *AuthService
+	Storage      *StorageService
+	Channel      *ChannelService
+	Notification *NotificationService
+	Stream       *StreamService
+	Ledger       *LedgerService
 }
 
 func NewMerchantService(ctx context.Context, db *gorm.DB) *MerchantService {
 	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
 
 	return &MerchantService{
-		DB:          db,
-		Log:         logger,
-		AuthService: NewAuthService(ctx, db),
+		DB:           db,
+		Log:          logger,
+		AuthService:  NewAuthService(ctx, db),
+		Storage:      NewStorageService(logger),
+		Channel:      NewChannelService(ctx, db),
+		Notification: NewNotificationService(ctx, db),
+		Stream:       NewStreamService(ctx),
+		Ledger:       NewLedgerService(ctx, db),
 	}
 }
 
-func (service MerchantService) MerchantRegistration(c *fiber.Ctx, input dto.MerchantRegistration) (*entities.Merchant, *dto.ApiError) {
+func (service MerchantService) MerchantRegistration(input dto.MerchantRegistration, locale string) (*entities.Merchant, *dto.ApiError) {
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
+	files := service.Storage.Batch()
+	defer files.Rollback()
+
+	events := service.Stream.Batch()
+
 	// store self photo file to the storage
-	selfPhoto := "merchant/" + common.GenerateUUID() + filepath.Ext(input.SelfPhoto.Filename)
-	if err := c.SaveFile(input.SelfPhoto, fmt.Sprintf("./storage/%s", selfPhoto)); err != nil {
+	selfPhoto, err := files.Store("merchant", input.SelfPhoto)
+	if err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -48,8 +62,8 @@ func (service MerchantService) MerchantRegistration(c *fiber.Ctx, input dto.Merc
 	}
 
 	// store ktp photo file to the storage
-	ktpPhoto := "merchant/" + common.GenerateUUID() + filepath.Ext(input.SelfPhoto.Filename)
-	if err := c.SaveFile(input.SelfPhoto, fmt.Sprintf("./storage/%s", ktpPhoto)); err != nil {
+	ktpPhoto, err := files.Store("merchant", input.KTPPhoto)
+	if err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -74,6 +88,8 @@ func (service MerchantService) MerchantRegistration(c *fiber.Ctx, input dto.Merc
 		UserId:   common.GenerateUUID(),
 		Password: string(password),
 		Role:     "merchant",
+		Locale:   locale,
+		Channel:  input.Channel,
 	}
 
 	if err := tx.Create(&ouath).Error; err != nil {
@@ -86,19 +102,20 @@ func (service MerchantService) MerchantRegistration(c *fiber.Ctx, input dto.Merc
 
 	// insert merchant
 	merchant := entities.Merchant{
-		UserId:      ouath.ID,
-		KTPNumber:   input.KTPNumber,
-		KTPPhoto:    ktpPhoto,
-		SelfPhoto:   selfPhoto,
-		Status:      "waiting",
-		Province:    input.Province,
-		City:        input.City,
-		SubDistrict: input.SubDistrict,
-		PostalCode:  input.PostalCode,
-		Address:     input.Address,
-		Latitude:    input.Latitude,
-		Longitude:   input.Latitude,
-		NoLinkAja:   input.NoLinkAja,
+		UserId:         ouath.ID,
+		KTPNumber:      input.KTPNumber,
+		KTPPhoto:       ktpPhoto,
+		SelfPhoto:      selfPhoto,
+		Status:         "waiting",
+		Province:       input.Province,
+		City:           input.City,
+		SubDistrict:    input.SubDistrict,
+		PostalCode:     input.PostalCode,
+		Address:        input.Address,
+		Latitude:       input.Latitude,
+		Longitude:      input.Longitude,
+		NoLinkAja:      input.NoLinkAja,
+		DeliveryRadius: input.DeliveryRadius,
 	}
 
 	if err := tx.Create(&merchant).Error; err != nil {
@@ -111,10 +128,14 @@ func (service MerchantService) MerchantRegistration(c *fiber.Ctx, input dto.Merc
 
 	// send OTP
 	OTP := dto.OTPRequest{
-		Email: ouath.Email,
+		Email:     ouath.Email,
+		Phone:     ouath.Phone,
+		Channel:   ouath.Channel,
+		Locale:    ouath.Locale,
+		ExpiredAt: time.Now().Add(RegistrationOTPTTL),
 	}
 
-	if err := service.AuthService.SendOTP(OTP); err != nil {
+	if err := service.AuthService.SendOTP(tx, OTP); err != nil {
 		service.Log.Error().Msg(err.StatusCode.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -122,6 +143,37 @@ func (service MerchantService) MerchantRegistration(c *fiber.Ctx, input dto.Merc
 		}
 	}
 
+	received := dto.RegistrationReceivedMail{
+		Fullname: ouath.Fullname,
+		Role:     ouath.Role,
+	}
+
+	if err := service.Channel.Notify(tx, RecipientOf(&ouath), received); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	submitted := dto.NotificationEvent{
+		Type:    enums.NotificationRegistrationSubmitted,
+		RefType: enums.NotificationRefMerchant,
+		RefID:   merchant.ID,
+		Params: map[string]any{
+			"Fullname": ouath.Fullname,
+			"Role":     ouath.Role,
+		},
+	}
+
+	if err := service.Notification.PushRole(tx, events, "superadmin", submitted); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
@@ -130,6 +182,9 @@ func (service MerchantService) MerchantRegistration(c *fiber.Ctx, input dto.Merc
 		}
 	}
 
+	files.Commit()
+	events.Publish()
+
 	return &merchant, nil
 }
 
@@ -175,8 +230,10 @@ func (service MerchantService) MerchantApproval(id string, input dto.MerchantApp
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
+	events := service.Stream.Batch()
+
 	var merchant entities.Merchant
-	if err := tx.First(&merchant, "id", id).Error; err != nil {
+	if err := tx.Preload("Oauth").First(&merchant, "id", id).Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
@@ -197,6 +254,14 @@ func (service MerchantService) MerchantApproval(id string, input dto.MerchantApp
 		}
 	}
 
+	if err := service.notifyReview(tx, events, &merchant, input.Status, input.Note); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
@@ -205,37 +270,44 @@ func (service MerchantService) MerchantApproval(id string, input dto.MerchantApp
 		}
 	}
 
+	events.Publish()
+
 	return &update, nil
 }
 
-func (service MerchantService) MerchantUpdate(c *fiber.Ctx, id string, input dto.MerchantUpdate) (*entities.Merchant, *dto.ApiError) {
+func (service MerchantService) MerchantUpdate(id string, input dto.MerchantUpdate) (*entities.Merchant, *dto.ApiError) {
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
+	files := service.Storage.Batch()
+	defer files.Rollback()
+
 	var selfPhoto string
 	// store self photo file to the storage
 	if input.SelfPhoto != nil {
-		selfPhoto = "merchant/" + common.GenerateUUID() + filepath.Ext(input.SelfPhoto.Filename)
-		if err := c.SaveFile(input.SelfPhoto, fmt.Sprintf("./storage/%s", selfPhoto)); err != nil {
+		path, err := files.Store("merchant", input.SelfPhoto)
+		if err != nil {
 			service.Log.Error().Msg(err.Error())
 			return nil, &dto.ApiError{
 				StatusCode: fiber.ErrInternalServerError,
 				Message:    err.Error(),
 			}
 		}
+		selfPhoto = path
 	}
 
 	var ktpPhoto string
 	// store ktp photo file to the storage
 	if input.KTPPhoto != nil {
-		ktpPhoto = "merchant/" + common.GenerateUUID() + filepath.Ext(input.KTPPhoto.Filename)
-		if err := c.SaveFile(input.SelfPhoto, fmt.Sprintf("./storage/%s", ktpPhoto)); err != nil {
+		path, err := files.Store("merchant", input.KTPPhoto)
+		if err != nil {
 			service.Log.Error().Msg(err.Error())
 			return nil, &dto.ApiError{
 				StatusCode: fiber.ErrInternalServerError,
 				Message:    err.Error(),
 			}
 		}
+		ktpPhoto = path
 	}
 
 	var merchant entities.Merchant
@@ -243,22 +315,25 @@ func (service MerchantService) MerchantUpdate(c *fiber.Ctx, id string, input dto
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
-			Message:    errors.New("merchant not found").Error(),
+			Message:    enums.ErrNotFound.Error(),
 		}
 	}
 
+	previousSelfPhoto, previousKTPPhoto := merchant.SelfPhoto, merchant.KTPPhoto
+
 	merchantUpdate := entities.Merchant{
-		KTPNumber:   input.KTPNumber,
-		KTPPhoto:    ktpPhoto,
-		SelfPhoto:   selfPhoto,
-		Province:    input.Province,
-		City:        input.City,
-		SubDistrict: input.SubDistrict,
-		PostalCode:  input.PostalCode,
-		Address:     input.Address,
-		Latitude:    input.Latitude,
-		Longitude:   input.Latitude,
-		NoLinkAja:   input.NoLinkAja,
+		KTPNumber:      input.KTPNumber,
+		KTPPhoto:       ktpPhoto,
+		SelfPhoto:      selfPhoto,
+		Province:       input.Province,
+		City:           input.City,
+		SubDistrict:    input.SubDistrict,
+		PostalCode:     input.PostalCode,
+		Address:        input.Address,
+		Latitude:       input.Latitude,
+		Longitude:      input.Longitude,
+		NoLinkAja:      input.NoLinkAja,
+		DeliveryRadius: input.DeliveryRadius,
 	}
 
 	if err := tx.Model(&merchant).Updates(&merchantUpdate).Error; err != nil {
@@ -274,7 +349,7 @@ func (service MerchantService) MerchantUpdate(c *fiber.Ctx, id string, input dto
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
-			Message:    errors.New("oauth not found").Error(),
+			Message:    enums.ErrNotFound.Error(),
 		}
 	}
 
@@ -300,7 +375,225 @@ func (service MerchantService) MerchantUpdate(c *fiber.Ctx, id string, input dto
 		}
 	}
 
+	files.Commit()
+
+	// drop the replaced photos once nothing references their content anymore
+	if selfPhoto != "" && selfPhoto != previousSelfPhoto {
+		service.Storage.Release(previousSelfPhoto, service.photoInUse)
+	}
+	if ktpPhoto != "" && ktpPhoto != previousKTPPhoto {
+		service.Storage.Release(previousKTPPhoto, service.photoInUse)
+	}
+
 	merchant.Oauth = &ouath
 
 	return &merchant, nil
 }
+
+// photoInUse reports whether any merchant still references the stored photo.
+func (service MerchantService) photoInUse(path string) bool {
+	var count int64
+	if err := service.DB.Model(&entities.Merchant{}).
+		Where("self_photo = ? OR ktp_photo = ?", path, path).
+		Count(&count).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		// keep the file when in doubt
+		return true
+	}
+
+	return count > 0
+}
+
+// notifyReview tells the merchant about the outcome of the registration review,
+// both in the app and on their preferred channel.
+func (service MerchantService) notifyReview(tx *gorm.DB, events *EventBatch, merchant *entities.Merchant, status, note string) error {
+	oauth := merchant.Oauth
+	if oauth == nil {
+		return nil
+	}
+
+	event := dto.NotificationEvent{
+		RefType: enums.NotificationRefMerchant,
+		RefID:   merchant.ID,
+		Params: map[string]any{
+			"Role": oauth.Role,
+			"Note": note,
+		},
+	}
+
+	var mail dto.MailData
+	switch status {
+	case "approved":
+		event.Type = enums.NotificationRegistrationApproved
+		mail = dto.RegistrationApprovedMail{
+			Fullname: oauth.Fullname,
+			Role:     oauth.Role,
+			Note:     note,
+		}
+	case "rejected":
+		event.Type = enums.NotificationRegistrationRejected
+		mail = dto.RegistrationRejectedMail{
+			Fullname: oauth.Fullname,
+			Role:     oauth.Role,
+			Note:     note,
+		}
+	default:
+		return nil
+	}
+
+	if err := service.Notification.Push(tx, events, oauth, event); err != nil {
+		return err
+	}
+
+	return service.Channel.Notify(tx, RecipientOf(oauth), mail)
+}
+
+// Delete soft deletes a merchant and its products. Merchants whose products
+// still have open orders are kept until those orders are done.
+func (service MerchantService) Delete(id string) *dto.ApiError {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	// locked so no payout batch picks the merchant up while it is deleted
+	var merchant entities.Merchant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&merchant, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	// money owed to the merchant or on its way must be settled first
+	for _, account := range []string{enums.LedgerAccountMerchantPayable, enums.LedgerAccountPayoutClearing} {
+		balances, err := service.Ledger.Balances(tx, account, merchant.ID)
+		if err != nil {
+			service.Log.Error().Msg(err.Error())
+			return &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		if !balances[merchant.ID].IsZero() {
+			return &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrMerchantBalance.Error(),
+			}
+		}
+	}
+
+	open, err := openOrders(tx, tx.Model(&entities.MerchantProduct{}).Select("id").Where("merchant_id = ?", merchant.ID))
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if open {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrProductInUse.Error(),
+		}
+	}
+
+	// products are marked with the time of the merchant so they are restored
+	// with it, except those deleted on their own before
+	now := time.Now().Truncate(time.Second)
+
+	if err := tx.Model(&entities.MerchantProduct{}).
+		Where("merchant_id = ?", merchant.ID).
+		Update("deleted_at", now).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Model(&merchant).Update("deleted_at", now).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// Restore brings back a deleted merchant with the products deleted along
+// with it.
+func (service MerchantService) Restore(id string) (*entities.Merchant, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	var merchant entities.Merchant
+	if err := tx.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&merchant).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Unscoped().Model(&entities.MerchantProduct{}).
+		Where("merchant_id = ? AND deleted_at = ?", merchant.ID, merchant.DeletedAt.Time).
+		Update("deleted_at", nil).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Unscoped().Model(&merchant).Update("deleted_at", nil).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetByID(id)
+}
+
+func (service MerchantService) GetDeleted(pagination *common.Pagination) ([]entities.Merchant, *dto.ApiError) {
+	var merchants []entities.Merchant
+
+	query := service.DB.Unscoped().
+		Preload("Oauth").
+		Order("deleted_at desc").
+		Where("deleted_at IS NOT NULL").
+		Find(&merchants)
+
+	if err := query.Scopes(common.Paginate(query, entities.Merchant{}, pagination)).Find(&merchants); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return merchants, nil
+}
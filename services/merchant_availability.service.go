@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MerchantAvailabilityService keeps when merchants are open and how many
+// portions they can cook a day, which orders for a campaign must fit in.
+type MerchantAvailabilityService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewMerchantAvailabilityService(ctx context.Context, db *gorm.DB) *MerchantAvailabilityService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &MerchantAvailabilityService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// GetSchedule returns the merchant with its weekly hours and the closures not
+// over yet.
+func (service MerchantAvailabilityService) GetSchedule(id string) (*entities.Merchant, *dto.ApiError) {
+	var merchant entities.Merchant
+
+	location, _ := common.Timezone(enums.TimezoneWIB)
+	today := time.Now().In(location).Format("2006-01-02")
+
+	if err := service.DB.
+		Preload("Hours", func(db *gorm.DB) *gorm.DB {
+			return db.Order("weekday")
+		}).
+		Preload("Closures", func(db *gorm.DB) *gorm.DB {
+			return db.Where("end_date >= ?", today).Order("start_date")
+		}).
+		Where("id = ?", id).
+		First(&merchant).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &merchant, nil
+}
+
+// SetSchedule replaces the weekly hours and the daily capacity of the
+// merchant. Merchants may only change their own, superadmins pass an ownerId
+// of 0.
+func (service MerchantAvailabilityService) SetSchedule(ownerId int, id string, input dto.MerchantScheduleRequest) (*entities.Merchant, *dto.ApiError) {
+	merchant, fail := service.ownMerchant(ownerId, id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	var hours []entities.MerchantHour
+	weekdays := map[int]bool{}
+
+	for _, hour := range input.Hours {
+		if hour.ClosesAt <= hour.OpensAt || weekdays[hour.Weekday] {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrMerchantHours.Error(),
+			}
+		}
+		weekdays[hour.Weekday] = true
+
+		hours = append(hours, entities.MerchantHour{
+			MerchantID: merchant.ID,
+			Weekday:    hour.Weekday,
+			OpensAt:    hour.OpensAt,
+			ClosesAt:   hour.ClosesAt,
+		})
+	}
+
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	if err := tx.Model(merchant).Update("daily_capacity", input.DailyCapacity).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Where("merchant_id = ?", merchant.ID).Delete(&entities.MerchantHour{}).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if len(hours) > 0 {
+		if err := tx.Create(&hours).Error; err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetSchedule(id)
+}
+
+// AddClosure closes the merchant for the days of input.
+func (service MerchantAvailabilityService) AddClosure(ownerId int, id string, input dto.MerchantClosureRequest) (*entities.MerchantClosure, *dto.ApiError) {
+	merchant, fail := service.ownMerchant(ownerId, id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	if input.EndDate < input.StartDate {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrClosurePeriod.Error(),
+		}
+	}
+
+	closure := entities.MerchantClosure{
+		MerchantID: merchant.ID,
+		StartDate:  input.StartDate,
+		EndDate:    input.EndDate,
+		Note:       input.Note,
+	}
+
+	if err := service.DB.Create(&closure).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &closure, nil
+}
+
+// DeleteClosure opens the merchant again on the days of the closure of id.
+func (service MerchantAvailabilityService) DeleteClosure(ownerId int, id string) *dto.ApiError {
+	var closure entities.MerchantClosure
+	if err := service.DB.First(&closure, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if _, fail := service.ownMerchant(ownerId, closure.MerchantID); fail != nil {
+		return fail
+	}
+
+	if err := service.DB.Delete(&closure).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// GetDay tells whether the merchant takes orders on the ?date= and how much
+// of its capacity is left then.
+func (service MerchantAvailabilityService) GetDay(id string, date string) (*dto.MerchantDay, *dto.ApiError) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBadParamInput.Error(),
+		}
+	}
+
+	var merchant entities.Merchant
+	if err := service.DB.First(&merchant, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	day, err := merchantDay(service.DB, merchant, date)
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return day, nil
+}
+
+func (service MerchantAvailabilityService) ownMerchant(ownerId int, id any) (*entities.Merchant, *dto.ApiError) {
+	var merchant entities.Merchant
+
+	query := service.DB.Where("id = ?", id)
+	if ownerId != 0 {
+		query = query.Where("user_id = ?", ownerId)
+	}
+
+	if err := query.First(&merchant).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &merchant, nil
+}
+
+// merchantDay works out the hours, closure and booked portions of the
+// merchant on the YYYY-MM-DD date. Portions are booked by the waiting and
+// approved orders of campaigns on that date that are not cancelled.
+func merchantDay(db *gorm.DB, merchant entities.Merchant, date string) (*dto.MerchantDay, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, err
+	}
+
+	var hours []entities.MerchantHour
+	if err := db.Where("merchant_id = ?", merchant.ID).Find(&hours).Error; err != nil {
+		return nil, err
+	}
+
+	var closure entities.MerchantClosure
+	if err := db.
+		Where("merchant_id = ? AND start_date <= ? AND end_date >= ?", merchant.ID, date, date).
+		Limit(1).
+		Find(&closure).Error; err != nil {
+		return nil, err
+	}
+
+	var booked int64
+	if err := db.Model(&entities.Order{}).
+		Select("COALESCE(SUM(orders.qty), 0)").
+		Joins("JOIN merchant_products ON merchant_products.id = orders.merchant_product_id").
+		Joins("JOIN campaigns ON campaigns.id = orders.campaign_id AND campaigns.deleted_at IS NULL").
+		Where("merchant_products.merchant_id = ? AND campaigns.event_date = ? AND campaigns.status <> ? AND orders.order_status IN ?",
+			merchant.ID, date, enums.CampaignStatusCancelled, []string{enums.OrderStatusWaiting, enums.OrderStatusApproved}).
+		Scan(&booked).Error; err != nil {
+		return nil, err
+	}
+
+	return buildDay(merchant, date, hours, closure, int(booked))
+}
+
+// buildDay puts together the day of the merchant on the YYYY-MM-DD date from
+// its weekly hours, the closure covering the date if any (ID 0 otherwise) and
+// the portions booked. Merchants without hours are open every day, those
+// without a daily capacity have no limit, shown as a Remaining of -1.
+func buildDay(merchant entities.Merchant, date string, hours []entities.MerchantHour, closure entities.MerchantClosure, booked int) (*dto.MerchantDay, error) {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, err
+	}
+
+	day := dto.MerchantDay{
+		MerchantID: merchant.ID,
+		Date:       date,
+		Open:       true,
+		Capacity:   merchant.DailyCapacity,
+		Booked:     booked,
+		Remaining:  -1,
+	}
+
+	if len(hours) > 0 {
+		day.Open = false
+		for _, hour := range hours {
+			if hour.Weekday == int(parsed.Weekday()) {
+				day.Open = true
+				day.OpensAt = hour.OpensAt
+				day.ClosesAt = hour.ClosesAt
+			}
+		}
+	}
+
+	if closure.ID != 0 {
+		day.Open = false
+		day.Closure = closure.Note
+	}
+
+	if day.Capacity > 0 {
+		day.Remaining = day.Capacity - day.Booked
+		if day.Remaining < 0 {
+			day.Remaining = 0
+		}
+	}
+
+	return &day, nil
+}
+
+// checkAvailability makes sure the merchant is open at the time of the
+// campaign event and has room left for qty more portions that day. The
+// merchant row stays locked until tx ends so concurrent orders are counted
+// against each other.
+func checkAvailability(tx *gorm.DB, merchantId int, campaign entities.Campaign, qty int) error {
+	var merchant entities.Merchant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&merchant, "id", merchantId).Error; err != nil {
+		return err
+	}
+
+	date, clock := eventDay(campaign)
+
+	day, err := merchantDay(tx, merchant, date)
+	if err != nil {
+		return err
+	}
+
+	return dayAvailability(day, clock, qty)
+}
+
+// dayAvailability tells why qty more portions cannot be cooked on the day for
+// an event at the HH:MM clock, if they cannot.
+func dayAvailability(day *dto.MerchantDay, clock string, qty int) error {
+	if !openAt(day, clock) {
+		return enums.ErrMerchantClosed
+	}
+
+	if day.Remaining >= 0 && qty > day.Remaining {
+		return enums.ErrMerchantCapacity
+	}
+
+	return nil
+}
+
+// eventDay is the YYYY-MM-DD date and HH:MM time of the campaign event in
+// its own time zone.
+func eventDay(campaign entities.Campaign) (string, string) {
+	if campaign.EventAt == nil {
+		return campaign.EventDate, campaign.EventTime
+	}
+
+	location, err := common.Timezone(campaign.Timezone)
+	if err != nil {
+		location, _ = common.Timezone(enums.TimezoneWIB)
+	}
+
+	at := campaign.EventAt.In(location)
+
+	return at.Format("2006-01-02"), at.Format("15:04")
+}
+
+// openAt tells whether the merchant is open on the day at the HH:MM clock.
+func openAt(day *dto.MerchantDay, clock string) bool {
+	if !day.Open {
+		return false
+	}
+
+	return day.OpensAt == "" || (clock >= day.OpensAt && clock <= day.ClosesAt)
+}
+
+// availabilityError turns an error of checkAvailability into its response.
+func availabilityError(log *zerolog.Logger, err error) *dto.ApiError {
+	if err == enums.ErrMerchantClosed || err == enums.ErrMerchantCapacity {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    err.Error(),
+		}
+	}
+
+	log.Error().Msg(err.Error())
+	return &dto.ApiError{
+		StatusCode: fiber.ErrInternalServerError,
+		Message:    err.Error(),
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+)
+
+func TestBuildDay(t *testing.T) {
+	// 2023-09-17 is a Sunday
+	weekdays := []entities.MerchantHour{
+		{Weekday: 1, OpensAt: "08:00", ClosesAt: "17:00"},
+		{Weekday: 6, OpensAt: "09:00", ClosesAt: "13:00"},
+	}
+	sundays := []entities.MerchantHour{{Weekday: 0, OpensAt: "10:00", ClosesAt: "20:00"}}
+	closure := entities.MerchantClosure{ID: 1, Note: "Lebaran"}
+
+	tests := []struct {
+		name      string
+		capacity  int
+		date      string
+		hours     []entities.MerchantHour
+		closure   entities.MerchantClosure
+		booked    int
+		open      bool
+		opensAt   string
+		remaining int
+	}{
+		{"no hours is open all day", 0, "2023-09-17", nil, entities.MerchantClosure{}, 0, true, "", -1},
+		{"open on the weekday", 0, "2023-09-17", sundays, entities.MerchantClosure{}, 0, true, "10:00", -1},
+		{"closed on other weekdays", 0, "2023-09-17", weekdays, entities.MerchantClosure{}, 0, false, "", -1},
+		{"closure", 0, "2023-09-17", sundays, closure, 0, false, "10:00", -1},
+		{"capacity left", 100, "2023-09-18", weekdays, entities.MerchantClosure{}, 40, true, "08:00", 60},
+		{"fully booked", 100, "2023-09-18", weekdays, entities.MerchantClosure{}, 100, true, "08:00", 0},
+		{"overbooked", 100, "2023-09-18", weekdays, entities.MerchantClosure{}, 130, true, "08:00", 0},
+		{"no capacity is unlimited", 0, "2023-09-18", weekdays, entities.MerchantClosure{}, 500, true, "08:00", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merchant := entities.Merchant{ID: 4, DailyCapacity: tt.capacity}
+
+			day, err := buildDay(merchant, tt.date, tt.hours, tt.closure, tt.booked)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if day.Open != tt.open || day.OpensAt != tt.opensAt || day.Remaining != tt.remaining {
+				t.Fatalf("day = %+v, want open %v opening at %q with %d remaining", day, tt.open, tt.opensAt, tt.remaining)
+			}
+
+			if day.MerchantID != merchant.ID || day.Booked != tt.booked || day.Closure != tt.closure.Note {
+				t.Fatalf("day = %+v does not describe the merchant, bookings and closure", day)
+			}
+		})
+	}
+
+	if _, err := buildDay(entities.Merchant{}, "17-09-2023", nil, entities.MerchantClosure{}, 0); err == nil {
+		t.Fatal("expected an error for a malformed date")
+	}
+}
+
+func TestDayAvailability(t *testing.T) {
+	tests := []struct {
+		name  string
+		day   dto.MerchantDay
+		clock string
+		qty   int
+		want  error
+	}{
+		{"open all day", dto.MerchantDay{Open: true, Remaining: -1}, "23:30", 1000, nil},
+		{"within hours", dto.MerchantDay{Open: true, OpensAt: "08:00", ClosesAt: "17:00", Remaining: -1}, "12:00", 10, nil},
+		{"at opening", dto.MerchantDay{Open: true, OpensAt: "08:00", ClosesAt: "17:00", Remaining: -1}, "08:00", 10, nil},
+		{"at closing", dto.MerchantDay{Open: true, OpensAt: "08:00", ClosesAt: "17:00", Remaining: -1}, "17:00", 10, nil},
+		{"before opening", dto.MerchantDay{Open: true, OpensAt: "08:00", ClosesAt: "17:00", Remaining: -1}, "07:59", 10, enums.ErrMerchantClosed},
+		{"after closing", dto.MerchantDay{Open: true, OpensAt: "08:00", ClosesAt: "17:00", Remaining: -1}, "17:01", 10, enums.ErrMerchantClosed},
+		{"closed day", dto.MerchantDay{Open: false, Remaining: 50}, "12:00", 10, enums.ErrMerchantClosed},
+		{"fits the capacity", dto.MerchantDay{Open: true, Remaining: 50}, "12:00", 50, nil},
+		{"over the capacity", dto.MerchantDay{Open: true, Remaining: 50}, "12:00", 51, enums.ErrMerchantCapacity},
+		{"fully booked", dto.MerchantDay{Open: true, Remaining: 0}, "12:00", 1, enums.ErrMerchantCapacity},
+		{"nothing more on a full day", dto.MerchantDay{Open: true, Remaining: 0}, "12:00", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dayAvailability(&tt.day, tt.clock, tt.qty); got != tt.want {
+				t.Fatalf("dayAvailability() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventDay(t *testing.T) {
+	at := time.Date(2023, 9, 17, 17, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		campaign entities.Campaign
+		date     string
+		clock    string
+	}{
+		{"without an instant", entities.Campaign{EventDate: "2023-09-17", EventTime: "10:00"}, "2023-09-17", "10:00"},
+		{"WIB", entities.Campaign{EventAt: &at, Timezone: enums.TimezoneWIB}, "2023-09-18", "00:30"},
+		{"WITA", entities.Campaign{EventAt: &at, Timezone: enums.TimezoneWITA}, "2023-09-18", "01:30"},
+		{"WIT", entities.Campaign{EventAt: &at, Timezone: enums.TimezoneWIT}, "2023-09-18", "02:30"},
+		{"unknown time zone falls back to WIB", entities.Campaign{EventAt: &at, Timezone: "CET"}, "2023-09-18", "00:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, clock := eventDay(tt.campaign)
+			if date != tt.date || clock != tt.clock {
+				t.Fatalf("eventDay() = %s %s, want %s %s", date, clock, tt.date, tt.clock)
+			}
+		})
+	}
+}
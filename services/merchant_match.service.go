@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	// MatchCandidates is how many of the nearest merchants are looked into.
+	MatchCandidates   = 50
+	DefaultMatchLimit = 10
+	// MatchRadius is how far merchants are looked for without a ?radius=.
+	MatchRadius = 50000
+)
+
+// MerchantMatchService suggests the merchants a detonator could order the
+// food of a campaign from.
+type MerchantMatchService struct {
+	DB     *gorm.DB
+	Log    *zerolog.Logger
+	Budget *BudgetService
+}
+
+func NewMerchantMatchService(ctx context.Context, db *gorm.DB) *MerchantMatchService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &MerchantMatchService{
+		DB:     db,
+		Log:    logger,
+		Budget: NewBudgetService(ctx, db),
+	}
+}
+
+// Match ranks the approved merchants delivering to the campaign location that
+// are open at the event and have products passing the catalogue filters of
+// filterProducts. Each comes with a plan ordering its cheapest products first
+// for up to ?portions= portions, within its stock and capacity that day.
+// Merchants covering every portion rank first, then the nearest and the
+// cheapest. ?radius= caps the distance in meters, MatchRadius by default,
+// and ?limit= the merchants returned. Detonators only match their own campaigns, superadmins pass an
+// ownerId of 0.
+func (service MerchantMatchService) Match(c *fiber.Ctx, ownerId int, id string) ([]dto.MerchantMatch, *dto.ApiError) {
+	var campaign entities.Campaign
+	if err := service.DB.
+		Preload("Detonator").
+		First(&campaign, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if ownerId != 0 && (campaign.Detonator == nil || campaign.Detonator.UserId != ownerId) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	latitude, longitude, err := common.ParseCoordinates(campaign.Latitude, campaign.Longitude)
+	if err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrCampaignLocation.Error(),
+		}
+	}
+
+	portions, err := strconv.Atoi(c.Query("portions"))
+	if err != nil || portions <= 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBadParamInput.Error(),
+		}
+	}
+
+	radius := float64(MatchRadius)
+	if value := c.Query("radius"); value != "" {
+		if radius, err = strconv.ParseFloat(value, 64); err != nil || radius <= 0 {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrBadParamInput.Error(),
+			}
+		}
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 || limit > MatchCandidates {
+		limit = DefaultMatchLimit
+	}
+
+	// coordinates are stored as text, the box only narrows the merchants down
+	// before their distance is measured
+	minLat, maxLat, minLng, maxLng := common.BoundingBox(latitude, longitude, radius)
+
+	var merchants []entities.Merchant
+	if err := service.DB.
+		Preload("Oauth").
+		Where("status = ?", "approved").
+		Where("CAST(latitude AS DECIMAL(10,7)) BETWEEN ? AND ?", minLat, maxLat).
+		Where("CAST(longitude AS DECIMAL(10,7)) BETWEEN ? AND ?", minLng, maxLng).
+		Find(&merchants).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	type candidate struct {
+		merchant entities.Merchant
+		distance float64
+	}
+
+	var candidates []candidate
+
+	for _, merchant := range merchants {
+		// the same value twice is a placeholder, not where the merchant is
+		lat, lng, err := common.ParseCoordinates(merchant.Latitude, merchant.Longitude)
+		if err != nil || lat == lng {
+			continue
+		}
+
+		distance := common.Distance(latitude, longitude, lat, lng)
+		if merchant.DeliveryRadius > 0 && distance > float64(merchant.DeliveryRadius) {
+			continue
+		}
+
+		if distance > radius {
+			continue
+		}
+
+		candidates = append(candidates, candidate{merchant, distance})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	if len(candidates) > MatchCandidates {
+		candidates = candidates[:MatchCandidates]
+	}
+
+	matches := []dto.MerchantMatch{}
+	if len(candidates) == 0 {
+		return matches, nil
+	}
+
+	var ids []int
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.merchant.ID)
+	}
+
+	query, err := filterProducts(c, service.DB, service.DB.Where("merchant_products.merchant_id IN ?", ids))
+	if err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBadParamInput.Error(),
+		}
+	}
+
+	var products []entities.MerchantProduct
+	if err := query.Find(&products).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	offers := map[int][]dto.MatchOrder{}
+	stock := map[int]int{}
+	now := time.Now()
+
+	for _, product := range products {
+		price, err := effectivePrice(service.DB, product, now)
+		if err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		offers[product.MerchantID] = append(offers[product.MerchantID], dto.MatchOrder{
+			MerchantProductID: product.ID,
+			Name:              product.Name,
+			Price:             price,
+		})
+		stock[product.ID] = product.QTY
+	}
+
+	budget, err := service.Budget.compute(service.DB, &campaign, decimal.Zero)
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	date, clock := eventDay(campaign)
+
+	for _, candidate := range candidates {
+		merchant := candidate.merchant
+
+		if len(offers[merchant.ID]) == 0 {
+			continue
+		}
+
+		day, err := merchantDay(service.DB, merchant, date)
+		if err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		room := portions
+		if day.Remaining >= 0 && day.Remaining < room {
+			room = day.Remaining
+		}
+
+		if !openAt(day, clock) || room == 0 {
+			continue
+		}
+
+		match := dto.MerchantMatch{
+			MerchantID:     merchant.ID,
+			City:           merchant.City,
+			Distance:       candidate.distance,
+			DeliveryRadius: merchant.DeliveryRadius,
+			Capacity:       day.Capacity,
+			Remaining:      day.Remaining,
+			Products:       len(offers[merchant.ID]),
+		}
+
+		if merchant.Oauth != nil {
+			match.Name = merchant.Oauth.Fullname
+		}
+
+		match.Plan, match.Portions, match.Total = planOrders(offers[merchant.ID], stock, room)
+
+		match.Complete = match.Portions == portions
+		match.WithinBudget = budget.Override || match.Total.LessThanOrEqual(budget.Remaining)
+
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Complete != matches[j].Complete {
+			return matches[i].Complete
+		}
+
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+
+		return matches[i].Total.LessThan(matches[j].Total)
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// planOrders orders the cheapest offers first, as much of each as its stock
+// allows, until room portions are planned. It returns the orders with the
+// portions and total they come to.
+func planOrders(offers []dto.MatchOrder, stock map[int]int, room int) ([]dto.MatchOrder, int, decimal.Decimal) {
+	sorted := append([]dto.MatchOrder{}, offers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Price.LessThan(sorted[j].Price)
+	})
+
+	plan := []dto.MatchOrder{}
+	portions := 0
+	total := decimal.Zero
+
+	for _, order := range sorted {
+		if portions == room {
+			break
+		}
+
+		order.Qty = stock[order.MerchantProductID]
+		if left := room - portions; order.Qty > left {
+			order.Qty = left
+		}
+
+		if order.Qty <= 0 {
+			continue
+		}
+
+		order.Total = order.Price.Mul(decimal.NewFromInt(int64(order.Qty)))
+
+		plan = append(plan, order)
+		portions += order.Qty
+		total = total.Add(order.Total)
+	}
+
+	return plan, portions, total
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPlanOrders(t *testing.T) {
+	offer := func(id int, price int64) dto.MatchOrder {
+		return dto.MatchOrder{MerchantProductID: id, Price: decimal.NewFromInt(price)}
+	}
+
+	offers := []dto.MatchOrder{offer(1, 30000), offer(2, 20000), offer(3, 25000)}
+
+	tests := []struct {
+		name     string
+		offers   []dto.MatchOrder
+		stock    map[int]int
+		room     int
+		want     map[int]int
+		order    []int
+		portions int
+		total    int64
+	}{
+		{"cheapest covers all", offers, map[int]int{1: 100, 2: 100, 3: 100}, 50, map[int]int{2: 50}, []int{2}, 50, 1000000},
+		{"spills over to the next cheapest", offers, map[int]int{1: 100, 2: 30, 3: 10}, 50, map[int]int{2: 30, 3: 10, 1: 10}, []int{2, 3, 1}, 50, 1150000},
+		{"not enough stock", offers, map[int]int{1: 5, 2: 10, 3: 5}, 50, map[int]int{2: 10, 3: 5, 1: 5}, []int{2, 3, 1}, 20, 475000},
+		{"out of stock skipped", offers, map[int]int{1: 10, 2: 0, 3: 10}, 15, map[int]int{3: 10, 1: 5}, []int{3, 1}, 15, 400000},
+		{"no room", offers, map[int]int{1: 10, 2: 10, 3: 10}, 0, map[int]int{}, []int{}, 0, 0},
+		{"no offers", nil, map[int]int{}, 10, map[int]int{}, []int{}, 0, 0},
+		{"equal prices keep their order", []dto.MatchOrder{offer(4, 20000), offer(5, 20000)}, map[int]int{4: 5, 5: 5}, 8, map[int]int{4: 5, 5: 3}, []int{4, 5}, 8, 160000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, portions, total := planOrders(tt.offers, tt.stock, tt.room)
+
+			if len(plan) != len(tt.order) {
+				t.Fatalf("plan = %+v, want products %v", plan, tt.order)
+			}
+
+			for i, order := range plan {
+				if order.MerchantProductID != tt.order[i] || order.Qty != tt.want[order.MerchantProductID] {
+					t.Fatalf("plan = %+v, want products %v with %v portions", plan, tt.order, tt.want)
+				}
+
+				if !order.Total.Equal(order.Price.Mul(decimal.NewFromInt(int64(order.Qty)))) {
+					t.Fatalf("order %+v is not priced at its quantity", order)
+				}
+			}
+
+			if portions != tt.portions || !total.Equal(decimal.NewFromInt(tt.total)) {
+				t.Fatalf("planned %d portions for %s, want %d for %d", portions, total, tt.portions, tt.total)
+			}
+		})
+	}
+
+	if offers[0].MerchantProductID != 1 || offers[0].Qty != 0 {
+		t.Fatalf("planOrders changed the offers given: %+v", offers)
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	// Monas, Jakarta
+	lat, lng := -6.175392, 106.827153
+
+	minLat, maxLat, minLng, maxLng := common.BoundingBox(lat, lng, 10000)
+
+	points := []struct {
+		name   string
+		lat    float64
+		lng    float64
+		inside bool
+	}{
+		{"center", lat, lng, true},
+		{"9 km north", lat + 0.0809, lng, true},
+		{"9 km east", lat, lng + 0.0814, true},
+		{"12 km south", lat - 0.1079, lng, false},
+		{"12 km west", lat, lng - 0.1086, false},
+	}
+
+	for _, point := range points {
+		t.Run(point.name, func(t *testing.T) {
+			distance := common.Distance(lat, lng, point.lat, point.lng)
+			if (distance <= 10000) != point.inside {
+				t.Fatalf("point is %.0f m away, not a case of inside = %v", distance, point.inside)
+			}
+
+			inside := point.lat >= minLat && point.lat <= maxLat && point.lng >= minLng && point.lng <= maxLng
+			if inside != point.inside {
+				t.Fatalf("inside box = %v, want %v (%f..%f, %f..%f)", inside, point.inside, minLat, maxLat, minLng, maxLng)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"foodia-be/common"
 	"foodia-be/dto"
@@ -15,16 +17,20 @@ import (
 )
 
 type MerchantProductService struct {
-	DB  *gorm.DB
-	Log *zerolog.Logger
+	DB           *gorm.DB
+	Log          *zerolog.Logger
+	Notification *NotificationService
+	Stream       *StreamService
 }
 
 func NewMerchantProductService(ctx context.Context, db *gorm.DB) *MerchantProductService {
 	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
 
 	return &MerchantProductService{
-		DB:  db,
-		Log: logger,
+		DB:           db,
+		Log:          logger,
+		Notification: NewNotificationService(ctx, db),
+		Stream:       NewStreamService(ctx),
 	}
 }
 
@@ -32,15 +38,19 @@ func (service MerchantProductService) Create(input dto.MerchantProductRequest) (
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
-	merchantProduct := entities.MerchantProduct{
-		MerchantID:  input.MerchantID,
-		Name:        input.Name,
-		Description: input.Description,
-		Price:       decimal.NewFromFloat(input.Price),
-		QTY:         input.QTY,
+	if fail := service.checkCategory(tx, input.CategoryID); fail != nil {
+		return nil, fail
 	}
 
-	if err := tx.Create(&merchantProduct).Error; err != nil {
+	if _, err := createProduct(tx, input); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -48,6 +58,33 @@ func (service MerchantProductService) Create(input dto.MerchantProductRequest) (
 		}
 	}
 
+	return &input, nil
+}
+
+// createProduct creates the product of input with its images and attributes.
+func createProduct(tx *gorm.DB, input dto.MerchantProductRequest) (*entities.MerchantProduct, error) {
+	merchantProduct := entities.MerchantProduct{
+		MerchantID:   input.MerchantID,
+		Name:         input.Name,
+		Description:  input.Description,
+		Price:        decimal.NewFromFloat(input.Price),
+		QTY:          input.QTY,
+		PortionGrams: input.PortionGrams,
+		Packaging:    input.Packaging,
+	}
+
+	if input.CategoryID != 0 {
+		merchantProduct.CategoryID = &input.CategoryID
+	}
+
+	if err := tx.Create(&merchantProduct).Error; err != nil {
+		return nil, err
+	}
+
+	if err := recordPrice(tx, merchantProduct); err != nil {
+		return nil, err
+	}
+
 	var productImages []entities.MerchantProductImage
 
 	for _, image := range input.Images {
@@ -57,23 +94,162 @@ func (service MerchantProductService) Create(input dto.MerchantProductRequest) (
 		})
 	}
 
-	if err := tx.Create(&productImages).Error; err != nil {
-		service.Log.Error().Msg(err.Error())
-		return nil, &dto.ApiError{
-			StatusCode: fiber.ErrInternalServerError,
-			Message:    err.Error(),
+	if len(productImages) > 0 {
+		if err := tx.Create(&productImages).Error; err != nil {
+			return nil, err
 		}
 	}
 
-	if err := tx.Commit().Error; err != nil {
+	if err := saveAttributes(tx, merchantProduct.ID, input); err != nil {
+		return nil, err
+	}
+
+	return &merchantProduct, nil
+}
+
+// saveAttributes replaces the tags, dietary and allergen attributes of the
+// product with those of input. A kind left out of input is kept as it is.
+// contentEdited tells whether input changes what reviewers approved of the
+// product. Price and stock are the merchant's to change freely.
+func contentEdited(product entities.MerchantProduct, input dto.MerchantProductRequest) bool {
+	var categoryId int
+	if product.CategoryID != nil {
+		categoryId = *product.CategoryID
+	}
+
+	if product.Name != input.Name ||
+		product.Description != input.Description ||
+		categoryId != input.CategoryID ||
+		product.PortionGrams != input.PortionGrams ||
+		product.Packaging != input.Packaging {
+		return true
+	}
+
+	// attribute lists and images left out of the request are kept as they are
+	current := map[string][]string{}
+	for _, attribute := range product.Attributes {
+		current[attribute.Kind] = append(current[attribute.Kind], attribute.Value)
+	}
+
+	given := map[string][]string{
+		enums.ProductAttributeTag:      input.Tags,
+		enums.ProductAttributeDietary:  input.Dietary,
+		enums.ProductAttributeAllergen: input.Allergens,
+	}
+
+	for kind, values := range given {
+		if values != nil && !sameValues(current[kind], values) {
+			return true
+		}
+	}
+
+	if len(input.Images) == 0 {
+		return false
+	}
+
+	if len(input.Images) != len(product.MerchantProductImage) {
+		return true
+	}
+
+	for i, image := range input.Images {
+		if image.ImageURL != product.MerchantProductImage[i].ImageURL {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameValues compares the stored attribute values with the given ones the way
+// saveAttributes stores them, ignoring order, case and duplicates.
+func sameValues(current []string, given []string) bool {
+	want := map[string]bool{}
+	for _, value := range given {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			want[value] = true
+		}
+	}
+
+	if len(want) != len(current) {
+		return false
+	}
+
+	for _, value := range current {
+		if !want[value] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func saveAttributes(tx *gorm.DB, productId int, input dto.MerchantProductRequest) error {
+	kinds := map[string][]string{
+		enums.ProductAttributeTag:      input.Tags,
+		enums.ProductAttributeDietary:  input.Dietary,
+		enums.ProductAttributeAllergen: input.Allergens,
+	}
+
+	for kind, values := range kinds {
+		if values == nil {
+			continue
+		}
+
+		if err := tx.Where("merchant_product_id = ? AND kind = ?", productId, kind).
+			Delete(&entities.ProductAttribute{}).Error; err != nil {
+			return err
+		}
+
+		var attributes []entities.ProductAttribute
+		seen := map[string]bool{}
+
+		for _, value := range values {
+			value = strings.ToLower(strings.TrimSpace(value))
+			if value == "" || seen[value] {
+				continue
+			}
+			seen[value] = true
+
+			attributes = append(attributes, entities.ProductAttribute{
+				MerchantProductID: productId,
+				Kind:              kind,
+				Value:             value,
+			})
+		}
+
+		if len(attributes) > 0 {
+			if err := tx.Create(&attributes).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkCategory makes sure the category of a product exists, when it has one.
+func (service MerchantProductService) checkCategory(tx *gorm.DB, categoryId int) *dto.ApiError {
+	if categoryId == 0 {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&entities.ProductCategory{}).Where("id = ?", categoryId).Count(&count).Error; err != nil {
 		service.Log.Error().Msg(err.Error())
-		return nil, &dto.ApiError{
+		return &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
 			Message:    err.Error(),
 		}
 	}
 
-	return &input, nil
+	if count == 0 {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrCategoryNotFound.Error(),
+		}
+	}
+
+	return nil
 }
 
 func (service MerchantProductService) GetByMerchant(merchantId string, pagination *common.Pagination) ([]entities.MerchantProduct, *dto.ApiError) {
@@ -81,6 +257,8 @@ func (service MerchantProductService) GetByMerchant(merchantId string, paginatio
 
 	query := service.DB.
 		Preload("MerchantProductImage").
+		Preload("Category").
+		Preload("Attributes").
 		Order("created_at desc").
 		Where("merchant_id", merchantId).
 		Find(&merchantProducts)
@@ -101,6 +279,8 @@ func (service MerchantProductService) GetByID(id string) (*entities.MerchantProd
 
 	if err := service.DB.
 		Preload("MerchantProductImage").
+		Preload("Category").
+		Preload("Attributes").
 		Where("id", id).
 		First(&merchantProduct); err.Error != nil {
 		service.Log.Error().Msg(err.Error.Error())
@@ -113,13 +293,24 @@ func (service MerchantProductService) GetByID(id string) (*entities.MerchantProd
 	return &merchantProduct, nil
 }
 
-func (service MerchantProductService) Update(id string, input dto.MerchantProductRequest) (*dto.MerchantProductRequest, *dto.ApiError) {
+// Update changes the product of the merchant owned by ownerId, any product
+// for superadmins (ownerId 0). Products stay with their merchant, and edits to
+// what an approved product is send it back to review.
+func (service MerchantProductService) Update(ownerId int, id string, input dto.MerchantProductRequest) (*dto.MerchantProductRequest, *dto.ApiError) {
 	tx := service.DB.Begin()
 	defer tx.Rollback()
 
 	var merchantProduct entities.MerchantProduct
 
-	if err := tx.First(&merchantProduct, "id", id).Error; err != nil {
+	query := tx.Preload("MerchantProductImage").Preload("Attributes").Where("id = ?", id)
+	if ownerId != 0 {
+		query = query.Where("merchant_id IN (?)", tx.
+			Model(&entities.Merchant{}).
+			Select("id").
+			Where("user_id = ?", ownerId))
+	}
+
+	if err := query.First(&merchantProduct).Error; err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrNotFound,
@@ -127,15 +318,62 @@ func (service MerchantProductService) Update(id string, input dto.MerchantProduc
 		}
 	}
 
-	update := entities.MerchantProduct{
-		MerchantID:  input.MerchantID,
-		Name:        input.Name,
-		Description: input.Description,
-		Price:       decimal.NewFromFloat(input.Price),
-		QTY:         input.QTY,
+	if fail := service.checkCategory(tx, input.CategoryID); fail != nil {
+		return nil, fail
+	}
+
+	price := decimal.NewFromFloat(input.Price)
+
+	// a map so cleared fields are written too, Updates skips zero struct fields
+	update := map[string]any{
+		"name":          input.Name,
+		"description":   input.Description,
+		"price":         price,
+		"qty":           input.QTY,
+		"category_id":   nil,
+		"portion_grams": input.PortionGrams,
+		"packaging":     input.Packaging,
 	}
 
-	if err := tx.Model(&merchantProduct).Updates(&update).Error; err != nil {
+	if input.CategoryID != 0 {
+		update["category_id"] = input.CategoryID
+	}
+
+	// a rejected product goes back to review once it is corrected, an
+	// approved one once what was approved changes
+	switch merchantProduct.Status {
+	case enums.ProductStatusRejected:
+		update["status"] = enums.ProductStatusWaiting
+	case enums.ProductStatusApproved:
+		if contentEdited(merchantProduct, input) {
+			update["status"] = enums.ProductStatusWaiting
+		}
+	}
+
+	// price changes are kept so orders placed before stay explained
+	repriced := !price.Equal(merchantProduct.Price)
+
+	if err := tx.Model(&merchantProduct).Updates(update).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if repriced {
+		merchantProduct.Price = price
+
+		if err := recordPrice(tx, merchantProduct); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	if err := saveAttributes(tx, merchantProduct.ID, input); err != nil {
 		service.Log.Error().Msg(err.Error())
 		return nil, &dto.ApiError{
 			StatusCode: fiber.ErrInternalServerError,
@@ -183,3 +421,281 @@ func (service MerchantProductService) Update(id string, input dto.MerchantProduc
 
 	return &input, nil
 }
+
+// GetForReview lists the products of the ?status= (waiting by default)
+// across all merchants, oldest first, for the superadmins to review.
+func (service MerchantProductService) GetForReview(c *fiber.Ctx, pagination *common.Pagination) ([]entities.MerchantProduct, *dto.ApiError) {
+	var merchantProducts []entities.MerchantProduct
+
+	query := service.DB.
+		Preload("MerchantProductImage").
+		Preload("Category").
+		Preload("Attributes").
+		Order("created_at").
+		Where("status = ?", c.Query("status", enums.ProductStatusWaiting))
+
+	if merchantId := c.Query("merchant_id"); merchantId != "" {
+		query = query.Where("merchant_id = ?", merchantId)
+	}
+
+	query = query.Find(&merchantProducts)
+
+	if err := query.Scopes(common.Paginate(query, entities.MerchantProduct{}, pagination)).Find(&merchantProducts); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return merchantProducts, nil
+}
+
+// Review approves or rejects a product. Rejected products are deactivated
+// and the merchant is told about the outcome either way.
+func (service MerchantProductService) Review(userId int, id string, input dto.ProductReview) (*entities.MerchantProduct, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	var merchantProduct entities.MerchantProduct
+	if err := tx.
+		Preload("Merchant").
+		Preload("Merchant.Oauth").
+		First(&merchantProduct, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	update := map[string]any{
+		"status":      input.Status,
+		"review_note": input.Note,
+		"reviewed_by": userId,
+		"reviewed_at": time.Now(),
+	}
+
+	if input.Status == enums.ProductStatusRejected {
+		update["is_active"] = false
+	}
+
+	if err := tx.Model(&merchantProduct).Updates(update).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if merchantProduct.Merchant != nil && merchantProduct.Merchant.Oauth != nil {
+		event := dto.NotificationEvent{
+			Type:    enums.NotificationProductApproved,
+			RefType: enums.NotificationRefProduct,
+			RefID:   merchantProduct.ID,
+			Params: map[string]any{
+				"ProductName": merchantProduct.Name,
+				"Note":        input.Note,
+			},
+		}
+
+		if input.Status == enums.ProductStatusRejected {
+			event.Type = enums.NotificationProductRejected
+		}
+
+		if err := service.Notification.Push(tx, events, merchantProduct.Merchant.Oauth, event); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	events.Publish()
+
+	merchantProduct.Merchant = nil
+
+	return &merchantProduct, nil
+}
+
+// SetActive shows or hides an approved product from the catalogue. Merchants
+// may only change their own products, superadmins pass an ownerId of 0.
+func (service MerchantProductService) SetActive(ownerId int, id string, input dto.ProductActivation) (*entities.MerchantProduct, *dto.ApiError) {
+	var merchantProduct entities.MerchantProduct
+
+	query := service.DB.Where("id = ?", id)
+	if ownerId != 0 {
+		query = query.Where("merchant_id IN (?)", service.DB.
+			Model(&entities.Merchant{}).
+			Select("id").
+			Where("user_id = ?", ownerId))
+	}
+
+	if err := query.First(&merchantProduct).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if *input.IsActive && merchantProduct.Status != enums.ProductStatusApproved {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrProductNotApproved.Error(),
+		}
+	}
+
+	if err := service.DB.Model(&merchantProduct).Update("is_active", *input.IsActive).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &merchantProduct, nil
+}
+
+// available tells whether the product can be ordered so that qty of it are
+// held in all: it must be approved, active and have that much in stock.
+func available(product entities.MerchantProduct, qty int) bool {
+	return product.Status == enums.ProductStatusApproved && product.IsActive && product.QTY >= qty
+}
+
+// Delete soft deletes a product that has no open orders. Merchants may only
+// delete their own products, superadmins pass an ownerId of 0.
+func (service MerchantProductService) Delete(ownerId int, id string) *dto.ApiError {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	var merchantProduct entities.MerchantProduct
+
+	query := tx.Preload("MerchantProductImage").Preload("Attributes").Where("id = ?", id)
+	if ownerId != 0 {
+		query = query.Where("merchant_id IN (?)", tx.
+			Model(&entities.Merchant{}).
+			Select("id").
+			Where("user_id = ?", ownerId))
+	}
+
+	if err := query.First(&merchantProduct).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	open, err := openOrders(tx, tx.Model(&entities.MerchantProduct{}).Select("id").Where("id = ?", merchantProduct.ID))
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if open {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrProductInUse.Error(),
+		}
+	}
+
+	if err := tx.Delete(&merchantProduct).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// Restore brings back a deleted product, unless its merchant is deleted too.
+func (service MerchantProductService) Restore(id string) (*entities.MerchantProduct, *dto.ApiError) {
+	var merchantProduct entities.MerchantProduct
+
+	if err := service.DB.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		First(&merchantProduct).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	var merchants int64
+	if err := service.DB.Model(&entities.Merchant{}).Where("id = ?", merchantProduct.MerchantID).Count(&merchants).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if merchants == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrRestoreOwner.Error(),
+		}
+	}
+
+	if err := service.DB.Unscoped().Model(&merchantProduct).Update("deleted_at", nil).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetByID(id)
+}
+
+// GetDeleted lists the deleted products, of the ?merchant_id= when given.
+func (service MerchantProductService) GetDeleted(c *fiber.Ctx, pagination *common.Pagination) ([]entities.MerchantProduct, *dto.ApiError) {
+	var merchantProducts []entities.MerchantProduct
+
+	query := service.DB.Unscoped().
+		Preload("MerchantProductImage").
+		Order("deleted_at desc").
+		Where("deleted_at IS NOT NULL")
+
+	if merchantId := c.Query("merchant_id"); merchantId != "" {
+		query = query.Where("merchant_id = ?", merchantId)
+	}
+
+	query = query.Find(&merchantProducts)
+
+	if err := query.Scopes(common.Paginate(query, entities.MerchantProduct{}, pagination)).Find(&merchantProducts); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return merchantProducts, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+)
+
+func TestContentEdited(t *testing.T) {
+	categoryId := 2
+	product := entities.MerchantProduct{
+		Name:         "Nasi Box",
+		Description:  "Nasi, ayam dan sayur",
+		CategoryID:   &categoryId,
+		PortionGrams: 350,
+		Packaging:    "box",
+		Attributes: []entities.ProductAttribute{
+			{Kind: enums.ProductAttributeTag, Value: "ayam"},
+			{Kind: enums.ProductAttributeDietary, Value: "halal"},
+		},
+		MerchantProductImage: []entities.MerchantProductImage{{ImageURL: "storage/product/1.jpg"}},
+	}
+
+	request := func(edit func(*dto.MerchantProductRequest)) dto.MerchantProductRequest {
+		input := dto.MerchantProductRequest{
+			Name:         "Nasi Box",
+			Description:  "Nasi, ayam dan sayur",
+			Price:        25000,
+			QTY:          10,
+			CategoryID:   2,
+			PortionGrams: 350,
+			Packaging:    "box",
+		}
+		edit(&input)
+		return input
+	}
+
+	image := func(url string) []struct {
+		ImageURL string `json:"image_url"`
+	} {
+		return []struct {
+			ImageURL string `json:"image_url"`
+		}{{ImageURL: url}}
+	}
+
+	tests := []struct {
+		name  string
+		input dto.MerchantProductRequest
+		want  bool
+	}{
+		{"unchanged", request(func(r *dto.MerchantProductRequest) {}), false},
+		{"price and stock", request(func(r *dto.MerchantProductRequest) { r.Price, r.QTY = 30000, 5 }), false},
+		{"name", request(func(r *dto.MerchantProductRequest) { r.Name = "Nasi Kotak" }), true},
+		{"description", request(func(r *dto.MerchantProductRequest) { r.Description = "Nasi dan ayam" }), true},
+		{"category cleared", request(func(r *dto.MerchantProductRequest) { r.CategoryID = 0 }), true},
+		{"portion", request(func(r *dto.MerchantProductRequest) { r.PortionGrams = 300 }), true},
+		{"packaging", request(func(r *dto.MerchantProductRequest) { r.Packaging = "banana_leaf" }), true},
+		{"same tags", request(func(r *dto.MerchantProductRequest) { r.Tags = []string{" Ayam ", "ayam"} }), false},
+		{"new tag", request(func(r *dto.MerchantProductRequest) { r.Tags = []string{"ayam", "pedas"} }), true},
+		{"dietary cleared", request(func(r *dto.MerchantProductRequest) { r.Dietary = []string{} }), true},
+		{"allergen added", request(func(r *dto.MerchantProductRequest) { r.Allergens = []string{"egg"} }), true},
+		{"same image", request(func(r *dto.MerchantProductRequest) { r.Images = image("storage/product/1.jpg") }), false},
+		{"new image", request(func(r *dto.MerchantProductRequest) { r.Images = image("storage/product/2.jpg") }), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentEdited(product, tt.input); got != tt.want {
+				t.Fatalf("contentEdited() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		active bool
+		held   int
+		want   bool
+	}{
+		{"in stock", enums.ProductStatusApproved, true, 4, true},
+		{"last of the stock", enums.ProductStatusApproved, true, 10, true},
+		{"over the stock", enums.ProductStatusApproved, true, 11, false},
+		{"not approved", enums.ProductStatusWaiting, true, 1, false},
+		{"inactive", enums.ProductStatusApproved, false, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := entities.MerchantProduct{Status: tt.status, IsActive: tt.active, QTY: 10}
+
+			if got := available(product, tt.held); got != tt.want {
+				t.Fatalf("available() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+)
+
+const messageProviderTimeout = 15 * time.Second
+
+// MessageProvider delivers short text messages over SMS or WhatsApp.
+type MessageProvider interface {
+	Name() string
+	Send(ctx context.Context, phone, text string) error
+}
+
+// NewMessageProvider builds the provider configured for the given channel.
+func NewMessageProvider(channel string, config *configs.EnvConfig, logger *zerolog.Logger) (MessageProvider, error) {
+	provider, url, token := config.SmsProvider, config.SmsGatewayURL, config.SmsGatewayToken
+	if channel == enums.OutboxChannelWhatsApp {
+		provider, url, token = config.WhatsAppProvider, config.WhatsAppGatewayURL, config.WhatsAppGatewayToken
+	}
+
+	switch provider {
+	case "", enums.MessageProviderFake:
+		return &FakeMessageProvider{Channel: channel, Log: logger}, nil
+	case enums.MessageProviderHTTP:
+		return &HTTPMessageProvider{
+			Channel: channel,
+			URL:     url,
+			Token:   token,
+			Client:  &http.Client{Timeout: messageProviderTimeout},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown %s provider %q", channel, provider)
+}
+
+// HTTPMessageProvider posts messages as JSON to a gateway, authenticated with a bearer token.
+type HTTPMessageProvider struct {
+	Channel string
+	URL     string
+	Token   string
+	Client  *http.Client
+}
+
+func (p *HTTPMessageProvider) Name() string {
+	return p.Channel + ":" + enums.MessageProviderHTTP
+}
+
+func (p *HTTPMessageProvider) Send(ctx context.Context, phone, text string) error {
+	body, err := json.Marshal(map[string]string{
+		"channel": p.Channel,
+		"to":      common.NormalizePhone(phone),
+		"message": text,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.Token)
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("%s gateway responded with status %d", p.Channel, res.StatusCode)
+	}
+
+	return nil
+}
+
+// FakeMessage is a message recorded by the FakeMessageProvider.
+type FakeMessage struct {
+	Phone string
+	Text  string
+}
+
+// FakeMessageProvider only logs and records messages, for local development and
+// tests. Setting Fail makes every delivery fail, to exercise the email fallback.
+type FakeMessageProvider struct {
+	Channel string
+	Log     *zerolog.Logger
+	Fail    error
+
+	mu       sync.Mutex
+	messages []FakeMessage
+}
+
+func (p *FakeMessageProvider) Name() string {
+	return p.Channel + ":" + enums.MessageProviderFake
+}
+
+func (p *FakeMessageProvider) Send(ctx context.Context, phone, text string) error {
+	if p.Fail != nil {
+		return p.Fail
+	}
+
+	p.mu.Lock()
+	p.messages = append(p.messages, FakeMessage{Phone: common.NormalizePhone(phone), Text: text})
+	p.mu.Unlock()
+
+	if p.Log != nil {
+		p.Log.Info().Msg(fmt.Sprintf("fake %s to %s: %s", p.Channel, phone, text))
+	}
+
+	return nil
+}
+
+// Messages returns the messages sent so far.
+func (p *FakeMessageProvider) Messages() []FakeMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]FakeMessage(nil), p.messages...)
+}
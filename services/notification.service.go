@@ -0,0 +1,421 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// NotificationTemplate holds the title and message of a notification type per
+// locale, as text/template sources executed with the event params.
+type NotificationTemplate struct {
+	Title   map[string]string
+	Message map[string]string
+}
+
+// NotificationTemplates is the catalogue of in-app notifications.
+var NotificationTemplates = map[string]NotificationTemplate{
+	enums.NotificationRegistrationSubmitted: {
+		Title: map[string]string{
+			enums.LocaleEN: "New {{.Role}} registration",
+			enums.LocaleID: "Pendaftaran {{.Role}} baru",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "{{.Fullname}} registered as {{.Role}} and is waiting for review.",
+			enums.LocaleID: "{{.Fullname}} mendaftar sebagai {{.Role}} dan menunggu peninjauan.",
+		},
+	},
+	enums.NotificationRegistrationApproved: {
+		Title: map[string]string{
+			enums.LocaleEN: "Registration approved",
+			enums.LocaleID: "Pendaftaran disetujui",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "Your {{.Role}} registration has been approved.",
+			enums.LocaleID: "Pendaftaran {{.Role}} Anda telah disetujui.",
+		},
+	},
+	enums.NotificationRegistrationRejected: {
+		Title: map[string]string{
+			enums.LocaleEN: "Registration rejected",
+			enums.LocaleID: "Pendaftaran ditolak",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "Your {{.Role}} registration could not be approved.{{if .Note}} Note: {{.Note}}{{end}}",
+			enums.LocaleID: "Pendaftaran {{.Role}} Anda belum dapat disetujui.{{if .Note}} Catatan: {{.Note}}{{end}}",
+		},
+	},
+	enums.NotificationCampaignSubmitted: {
+		Title: map[string]string{
+			enums.LocaleEN: "New campaign submitted",
+			enums.LocaleID: "Campaign baru diajukan",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "Campaign {{.EventName}} on {{.EventDate}} is waiting for review.",
+			enums.LocaleID: "Campaign {{.EventName}} pada {{.EventDate}} menunggu peninjauan.",
+		},
+	},
+	enums.NotificationCampaignApproved: {
+		Title: map[string]string{
+			enums.LocaleEN: "Campaign approved",
+			enums.LocaleID: "Campaign disetujui",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "Your campaign {{.EventName}} has been approved.",
+			enums.LocaleID: "Campaign {{.EventName}} Anda telah disetujui.",
+		},
+	},
+	enums.NotificationCampaignRejected: {
+		Title: map[string]string{
+			enums.LocaleEN: "Campaign rejected",
+			enums.LocaleID: "Campaign ditolak",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "Your campaign {{.EventName}} could not be approved.{{if .Note}} Note: {{.Note}}{{end}}",
+			enums.LocaleID: "Campaign {{.EventName}} Anda belum dapat disetujui.{{if .Note}} Catatan: {{.Note}}{{end}}",
+		},
+	},
+	enums.NotificationCampaignStatusChanged: {
+		Title: map[string]string{
+			enums.LocaleEN: "Campaign {{.Status}}",
+			enums.LocaleID: "Campaign {{.Status}}",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "Your campaign {{.EventName}} is now {{.Status}}.{{if .Note}} {{.Note}}{{end}}",
+			enums.LocaleID: "Campaign {{.EventName}} Anda kini berstatus {{.Status}}.{{if .Note}} {{.Note}}{{end}}",
+		},
+	},
+	enums.NotificationReportSubmitted: {
+		Title: map[string]string{
+			enums.LocaleEN: "Distribution report submitted",
+			enums.LocaleID: "Laporan distribusi dikirim",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "The report of {{.EventName}} is waiting for verification.{{if not .LocationChecked}} Its location could not be checked against the campaign.{{end}}",
+			enums.LocaleID: "Laporan {{.EventName}} menunggu verifikasi.{{if not .LocationChecked}} Lokasinya tidak dapat dicocokkan dengan lokasi campaign.{{end}}",
+		},
+	},
+	enums.NotificationReportVerified: {
+		Title: map[string]string{
+			enums.LocaleEN: "Distribution report verified",
+			enums.LocaleID: "Laporan distribusi terverifikasi",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "The distribution report of {{.EventName}} has been verified and is now public.",
+			enums.LocaleID: "Laporan distribusi {{.EventName}} telah diverifikasi dan kini dapat dilihat publik.",
+		},
+	},
+	enums.NotificationReportRejected: {
+		Title: map[string]string{
+			enums.LocaleEN: "Distribution report rejected",
+			enums.LocaleID: "Laporan distribusi ditolak",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "The distribution report of {{.EventName}} was rejected, please submit it again.{{if .Note}} Note: {{.Note}}{{end}}",
+			enums.LocaleID: "Laporan distribusi {{.EventName}} ditolak, silakan kirim ulang.{{if .Note}} Catatan: {{.Note}}{{end}}",
+		},
+	},
+	enums.NotificationOrderReceived: {
+		Title: map[string]string{
+			enums.LocaleEN: "New order #{{.OrderID}}",
+			enums.LocaleID: "Pesanan baru #{{.OrderID}}",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "{{.Quantity}}x {{.ProductName}} was ordered for {{.EventName}} on {{.EventDate}}.",
+			enums.LocaleID: "{{.Quantity}}x {{.ProductName}} dipesan untuk {{.EventName}} pada {{.EventDate}}.",
+		},
+	},
+	enums.NotificationOrderStatusChanged: {
+		Title: map[string]string{
+			enums.LocaleEN: "Order #{{.OrderID}} {{.Status}}",
+			enums.LocaleID: "Pesanan #{{.OrderID}} {{.Status}}",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "The order of {{.ProductName}} for {{.EventName}} is now {{.Status}}.{{if .Note}} Note: {{.Note}}{{end}}",
+			enums.LocaleID: "Pesanan {{.ProductName}} untuk {{.EventName}} kini berstatus {{.Status}}.{{if .Note}} Catatan: {{.Note}}{{end}}",
+		},
+	},
+	enums.NotificationPayoutPaid: {
+		Title: map[string]string{
+			enums.LocaleEN: "Payout of {{.Amount}} sent",
+			enums.LocaleID: "Pembayaran {{.Amount}} terkirim",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "{{.Amount}} has been paid to your LinkAja account {{.Account}}.",
+			enums.LocaleID: "{{.Amount}} telah dibayarkan ke akun LinkAja {{.Account}}.",
+		},
+	},
+	enums.NotificationPayoutFailed: {
+		Title: map[string]string{
+			enums.LocaleEN: "Payout of {{.Amount}} failed",
+			enums.LocaleID: "Pembayaran {{.Amount}} gagal",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "The payout to LinkAja {{.Account}} failed and the amount is back in your balance.{{if .Note}} Reason: {{.Note}}{{end}}",
+			enums.LocaleID: "Pembayaran ke LinkAja {{.Account}} gagal dan dananya kembali ke saldo Anda.{{if .Note}} Alasan: {{.Note}}{{end}}",
+		},
+	},
+	enums.NotificationProductApproved: {
+		Title: map[string]string{
+			enums.LocaleEN: "Product approved",
+			enums.LocaleID: "Produk disetujui",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "{{.ProductName}} has been approved and can now be activated.",
+			enums.LocaleID: "{{.ProductName}} telah disetujui dan kini dapat diaktifkan.",
+		},
+	},
+	enums.NotificationProductRejected: {
+		Title: map[string]string{
+			enums.LocaleEN: "Product rejected",
+			enums.LocaleID: "Produk ditolak",
+		},
+		Message: map[string]string{
+			enums.LocaleEN: "{{.ProductName}} could not be approved, please update it to submit it again.{{if .Note}} Note: {{.Note}}{{end}}",
+			enums.LocaleID: "{{.ProductName}} belum dapat disetujui, silakan perbarui untuk mengajukannya kembali.{{if .Note}} Catatan: {{.Note}}{{end}}",
+		},
+	},
+}
+
+type NotificationService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewNotificationService(ctx context.Context, db *gorm.DB) *NotificationService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &NotificationService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// Push stores a notification for the account within tx, rendered in the
+// account's locale, so it only shows up once the change it reports commits.
+// The notification is also added to events for the real-time stream.
+func (service NotificationService) Push(tx *gorm.DB, events *EventBatch, oauth *entities.Oauth, event dto.NotificationEvent) error {
+	if oauth == nil {
+		return nil
+	}
+
+	notification, err := service.build(oauth, event)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Create(notification).Error; err != nil {
+		return err
+	}
+
+	events.Add(streamNotification(notification))
+
+	return nil
+}
+
+// PushRole notifies every active account with the given role, e.g. all
+// superadmins when something is waiting for review.
+func (service NotificationService) PushRole(tx *gorm.DB, events *EventBatch, role string, event dto.NotificationEvent) error {
+	var oauths []entities.Oauth
+	if err := tx.Where("role = ? AND is_active = ?", role, true).Find(&oauths).Error; err != nil {
+		return err
+	}
+
+	if len(oauths) == 0 {
+		return nil
+	}
+
+	notifications := make([]*entities.Notification, 0, len(oauths))
+	for i := range oauths {
+		notification, err := service.build(&oauths[i], event)
+		if err != nil {
+			return err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := tx.Create(&notifications).Error; err != nil {
+		return err
+	}
+
+	for _, notification := range notifications {
+		events.Add(streamNotification(notification))
+	}
+
+	return nil
+}
+
+func streamNotification(notification *entities.Notification) dto.StreamEvent {
+	return dto.StreamEvent{
+		Topic:  enums.StreamTopicNotification,
+		Type:   notification.Type,
+		UserId: notification.UserId,
+		Data:   notification,
+	}
+}
+
+func (service NotificationService) build(oauth *entities.Oauth, event dto.NotificationEvent) (*entities.Notification, error) {
+	tmpl, ok := NotificationTemplates[event.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown notification type %s", event.Type)
+	}
+
+	locale := oauth.Locale
+	if !common.IsSupportedLocale(locale) {
+		locale = enums.DefaultLocale
+	}
+
+	title, err := renderNotification(tmpl.Title[locale], event.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := renderNotification(tmpl.Message[locale], event.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.Notification{
+		UserId:  oauth.ID,
+		Type:    event.Type,
+		Title:   title,
+		Message: message,
+		RefType: event.RefType,
+		RefID:   event.RefID,
+	}, nil
+}
+
+func renderNotification(source string, params map[string]any) (string, error) {
+	tmpl, err := template.New("notification").Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, params); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(strings.ReplaceAll(out.String(), "<no value>", "")), nil
+}
+
+func (service NotificationService) GetAll(c *fiber.Ctx, userId int, pagination *common.Pagination) ([]entities.Notification, *dto.ApiError) {
+	var notifications []entities.Notification
+
+	query := service.DB.Order("created_at desc").Where("user_id = ?", userId)
+
+	switch c.Query("read") {
+	case "true":
+		query = query.Where("is_read = ?", true)
+	case "false":
+		query = query.Where("is_read = ?", false)
+	}
+
+	if kind := c.Query("type"); kind != "" {
+		query = query.Where("type = ?", kind)
+	}
+
+	query = query.Find(&notifications)
+
+	if err := query.Scopes(common.Paginate(query, entities.Notification{}, pagination)).Find(&notifications); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks one of the user's notifications as read.
+func (service NotificationService) MarkRead(userId int, id string) (*entities.Notification, *dto.ApiError) {
+	var notification entities.Notification
+	if err := service.DB.Where("id = ? AND user_id = ?", id, userId).First(&notification).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if notification.IsRead {
+		return &notification, nil
+	}
+
+	now := time.Now()
+	if err := service.DB.Model(&notification).Updates(map[string]any{
+		"is_read": true,
+		"read_at": now,
+	}).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	notification.IsRead = true
+	notification.ReadAt = &now
+
+	return &notification, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read.
+func (service NotificationService) MarkAllRead(userId int) (*dto.NotificationReadAll, *dto.ApiError) {
+	result := service.DB.Model(&entities.Notification{}).
+		Where("user_id = ? AND is_read = ?", userId, false).
+		Updates(map[string]any{
+			"is_read": true,
+			"read_at": time.Now(),
+		})
+	if result.Error != nil {
+		service.Log.Error().Msg(result.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    result.Error.Error(),
+		}
+	}
+
+	return &dto.NotificationReadAll{Updated: result.RowsAffected}, nil
+}
+
+// UnreadCount returns the number of unread notifications of the user, in total and per type.
+func (service NotificationService) UnreadCount(userId int) (*dto.NotificationCount, *dto.ApiError) {
+	var rows []struct {
+		Type  string
+		Total int64
+	}
+
+	if err := service.DB.Model(&entities.Notification{}).
+		Select("type, count(*) as total").
+		Where("user_id = ? AND is_read = ?", userId, false).
+		Group("type").
+		Scan(&rows).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	count := dto.NotificationCount{ByType: map[string]int64{}}
+	for _, row := range rows {
+		count.ByType[row.Type] = row.Total
+		count.Unread += row.Total
+	}
+
+	return &count, nil
+}
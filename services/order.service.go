@@ -0,0 +1,713 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultDeliveryTokenTTL = 24 * time.Hour
+	deliveryNonceBytes      = 16
+)
+
+type OrderService struct {
+	DB             *gorm.DB
+	Log            *zerolog.Logger
+	DeliverySecret string
+	DeliveryTTL    time.Duration
+	Ledger         *LedgerService
+	Budget         *BudgetService
+	Channel        *ChannelService
+	Notification   *NotificationService
+	Stream         *StreamService
+}
+
+func NewOrderService(ctx context.Context, db *gorm.DB) *OrderService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+
+	service := &OrderService{
+		DB:             db,
+		Log:            logger,
+		DeliverySecret: config.DeliverySecret,
+		DeliveryTTL:    config.DeliveryTokenTTL,
+		Ledger:         NewLedgerService(ctx, db),
+		Budget:         NewBudgetService(ctx, db),
+		Channel:        NewChannelService(ctx, db),
+		Notification:   NewNotificationService(ctx, db),
+		Stream:         NewStreamService(ctx),
+	}
+
+	if service.DeliverySecret == "" {
+		service.DeliverySecret = config.JWTSecret
+	}
+
+	if service.DeliveryTTL <= 0 {
+		service.DeliveryTTL = DefaultDeliveryTokenTTL
+	}
+
+	return service
+}
+
+func (service OrderService) GetAll(c *fiber.Ctx, pagination *common.Pagination) ([]entities.Order, *dto.ApiError) {
+	var orders []entities.Order
+
+	query := service.DB.
+		Preload("Campaign", unscoped).
+		Preload("MerchantProduct", unscoped).
+		Order("created_at desc")
+
+	query = filterOrders(c, service.DB, query).Find(&orders)
+
+	if err := query.Scopes(common.Paginate(query, entities.Order{}, pagination)).Find(&orders); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return orders, nil
+}
+
+// unscoped lets a preload include soft deleted records, for the history of
+// orders and donations that outlives what they were placed on.
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// openOrders tells whether any of the products is still in a waiting or
+// approved order of a campaign that is neither completed nor cancelled.
+func openOrders(tx *gorm.DB, products *gorm.DB) (bool, error) {
+	campaigns := tx.Model(&entities.Campaign{}).
+		Select("id").
+		Where("status NOT IN ?", []string{enums.CampaignStatusCompleted, enums.CampaignStatusCancelled})
+
+	var count int64
+	err := tx.Model(&entities.Order{}).
+		Where("merchant_product_id IN (?) AND campaign_id IN (?)", products, campaigns).
+		Where("order_status IN ?", []string{enums.OrderStatusWaiting, enums.OrderStatusApproved}).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// reserved returns how many of the product its open orders hold, the orders
+// openOrders looks for. They count against the stock until they are delivered,
+// rejected or their campaign ends.
+func reserved(tx *gorm.DB, productId int) (int, error) {
+	campaigns := tx.Model(&entities.Campaign{}).
+		Select("id").
+		Where("status NOT IN ?", []string{enums.CampaignStatusCompleted, enums.CampaignStatusCancelled})
+
+	var qty int
+	err := tx.Model(&entities.Order{}).
+		Select("COALESCE(SUM(qty), 0)").
+		Where("merchant_product_id = ? AND campaign_id IN (?)", productId, campaigns).
+		Where("order_status IN ?", []string{enums.OrderStatusWaiting, enums.OrderStatusApproved}).
+		Scan(&qty).Error
+
+	return qty, err
+}
+
+// filterOrders narrows query down to the orders matching the filters of the
+// order list, within the orders the caller is allowed to see.
+func filterOrders(c *fiber.Ctx, db *gorm.DB, query *gorm.DB) *gorm.DB {
+	query = scopeOrders(db, query, common.Session(c))
+
+	if campaignId := c.Query("campaign_id"); campaignId != "" {
+		query = query.Where("campaign_id = ?", campaignId)
+	}
+
+	if merchantId := c.Query("merchant_id"); merchantId != "" {
+		query = query.Where("merchant_product_id IN (?)", db.
+			Model(&entities.MerchantProduct{}).
+			Select("id").
+			Where("merchant_id = ?", merchantId))
+	}
+
+	if status := c.Query("order_status"); status != "" {
+		query = query.Where("order_status = ?", status)
+	}
+
+	return query
+}
+
+// scopeOrders restricts query to the orders of the merchant or detonator of the
+// session. Superadmins see every order, other roles none.
+func scopeOrders(db *gorm.DB, query *gorm.DB, session *dto.JWTClaims) *gorm.DB {
+	if session == nil {
+		return query.Where("1 = 0")
+	}
+
+	switch session.Role {
+	case "superadmin":
+		return query
+	case "merchant":
+		return query.Where("merchant_product_id IN (?)", db.
+			Unscoped().
+			Model(&entities.MerchantProduct{}).
+			Select("id").
+			Where("merchant_id IN (?)", db.Model(&entities.Merchant{}).Select("id").Where("user_id = ?", session.UserId)))
+	case "detonator":
+		return query.Where("campaign_id IN (?)", db.
+			Unscoped().
+			Model(&entities.Campaign{}).
+			Select("id").
+			Where("detonator_id IN (?)", db.Model(&entities.Detonator{}).Select("id").Where("user_id = ?", session.UserId)))
+	default:
+		return query.Where("1 = 0")
+	}
+}
+
+// GetByID returns an order the session is allowed to see.
+func (service OrderService) GetByID(session *dto.JWTClaims, id string) (*entities.Order, *dto.ApiError) {
+	var order entities.Order
+
+	if err := scopeOrders(service.DB, service.DB, session).
+		Preload("Campaign", unscoped).
+		Preload("MerchantProduct", unscoped).
+		Preload("MerchantProduct.Merchant", unscoped).
+		Where("id", id).
+		First(&order); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return &order, nil
+}
+
+// Create adds an order to a campaign of the detonator's. The order must fit in
+// the funds of the campaign unless an admin overrode its budget. Merchants of
+// campaigns already approved hear about the order right away.
+func (service OrderService) Create(userId int, input dto.OrderRequest) (*entities.Order, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	// the campaign row stays locked until commit so concurrent orders are
+	// checked against each other's costs
+	var campaign entities.Campaign
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Detonator").
+		First(&campaign, "id", input.CampaignID).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if campaign.Detonator == nil || campaign.Detonator.UserId != userId {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	switch campaign.Status {
+	case enums.CampaignStatusDraft, enums.CampaignStatusReview, enums.CampaignStatusFundraising, enums.CampaignStatusFunded:
+	default:
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrOrderLocked.Error(),
+		}
+	}
+
+	// the product row is locked as well so concurrent orders cannot both take
+	// the last of its stock
+	var product entities.MerchantProduct
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Merchant").
+		Preload("Merchant.Oauth").
+		First(&product, "id", input.MerchantProductID).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    err.Error(),
+		}
+	}
+
+	held, err := reserved(tx, product.ID)
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if !available(product, held+input.Qty) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrProductUnavailable.Error(),
+		}
+	}
+
+	if err := checkAvailability(tx, product.MerchantID, campaign, input.Qty); err != nil {
+		return nil, availabilityError(service.Log, err)
+	}
+
+	price, err := effectivePrice(tx, product, time.Now())
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	order := entities.Order{
+		CampaignID:        campaign.ID,
+		MerchantProductID: product.ID,
+		Qty:               input.Qty,
+		Price:             price,
+		OrderStatus:       enums.OrderStatusWaiting,
+	}
+
+	if covered, err := service.Budget.Covers(tx, &campaign, order.Total()); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	} else if !covered {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBudgetExceeded.Error(),
+		}
+	}
+
+	if err := tx.Create(&order).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	order.MerchantProduct = &product
+
+	if campaignActive(campaign.Status) {
+		if err := notifyOrderReceived(tx, events, service.Notification, service.Channel, &campaign, &order); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	events.Publish()
+
+	return &order, nil
+}
+
+// UpdateStatus lets the merchant owning the ordered product accept or reject
+// a waiting order. The detonator of the campaign is notified of the outcome.
+func (service OrderService) UpdateStatus(userId int, id string, input dto.OrderStatusRequest) (*entities.Order, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	var order entities.Order
+	if err := tx.
+		Preload("Campaign").
+		Preload("Campaign.Detonator").
+		Preload("Campaign.Detonator.Oauth").
+		Preload("MerchantProduct").
+		Preload("MerchantProduct.Merchant").
+		First(&order, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if order.MerchantProduct == nil || order.MerchantProduct.Merchant == nil || order.MerchantProduct.Merchant.UserId != userId {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	if order.OrderStatus != enums.OrderStatusWaiting {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrOrderNotWaiting.Error(),
+		}
+	}
+
+	if err := tx.Model(&order).Updates(map[string]any{
+		"order_status": input.Status,
+		"note":         input.Note,
+	}).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	order.OrderStatus = input.Status
+	order.Note = input.Note
+
+	if err := service.notifyStatusChanged(tx, events, &order); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	events.Publish()
+
+	return &order, nil
+}
+
+// deliverable tells whether the order can be handed over to the campaign.
+func deliverable(order *entities.Order) bool {
+	if order.OrderStatus != enums.OrderStatusApproved || order.Campaign == nil {
+		return false
+	}
+
+	return order.Campaign.Status == enums.CampaignStatusFunded || order.Campaign.Status == enums.CampaignStatusExecuting
+}
+
+// DeliveryToken issues the signed token the detonator shows as a QR code when
+// the merchant hands the order over. Issuing a new token invalidates the
+// previous one.
+func (service OrderService) DeliveryToken(userId int, id string) (*dto.DeliveryToken, *dto.ApiError) {
+	var order entities.Order
+	if err := service.DB.
+		Preload("Campaign").
+		Preload("Campaign.Detonator").
+		First(&order, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if order.Campaign == nil || order.Campaign.Detonator == nil || order.Campaign.Detonator.UserId != userId {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	if !deliverable(&order) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrOrderNotDeliverable.Error(),
+		}
+	}
+
+	nonce, err := common.GenerateSecret(deliveryNonceBytes)
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	expiresAt := time.Now().Add(service.DeliveryTTL)
+
+	token, err := common.SignToken(service.DeliverySecret, dto.DeliveryClaims{
+		OrderID:    order.ID,
+		CampaignID: order.CampaignID,
+		Nonce:      nonce,
+		ExpiresAt:  expiresAt.Unix(),
+	})
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := service.DB.Model(&order).Update("delivery_nonce", nonce).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &dto.DeliveryToken{
+		OrderID:   order.ID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Deliver confirms the handoff scanned by the merchant owning the ordered
+// product at the campaign site. A token is accepted once, for the campaign it
+// was issued for, as long as it is the latest one issued for the order.
+func (service OrderService) Deliver(userId int, input dto.DeliveryScanRequest) (*entities.Order, *dto.ApiError) {
+	var claims dto.DeliveryClaims
+	if err := common.VerifyToken(service.DeliverySecret, input.Token, &claims); err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrInvalidToken.Error(),
+		}
+	}
+
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDeliveryTokenExpired.Error(),
+		}
+	}
+
+	if claims.CampaignID != input.CampaignID {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDeliveryCampaign.Error(),
+		}
+	}
+
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	var order entities.Order
+	if err := tx.
+		Preload("Campaign").
+		Preload("Campaign.Detonator").
+		Preload("Campaign.Detonator.Oauth").
+		Preload("MerchantProduct").
+		Preload("MerchantProduct.Merchant").
+		First(&order, "id", claims.OrderID).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if order.MerchantProduct == nil || order.MerchantProduct.Merchant == nil || order.MerchantProduct.Merchant.UserId != userId {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrForbidden,
+			Message:    enums.ErrAccessForbidden.Error(),
+		}
+	}
+
+	if order.CampaignID != claims.CampaignID {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDeliveryCampaign.Error(),
+		}
+	}
+
+	if order.OrderStatus == enums.OrderStatusDelivered || order.DeliveryNonce != claims.Nonce {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDeliveryTokenUsed.Error(),
+		}
+	}
+
+	if !deliverable(&order) {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrOrderNotDeliverable.Error(),
+		}
+	}
+
+	now := time.Now()
+
+	// guarded on the nonce so two scans of the same token cannot both succeed
+	result := tx.Model(&entities.Order{}).
+		Where("id = ? AND order_status = ? AND delivery_nonce = ?", order.ID, enums.OrderStatusApproved, claims.Nonce).
+		Updates(map[string]any{
+			"order_status":       enums.OrderStatusDelivered,
+			"delivery_nonce":     "",
+			"delivered_at":       now,
+			"delivered_by":       userId,
+			"delivery_latitude":  *input.Latitude,
+			"delivery_longitude": *input.Longitude,
+		})
+	if result.Error != nil {
+		service.Log.Error().Msg(result.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    result.Error.Error(),
+		}
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrDeliveryTokenUsed.Error(),
+		}
+	}
+
+	order.OrderStatus = enums.OrderStatusDelivered
+	order.DeliveryNonce = ""
+	order.DeliveredAt = &now
+	order.DeliveredBy = userId
+	order.DeliveryLatitude = *input.Latitude
+	order.DeliveryLongitude = *input.Longitude
+
+	// the merchant is owed the order once its handoff is confirmed
+	if err := service.Ledger.AccrueOrder(tx, &order); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := service.notifyStatusChanged(tx, events, &order); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	events.Publish()
+
+	return &order, nil
+}
+
+// notifyOrderReceived tells the merchant about an order placed on their
+// product for a campaign that has been approved.
+func notifyOrderReceived(tx *gorm.DB, events *EventBatch, notification *NotificationService, channel *ChannelService, campaign *entities.Campaign, order *entities.Order) error {
+	product := order.MerchantProduct
+	if product == nil || product.Merchant == nil || product.Merchant.Oauth == nil {
+		return nil
+	}
+
+	oauth := product.Merchant.Oauth
+	received := dto.NotificationEvent{
+		Type:    enums.NotificationOrderReceived,
+		RefType: enums.NotificationRefOrder,
+		RefID:   order.ID,
+		Params: map[string]any{
+			"OrderID":     order.ID,
+			"ProductName": product.Name,
+			"Quantity":    order.Qty,
+			"EventName":   campaign.EventName,
+			"EventDate":   campaign.EventDate,
+		},
+	}
+
+	if err := notification.Push(tx, events, oauth, received); err != nil {
+		return err
+	}
+
+	return channel.Notify(tx, RecipientOf(oauth), dto.OrderReceivedMail{
+		MerchantName: oauth.Fullname,
+		OrderID:      order.ID,
+		EventName:    campaign.EventName,
+		EventDate:    campaign.EventDate,
+		ProductName:  product.Name,
+		Quantity:     order.Qty,
+		Address:      campaign.Address,
+	})
+}
+
+// notifyStatusChanged tells the detonator of the order's campaign about its new
+// status and streams the change to both the detonator and the merchant.
+func (service OrderService) notifyStatusChanged(tx *gorm.DB, events *EventBatch, order *entities.Order) error {
+	status := dto.OrderStatusEvent{
+		OrderID:     order.ID,
+		CampaignID:  order.CampaignID,
+		OrderStatus: order.OrderStatus,
+		Note:        order.Note,
+	}
+
+	events.Add(dto.StreamEvent{
+		Topic:  enums.StreamTopicOrder,
+		Type:   enums.NotificationOrderStatusChanged,
+		UserId: order.MerchantProduct.Merchant.UserId,
+		Data:   status,
+	})
+
+	campaign := order.Campaign
+	if campaign == nil || campaign.Detonator == nil || campaign.Detonator.Oauth == nil {
+		return nil
+	}
+
+	events.Add(dto.StreamEvent{
+		Topic:  enums.StreamTopicOrder,
+		Type:   enums.NotificationOrderStatusChanged,
+		UserId: campaign.Detonator.UserId,
+		Data:   status,
+	})
+
+	oauth := campaign.Detonator.Oauth
+	event := dto.NotificationEvent{
+		Type:    enums.NotificationOrderStatusChanged,
+		RefType: enums.NotificationRefOrder,
+		RefID:   order.ID,
+		Params: map[string]any{
+			"OrderID":     order.ID,
+			"EventName":   campaign.EventName,
+			"ProductName": order.MerchantProduct.Name,
+			"Status":      order.OrderStatus,
+			"Note":        order.Note,
+		},
+	}
+
+	if err := service.Notification.Push(tx, events, oauth, event); err != nil {
+		return err
+	}
+
+	return service.Channel.Notify(tx, RecipientOf(oauth), dto.OrderStatusChangedMail{
+		Fullname:    oauth.Fullname,
+		OrderID:     order.ID,
+		EventName:   campaign.EventName,
+		ProductName: order.MerchantProduct.Name,
+		Status:      order.OrderStatus,
+		Note:        order.Note,
+	})
+}
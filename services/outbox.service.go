@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const DefaultOutboxMaxAttempts = 5
+
+// otpCodes finds the codes in OTP payloads, on email and short messages alike.
+var otpCodes = regexp.MustCompile(`"code":"[^"]*"`)
+
+type OutboxService struct {
+	DB     *gorm.DB
+	Log    *zerolog.Logger
+	Config *configs.EnvConfig
+}
+
+func NewOutboxService(ctx context.Context, db *gorm.DB) *OutboxService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+
+	return &OutboxService{
+		DB:     db,
+		Log:    logger,
+		Config: config,
+	}
+}
+
+// Enqueue stores a message in the outbox using the given transaction, so the
+// message is only dispatched when the surrounding business change commits.
+func (service OutboxService) Enqueue(tx *gorm.DB, channel, kind, recipient, locale string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	message := entities.Outbox{
+		Channel:       channel,
+		Type:          kind,
+		Recipient:     recipient,
+		Locale:        locale,
+		Payload:       string(body),
+		Status:        enums.OutboxStatusPending,
+		MaxAttempts:   service.maxAttempts(),
+		NextAttemptAt: time.Now(),
+	}
+
+	return tx.Create(&message).Error
+}
+
+func (service OutboxService) maxAttempts() int {
+	if service.Config.OutboxMaxAttempts <= 0 {
+		return DefaultOutboxMaxAttempts
+	}
+
+	return service.Config.OutboxMaxAttempts
+}
+
+// EnqueueMail queues a transactional email rendered from the mail template
+// catalogue in the recipient's locale.
+func (service OutboxService) EnqueueMail(tx *gorm.DB, destination, locale string, data dto.MailData) error {
+	return service.Enqueue(tx, enums.OutboxChannelEmail, data.MailTemplate(), destination, locale, data)
+}
+
+func (service OutboxService) GetAll(c *fiber.Ctx, pagination *common.Pagination) ([]entities.Outbox, *dto.ApiError) {
+	var messages []entities.Outbox
+
+	query := service.DB.Order("created_at desc")
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if channel := c.Query("channel"); channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+
+	query = query.Find(&messages)
+
+	if err := query.Scopes(common.Paginate(query, entities.Outbox{}, pagination)).Find(&messages); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	for i := range messages {
+		redactOutbox(&messages[i])
+	}
+
+	return messages, nil
+}
+
+func (service OutboxService) GetByID(id string) (*entities.Outbox, *dto.ApiError) {
+	var message entities.Outbox
+
+	if err := service.DB.Where("id", id).First(&message).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	redactOutbox(&message)
+
+	return &message, nil
+}
+
+// Replay puts a dead-lettered message back in the queue with a fresh attempt budget.
+func (service OutboxService) Replay(id string) (*entities.Outbox, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	var message entities.Outbox
+	if err := tx.First(&message, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if message.Status != enums.OutboxStatusDead {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrOutboxNotReplayable.Error(),
+		}
+	}
+
+	if err := tx.Model(&message).Updates(map[string]any{
+		"status":          enums.OutboxStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+		"last_error":      "",
+	}).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	redactOutbox(&message)
+
+	return &message, nil
+}
+
+// redactOutbox masks the code of an OTP message before it is shown, the
+// stored payload is left for the dispatcher.
+func redactOutbox(message *entities.Outbox) {
+	if message.Type == (dto.OTPMail{}).MailTemplate() {
+		message.Payload = otpCodes.ReplaceAllString(message.Payload, `"code":"******"`)
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultOutboxInterval = 10 * time.Second
+	outboxBatchSize       = 50
+	outboxBaseBackoff     = 30 * time.Second
+	outboxMaxBackoff      = time.Hour
+	// outboxLease is how long a claimed message stays invisible to other
+	// dispatchers; a crashed worker's messages become eligible again after it.
+	outboxLease = 5 * time.Minute
+)
+
+// OutboxHandler delivers a single outbox message. A returned error schedules a retry.
+type OutboxHandler func(message entities.Outbox) error
+
+type OutboxDispatcher struct {
+	DB       *gorm.DB
+	Log      *zerolog.Logger
+	Interval time.Duration
+	handlers map[string]OutboxHandler
+}
+
+func NewOutboxDispatcher(ctx context.Context, db *gorm.DB) *OutboxDispatcher {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+	transport := ctx.Value(enums.MailCtxKey).(*MonitoredMailTransport)
+	template := ctx.Value(enums.TemplateCtxKey).(embed.FS)
+
+	interval := config.OutboxInterval
+	if interval <= 0 {
+		interval = DefaultOutboxInterval
+	}
+
+	dispatcher := &OutboxDispatcher{
+		DB:       db,
+		Log:      logger,
+		Interval: interval,
+		handlers: map[string]OutboxHandler{},
+	}
+
+	mailer := NewMailService(config.SmtpSender, transport, template)
+
+	providers := map[string]MessageProvider{}
+	for _, channel := range []string{enums.OutboxChannelSMS, enums.OutboxChannelWhatsApp} {
+		provider, err := NewMessageProvider(channel, config, logger)
+		if err != nil {
+			// messages for the channel end up dead-lettered until it is configured
+			logger.Error().Msg(err.Error())
+			continue
+		}
+		providers[channel] = provider
+	}
+
+	// every catalogue template is delivered by the same handler per channel,
+	// decoding the payload into the template's own data type
+	for _, tmpl := range MailTemplates {
+		dataType := reflect.TypeOf(tmpl.Sample)
+
+		dispatcher.Handle(enums.OutboxChannelEmail, tmpl.Sample.MailTemplate(), func(message entities.Outbox) error {
+			data, err := decodeMailData(dataType, []byte(message.Payload))
+			if err != nil {
+				return err
+			}
+
+			return mailer.Send(message.Recipient, message.Locale, data)
+		})
+
+		if !tmpl.ShortMessage {
+			continue
+		}
+
+		for channel, provider := range providers {
+			dispatcher.Handle(channel, tmpl.Sample.MailTemplate(), dispatcher.shortMessageHandler(provider, mailer, dataType))
+		}
+	}
+
+	webhooks := NewWebhookService(ctx, db)
+	for _, event := range enums.WebhookEvents {
+		dispatcher.Handle(enums.OutboxChannelWebhook, event, webhooks.Deliver)
+	}
+
+	return dispatcher
+}
+
+// shortMessageHandler sends the SMS/WhatsApp version of a template and falls
+// back to the regular email when the provider fails.
+func (dispatcher *OutboxDispatcher) shortMessageHandler(provider MessageProvider, mailer *MailService, dataType reflect.Type) OutboxHandler {
+	return func(message entities.Outbox) error {
+		var payload struct {
+			FallbackEmail string          `json:"fallback_email"`
+			Data          json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+			return err
+		}
+
+		data, err := decodeMailData(dataType, payload.Data)
+		if err != nil {
+			return err
+		}
+
+		text, err := mailer.RenderShortMessage(message.Locale, data)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), messageProviderTimeout)
+		defer cancel()
+
+		err = provider.Send(ctx, message.Recipient, text)
+		if err == nil || payload.FallbackEmail == "" {
+			return err
+		}
+
+		dispatcher.Log.Error().Msg(fmt.Sprintf("outbox message %d via %s failed, falling back to email: %v", message.ID, provider.Name(), err))
+
+		return mailer.Send(payload.FallbackEmail, message.Locale, data)
+	}
+}
+
+// decodeMailData unmarshals a payload into a new value of the template data type.
+func decodeMailData(dataType reflect.Type, payload []byte) (dto.MailData, error) {
+	data := reflect.New(dataType)
+	if err := json.Unmarshal(payload, data.Interface()); err != nil {
+		return nil, err
+	}
+
+	return data.Elem().Interface().(dto.MailData), nil
+}
+
+// Handle registers the handler used to deliver messages of the given channel and type.
+func (dispatcher *OutboxDispatcher) Handle(channel, kind string, handler OutboxHandler) {
+	dispatcher.handlers[channel+":"+kind] = handler
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (dispatcher *OutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(dispatcher.Interval)
+	defer ticker.Stop()
+
+	for {
+		dispatcher.dispatch()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (dispatcher *OutboxDispatcher) dispatch() {
+	var messages []entities.Outbox
+
+	if err := dispatcher.DB.
+		Where("status IN ?", []string{enums.OutboxStatusPending, enums.OutboxStatusProcessing}).
+		Where("next_attempt_at <= ?", time.Now()).
+		Order("next_attempt_at asc").
+		Limit(outboxBatchSize).
+		Find(&messages).Error; err != nil {
+		dispatcher.Log.Error().Msg(err.Error())
+		return
+	}
+
+	for _, message := range messages {
+		if !dispatcher.claim(&message) {
+			continue
+		}
+
+		dispatcher.deliver(message)
+	}
+}
+
+// claim marks the message as processing, guarding on next_attempt_at so only
+// one dispatcher wins when several instances poll the same table.
+func (dispatcher *OutboxDispatcher) claim(message *entities.Outbox) bool {
+	leaseUntil := time.Now().Add(outboxLease)
+
+	result := dispatcher.DB.Model(&entities.Outbox{}).
+		Where("id = ? AND next_attempt_at = ?", message.ID, message.NextAttemptAt).
+		Updates(map[string]any{
+			"status":          enums.OutboxStatusProcessing,
+			"next_attempt_at": leaseUntil,
+		})
+	if result.Error != nil {
+		dispatcher.Log.Error().Msg(result.Error.Error())
+		return false
+	}
+
+	return result.RowsAffected == 1
+}
+
+func (dispatcher *OutboxDispatcher) deliver(message entities.Outbox) {
+	err := dispatcher.invoke(message)
+
+	if err == nil {
+		now := time.Now()
+		if err := dispatcher.DB.Model(&message).Updates(map[string]any{
+			"status":     enums.OutboxStatusSent,
+			"attempts":   message.Attempts + 1,
+			"sent_at":    &now,
+			"last_error": "",
+		}).Error; err != nil {
+			dispatcher.Log.Error().Msg(err.Error())
+		}
+		return
+	}
+
+	attempts := message.Attempts + 1
+	dispatcher.Log.Error().Msg(fmt.Sprintf("outbox message %d attempt %d failed: %v", message.ID, attempts, err))
+
+	update := map[string]any{
+		"status":          enums.OutboxStatusPending,
+		"attempts":        attempts,
+		"next_attempt_at": time.Now().Add(outboxBackoff(attempts)),
+		"last_error":      err.Error(),
+	}
+
+	if attempts >= message.MaxAttempts {
+		update["status"] = enums.OutboxStatusDead
+	}
+
+	if err := dispatcher.DB.Model(&message).Updates(update).Error; err != nil {
+		dispatcher.Log.Error().Msg(err.Error())
+	}
+}
+
+// invoke runs the registered handler, turning a panic into an error so a
+// misconfigured transport cannot take the dispatcher down.
+func (dispatcher *OutboxDispatcher) invoke(message entities.Outbox) (err error) {
+	handler, ok := dispatcher.handlers[message.Channel+":"+message.Type]
+	if !ok {
+		return fmt.Errorf("no handler registered for %s:%s", message.Channel, message.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	return handler(message)
+}
+
+// outboxBackoff doubles the wait after each failed attempt, capped at outboxMaxBackoff.
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+
+	return backoff
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"foodia-be/dto"
+	"foodia-be/entities"
+)
+
+func TestRedactOutbox(t *testing.T) {
+	payload := func(data any) string {
+		body, err := json.Marshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	otp := dto.OTPMail{Code: "482913"}
+	welcome := dto.WelcomeMail{Fullname: "Siti"}
+
+	tests := []struct {
+		name    string
+		message entities.Outbox
+		hidden  string
+		kept    string
+	}{
+		{"otp email", entities.Outbox{Type: otp.MailTemplate(), Payload: payload(otp)}, "482913", `"code":"******"`},
+		{"otp short message", entities.Outbox{Type: otp.MailTemplate(), Payload: payload(dto.ShortMessagePayload{FallbackEmail: "siti@example.com", Data: otp})}, "482913", "siti@example.com"},
+		{"other mail", entities.Outbox{Type: welcome.MailTemplate(), Payload: payload(welcome)}, "", "Siti"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redactOutbox(&tt.message)
+
+			if tt.hidden != "" && strings.Contains(tt.message.Payload, tt.hidden) {
+				t.Fatalf("payload %s still shows %s", tt.message.Payload, tt.hidden)
+			}
+
+			if !strings.Contains(tt.message.Payload, tt.kept) {
+				t.Fatalf("payload %s lost %s", tt.message.Payload, tt.kept)
+			}
+		})
+	}
+}
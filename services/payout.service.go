@@ -0,0 +1,488 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const DefaultPayoutMinAmount = 10000
+
+type PayoutService struct {
+	DB           *gorm.DB
+	Log          *zerolog.Logger
+	MinAmount    decimal.Decimal
+	Provider     PayoutProvider
+	Ledger       *LedgerService
+	Notification *NotificationService
+	Stream       *StreamService
+}
+
+func NewPayoutService(ctx context.Context, db *gorm.DB) *PayoutService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+
+	minAmount := config.PayoutMinAmount
+	if minAmount <= 0 {
+		minAmount = DefaultPayoutMinAmount
+	}
+
+	return &PayoutService{
+		DB:           db,
+		Log:          logger,
+		MinAmount:    decimal.NewFromFloat(minAmount),
+		Provider:     ctx.Value(enums.PayoutCtxKey).(PayoutProvider),
+		Ledger:       NewLedgerService(ctx, db),
+		Notification: NewNotificationService(ctx, db),
+		Stream:       NewStreamService(ctx),
+	}
+}
+
+// CreateBatch pays out the payable balance of every approved merchant with a
+// LinkAja number, or of the given merchants only. The balances move to the
+// payout clearing account until the provider confirms the transfer.
+func (service PayoutService) CreateBatch(userId int, input dto.PayoutBatchRequest) (*entities.PayoutBatch, *dto.ApiError) {
+	minAmount := service.MinAmount
+	if input.MinAmount > 0 {
+		minAmount = decimal.NewFromFloat(input.MinAmount)
+	}
+
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	query := tx.Where("status = ? AND no_link_aja <> ?", "approved", "")
+	if len(input.MerchantIDs) > 0 {
+		query = query.Where("id IN ?", input.MerchantIDs)
+	}
+
+	// the merchants stay locked until the batch is stored, so a concurrent
+	// batch waits and then sees the balances already moved to clearing
+	var merchants []entities.Merchant
+	if err := query.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&merchants).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	merchantIds := make([]int, 0, len(merchants))
+	for _, merchant := range merchants {
+		merchantIds = append(merchantIds, merchant.ID)
+	}
+
+	balances := map[int]decimal.Decimal{}
+	if len(merchantIds) > 0 {
+		var err error
+		if balances, err = service.Ledger.Balances(tx, enums.LedgerAccountMerchantPayable, merchantIds...); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	batch := entities.PayoutBatch{
+		Status:    enums.PayoutBatchProcessing,
+		Provider:  service.Provider.Name(),
+		Total:     decimal.Zero,
+		CreatedBy: userId,
+	}
+
+	for _, merchant := range merchants {
+		balance := balances[merchant.ID]
+		if !balance.IsPositive() || balance.LessThan(minAmount) {
+			continue
+		}
+
+		batch.Payouts = append(batch.Payouts, entities.Payout{
+			MerchantID: merchant.ID,
+			Account:    merchant.NoLinkAja,
+			Amount:     balance,
+			Status:     enums.PayoutStatusPending,
+		})
+		batch.Total = batch.Total.Add(balance)
+		batch.Count++
+	}
+
+	if batch.Count == 0 {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrPayoutNothingDue.Error(),
+		}
+	}
+
+	if err := tx.Create(&batch).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	for _, payout := range batch.Payouts {
+		if err := service.Ledger.Post(tx, enums.LedgerPayout, enums.LedgerRefPayout, payout.ID,
+			fmt.Sprintf("payout #%d to LinkAja %s", payout.ID, payout.Account),
+			transfer(payout.MerchantID, enums.LedgerAccountMerchantPayable, enums.LedgerAccountPayoutClearing, payout.Amount)...,
+		); err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.Process(fmt.Sprint(batch.ID))
+}
+
+// Process sends the pending payouts of the batch to the provider and checks
+// the ones still processing. Payouts that could not reach the provider stay
+// pending so the batch can be processed again.
+func (service PayoutService) Process(id string) (*entities.PayoutBatch, *dto.ApiError) {
+	batch, fail := service.GetBatch(id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	for _, payout := range batch.Payouts {
+		var (
+			result dto.PayoutResult
+			err    error
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), payoutProviderTimeout)
+
+		switch payout.Status {
+		case enums.PayoutStatusPending:
+			// the reference stays the same across attempts so the provider
+			// can refuse a transfer it already received
+			result, err = service.Provider.Disburse(ctx, dto.PayoutTransfer{
+				Reference: fmt.Sprintf("FOODIA-PAYOUT-%d", payout.ID),
+				Account:   payout.Account,
+				Amount:    payout.Amount,
+				Note:      fmt.Sprintf("Foodia payout #%d", payout.ID),
+			})
+		case enums.PayoutStatusProcessing:
+			result, err = service.Provider.Status(ctx, payout.Reference)
+		default:
+			cancel()
+			continue
+		}
+
+		cancel()
+
+		if err := service.apply(payout, result, err); err != nil {
+			service.Log.Error().Msg(err.Error())
+		}
+	}
+
+	if err := service.settleBatch(batch.ID); err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetBatch(id)
+}
+
+// apply stores what the provider reported for a payout. A paid payout leaves
+// the clearing account, a failed one goes back to the merchant's balance.
+func (service PayoutService) apply(payout entities.Payout, result dto.PayoutResult, sendErr error) error {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	events := service.Stream.Batch()
+
+	update := map[string]any{
+		"attempts": gorm.Expr("attempts + 1"),
+	}
+
+	if sendErr != nil {
+		update["error"] = sendErr.Error()
+		result.Status = payout.Status
+	} else {
+		update["status"] = result.Status
+		update["error"] = result.Message
+		if result.Reference != "" {
+			update["reference"] = result.Reference
+		}
+	}
+
+	now := time.Now()
+	if result.Status == enums.PayoutStatusPaid {
+		update["paid_at"] = now
+	}
+
+	// guarded on the status read before calling the provider so a concurrent
+	// run cannot book the same outcome twice
+	updated := tx.Model(&entities.Payout{}).
+		Where("id = ? AND status = ?", payout.ID, payout.Status).
+		Updates(update)
+	if updated.Error != nil {
+		return updated.Error
+	}
+
+	if updated.RowsAffected == 0 {
+		return nil
+	}
+
+	kind, description, entries := payoutMovement(payout, result)
+	if kind == "" {
+		return tx.Commit().Error
+	}
+
+	if err := service.Ledger.Post(tx, kind, enums.LedgerRefPayout, payout.ID, description, entries...); err != nil {
+		return err
+	}
+
+	if err := service.notify(tx, events, payout, result); err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	events.Publish()
+
+	return nil
+}
+
+// notify tells the merchant about the outcome of the payout.
+func (service PayoutService) notify(tx *gorm.DB, events *EventBatch, payout entities.Payout, result dto.PayoutResult) error {
+	var merchant entities.Merchant
+	if err := tx.Preload("Oauth").First(&merchant, "id", payout.MerchantID).Error; err != nil {
+		return err
+	}
+
+	if merchant.Oauth == nil {
+		return nil
+	}
+
+	event := dto.NotificationEvent{
+		Type:    enums.NotificationPayoutPaid,
+		RefType: enums.NotificationRefPayout,
+		RefID:   payout.ID,
+		Params: map[string]any{
+			"Amount":  common.FormatRupiah(payout.Amount),
+			"Account": payout.Account,
+			"Note":    result.Message,
+		},
+	}
+
+	if result.Status == enums.PayoutStatusFailed {
+		event.Type = enums.NotificationPayoutFailed
+	}
+
+	return service.Notification.Push(tx, events, merchant.Oauth, event)
+}
+
+// settleBatch derives the batch status from its payouts.
+func (service PayoutService) settleBatch(id int) error {
+	var counts []struct {
+		Status string
+		Total  int
+	}
+
+	if err := service.DB.Model(&entities.Payout{}).
+		Select("status, COUNT(*) AS total").
+		Where("batch_id = ?", id).
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+
+	byStatus := map[string]int{}
+	for _, count := range counts {
+		byStatus[count.Status] = count.Total
+	}
+
+	return service.DB.Model(&entities.PayoutBatch{}).
+		Where("id = ?", id).
+		Update("status", batchStatus(byStatus)).Error
+}
+
+// payoutMovement returns the ledger transaction booking the outcome of a
+// payout: a paid payout leaves the clearing account, a failed one goes back to
+// the merchant's balance. Payouts still in progress book nothing.
+func payoutMovement(payout entities.Payout, result dto.PayoutResult) (string, string, []entities.LedgerEntry) {
+	switch result.Status {
+	case enums.PayoutStatusPaid:
+		return enums.LedgerPayoutSettled,
+			fmt.Sprintf("payout #%d paid, reference %s", payout.ID, result.Reference),
+			transfer(payout.MerchantID, enums.LedgerAccountPayoutClearing, enums.LedgerAccountLinkAja, payout.Amount)
+	case enums.PayoutStatusFailed:
+		return enums.LedgerPayoutReversed,
+			fmt.Sprintf("payout #%d failed: %s", payout.ID, result.Message),
+			transfer(payout.MerchantID, enums.LedgerAccountPayoutClearing, enums.LedgerAccountMerchantPayable, payout.Amount)
+	}
+
+	return "", "", nil
+}
+
+// batchStatus works out the status of a batch from the number of its payouts
+// in each status.
+func batchStatus(byStatus map[string]int) string {
+	all := 0
+	for _, count := range byStatus {
+		all += count
+	}
+
+	switch {
+	case byStatus[enums.PayoutStatusPending]+byStatus[enums.PayoutStatusProcessing] > 0:
+		return enums.PayoutBatchProcessing
+	case byStatus[enums.PayoutStatusFailed] == all:
+		return enums.PayoutBatchFailed
+	case byStatus[enums.PayoutStatusFailed] > 0:
+		return enums.PayoutBatchPartial
+	}
+
+	return enums.PayoutBatchCompleted
+}
+
+func (service PayoutService) GetBatches(c *fiber.Ctx, pagination *common.Pagination) ([]entities.PayoutBatch, *dto.ApiError) {
+	var batches []entities.PayoutBatch
+
+	query := service.DB.Order("created_at desc")
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query = query.Find(&batches)
+
+	if err := query.Scopes(common.Paginate(query, entities.PayoutBatch{}, pagination)).Find(&batches); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return batches, nil
+}
+
+func (service PayoutService) GetBatch(id string) (*entities.PayoutBatch, *dto.ApiError) {
+	var batch entities.PayoutBatch
+
+	if err := service.DB.
+		Preload("Payouts").
+		Preload("Payouts.Merchant").
+		Where("id", id).
+		First(&batch); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return &batch, nil
+}
+
+// GetPayouts lists payouts; merchantId limits them to one merchant.
+func (service PayoutService) GetPayouts(c *fiber.Ctx, merchantId int, pagination *common.Pagination) ([]entities.Payout, *dto.ApiError) {
+	var payouts []entities.Payout
+
+	query := service.DB.Order("created_at desc")
+
+	if merchantId != 0 {
+		query = query.Where("merchant_id = ?", merchantId)
+	} else if filter := c.Query("merchant_id"); filter != "" {
+		query = query.Where("merchant_id = ?", filter)
+	}
+
+	if batchId := c.Query("batch_id"); batchId != "" {
+		query = query.Where("batch_id = ?", batchId)
+	}
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query = query.Find(&payouts)
+
+	if err := query.Scopes(common.Paginate(query, entities.Payout{}, pagination)).Find(&payouts); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return payouts, nil
+}
+
+// MerchantOf returns the merchant registered by the user.
+func (service PayoutService) MerchantOf(userId int) (*entities.Merchant, *dto.ApiError) {
+	var merchant entities.Merchant
+
+	if err := service.DB.Where("user_id = ?", userId).First(&merchant).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &merchant, nil
+}
+
+// Statement is the merchant statement for ?from=&to= (YYYY-MM-DD, WIB),
+// the current month by default.
+func (service PayoutService) Statement(c *fiber.Ctx, merchantId int) (*dto.MerchantStatement, *dto.ApiError) {
+	location, _ := common.Timezone(enums.TimezoneWIB)
+	now := time.Now().In(location)
+
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+	to := from.AddDate(0, 1, 0)
+
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    err.Error(),
+			}
+		}
+		from = parsed
+	}
+
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, location)
+		if err != nil {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    err.Error(),
+			}
+		}
+		// the end date is included
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	return service.Ledger.Statement(merchantId, from, to)
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+)
+
+const payoutProviderTimeout = 30 * time.Second
+
+// PayoutProvider disburses merchant payouts to their LinkAja account.
+type PayoutProvider interface {
+	Name() string
+	Disburse(ctx context.Context, transfer dto.PayoutTransfer) (dto.PayoutResult, error)
+	Status(ctx context.Context, reference string) (dto.PayoutResult, error)
+}
+
+// NewPayoutProvider builds the provider configured with PAYOUT_PROVIDER. The
+// fake provider pays nothing for real, so it has to be chosen explicitly.
+func NewPayoutProvider(config *configs.EnvConfig, logger *zerolog.Logger) (PayoutProvider, error) {
+	switch config.PayoutProvider {
+	case "":
+		return nil, enums.ErrPayoutProviderMissing
+	case enums.PayoutProviderFake:
+		return &FakePayoutProvider{Log: logger}, nil
+	case enums.PayoutProviderLinkAja:
+		return &LinkAjaPayoutProvider{
+			URL:    strings.TrimSuffix(config.LinkAjaURL, "/"),
+			Token:  config.LinkAjaToken,
+			Client: &http.Client{Timeout: payoutProviderTimeout},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown payout provider %q", config.PayoutProvider)
+}
+
+// LinkAjaPayoutProvider talks to the LinkAja disbursement API, authenticated
+// with a bearer token.
+type LinkAjaPayoutProvider struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+type linkAjaDisbursement struct {
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+}
+
+func (p *LinkAjaPayoutProvider) Name() string {
+	return enums.PayoutProviderLinkAja
+}
+
+func (p *LinkAjaPayoutProvider) Disburse(ctx context.Context, transfer dto.PayoutTransfer) (dto.PayoutResult, error) {
+	body, err := json.Marshal(map[string]any{
+		"partner_reference": transfer.Reference,
+		"msisdn":            common.NormalizePhone(transfer.Account),
+		"amount":            transfer.Amount.StringFixed(0),
+		"note":              transfer.Note,
+	})
+	if err != nil {
+		return dto.PayoutResult{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL+"/disbursements", bytes.NewReader(body))
+	if err != nil {
+		return dto.PayoutResult{}, err
+	}
+
+	result, err := p.do(req)
+
+	// the transfer was already received on an earlier attempt, its outcome
+	// is whatever LinkAja recorded for it
+	if errors.Is(err, errPayoutDuplicate) {
+		return p.Status(ctx, transfer.Reference)
+	}
+
+	return result, err
+}
+
+func (p *LinkAjaPayoutProvider) Status(ctx context.Context, reference string) (dto.PayoutResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL+"/disbursements/"+url.PathEscape(reference), nil)
+	if err != nil {
+		return dto.PayoutResult{}, err
+	}
+
+	return p.do(req)
+}
+
+func (p *LinkAjaPayoutProvider) do(req *http.Request) (dto.PayoutResult, error) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.Token)
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return dto.PayoutResult{}, err
+	}
+	defer res.Body.Close()
+
+	var disbursement linkAjaDisbursement
+	if err := json.NewDecoder(res.Body).Decode(&disbursement); err != nil && res.StatusCode < 300 {
+		return dto.PayoutResult{}, err
+	}
+
+	return linkAjaResult(res.StatusCode, disbursement)
+}
+
+// errPayoutDuplicate tells that LinkAja already has a transfer with the reference.
+var errPayoutDuplicate = errors.New("linkaja already received a transfer with this reference")
+
+// linkAjaResult turns a LinkAja response into the payout outcome. Only a
+// request LinkAja rejected outright is a failed payout; a duplicate means the
+// transfer has to be looked up, anything else is retried later.
+func linkAjaResult(statusCode int, disbursement linkAjaDisbursement) (dto.PayoutResult, error) {
+	switch {
+	case statusCode == http.StatusConflict:
+		return dto.PayoutResult{}, errPayoutDuplicate
+	case statusCode == http.StatusBadRequest, statusCode == http.StatusUnprocessableEntity:
+		return dto.PayoutResult{Status: enums.PayoutStatusFailed, Message: disbursement.Message}, nil
+	case statusCode >= 300:
+		return dto.PayoutResult{}, fmt.Errorf("linkaja responded with status %d", statusCode)
+	}
+
+	result := dto.PayoutResult{Reference: disbursement.Reference, Message: disbursement.Message}
+
+	switch strings.ToLower(disbursement.Status) {
+	case "success", "succeeded", "completed":
+		result.Status = enums.PayoutStatusPaid
+	case "failed", "rejected", "cancelled":
+		result.Status = enums.PayoutStatusFailed
+	default:
+		result.Status = enums.PayoutStatusProcessing
+	}
+
+	return result, nil
+}
+
+// FakePayoutProvider pays every transfer right away, for local development.
+// Setting Fail makes every transfer fail instead.
+type FakePayoutProvider struct {
+	Log  *zerolog.Logger
+	Fail string
+
+	mu        sync.Mutex
+	transfers []dto.PayoutTransfer
+}
+
+func (p *FakePayoutProvider) Name() string {
+	return enums.PayoutProviderFake
+}
+
+func (p *FakePayoutProvider) Disburse(ctx context.Context, transfer dto.PayoutTransfer) (dto.PayoutResult, error) {
+	if p.Fail != "" {
+		return dto.PayoutResult{Status: enums.PayoutStatusFailed, Message: p.Fail}, nil
+	}
+
+	p.mu.Lock()
+	p.transfers = append(p.transfers, transfer)
+	p.mu.Unlock()
+
+	if p.Log != nil {
+		p.Log.Info().Msg(fmt.Sprintf("fake payout %s of %s to %s", transfer.Reference, common.FormatRupiah(transfer.Amount), transfer.Account))
+	}
+
+	return dto.PayoutResult{Reference: "FAKE-" + transfer.Reference, Status: enums.PayoutStatusPaid}, nil
+}
+
+func (p *FakePayoutProvider) Status(ctx context.Context, reference string) (dto.PayoutResult, error) {
+	return dto.PayoutResult{Reference: reference, Status: enums.PayoutStatusPaid}, nil
+}
+
+// Transfers returns the transfers paid so far.
+func (p *FakePayoutProvider) Transfers() []dto.PayoutTransfer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]dto.PayoutTransfer(nil), p.transfers...)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLinkAjaResult(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		disbursement linkAjaDisbursement
+		want         string
+		wantErr      error
+		retried      bool
+	}{
+		{"success", http.StatusOK, linkAjaDisbursement{Reference: "LA-1", Status: "success"}, enums.PayoutStatusPaid, nil, false},
+		{"completed", http.StatusOK, linkAjaDisbursement{Reference: "LA-1", Status: "COMPLETED"}, enums.PayoutStatusPaid, nil, false},
+		{"rejected by linkaja", http.StatusOK, linkAjaDisbursement{Status: "rejected"}, enums.PayoutStatusFailed, nil, false},
+		{"still pending", http.StatusAccepted, linkAjaDisbursement{Status: "pending"}, enums.PayoutStatusProcessing, nil, false},
+		{"unknown status", http.StatusOK, linkAjaDisbursement{}, enums.PayoutStatusProcessing, nil, false},
+		{"bad request", http.StatusBadRequest, linkAjaDisbursement{Message: "invalid msisdn"}, enums.PayoutStatusFailed, nil, false},
+		{"unprocessable", http.StatusUnprocessableEntity, linkAjaDisbursement{}, enums.PayoutStatusFailed, nil, false},
+		{"duplicate reference", http.StatusConflict, linkAjaDisbursement{}, "", errPayoutDuplicate, false},
+		{"unauthorized", http.StatusUnauthorized, linkAjaDisbursement{}, "", nil, true},
+		{"rate limited", http.StatusTooManyRequests, linkAjaDisbursement{}, "", nil, true},
+		{"server error", http.StatusBadGateway, linkAjaDisbursement{}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := linkAjaResult(tt.statusCode, tt.disbursement)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.retried:
+				if err == nil {
+					t.Fatalf("expected an error so the payout is retried, got %+v", result)
+				}
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case result.Status != tt.want:
+				t.Fatalf("status = %q, want %q", result.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayoutMovement(t *testing.T) {
+	payout := entities.Payout{ID: 7, MerchantID: 3, Amount: decimal.NewFromInt(150000)}
+
+	tests := []struct {
+		name   string
+		status string
+		kind   string
+		from   string
+		to     string
+	}{
+		{"paid", enums.PayoutStatusPaid, enums.LedgerPayoutSettled, enums.LedgerAccountPayoutClearing, enums.LedgerAccountLinkAja},
+		{"failed", enums.PayoutStatusFailed, enums.LedgerPayoutReversed, enums.LedgerAccountPayoutClearing, enums.LedgerAccountMerchantPayable},
+		{"processing", enums.PayoutStatusProcessing, "", "", ""},
+		{"pending", enums.PayoutStatusPending, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, _, entries := payoutMovement(payout, dto.PayoutResult{Status: tt.status})
+
+			if kind != tt.kind {
+				t.Fatalf("kind = %q, want %q", kind, tt.kind)
+			}
+
+			if tt.kind == "" {
+				if len(entries) != 0 {
+					t.Fatalf("expected no entries, got %+v", entries)
+				}
+				return
+			}
+
+			if len(entries) != 2 {
+				t.Fatalf("expected 2 entries, got %+v", entries)
+			}
+
+			debit, credit := entries[0], entries[1]
+			if debit.Account != tt.from || credit.Account != tt.to {
+				t.Fatalf("moves %s -> %s, want %s -> %s", debit.Account, credit.Account, tt.from, tt.to)
+			}
+
+			if !debit.Debit.Equal(payout.Amount) || !credit.Credit.Equal(payout.Amount) {
+				t.Fatalf("entries do not move the payout amount: %+v", entries)
+			}
+
+			if debit.MerchantID != payout.MerchantID || credit.MerchantID != payout.MerchantID {
+				t.Fatalf("entries are not booked on the merchant: %+v", entries)
+			}
+		})
+	}
+}
+
+func TestBatchStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		byStatus map[string]int
+		want     string
+	}{
+		{"all paid", map[string]int{enums.PayoutStatusPaid: 3}, enums.PayoutBatchCompleted},
+		{"pending left", map[string]int{enums.PayoutStatusPaid: 2, enums.PayoutStatusPending: 1}, enums.PayoutBatchProcessing},
+		{"processing left", map[string]int{enums.PayoutStatusFailed: 2, enums.PayoutStatusProcessing: 1}, enums.PayoutBatchProcessing},
+		{"all failed", map[string]int{enums.PayoutStatusFailed: 2}, enums.PayoutBatchFailed},
+		{"some failed", map[string]int{enums.PayoutStatusPaid: 2, enums.PayoutStatusFailed: 1}, enums.PayoutBatchPartial},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batchStatus(tt.byStatus); got != tt.want {
+				t.Fatalf("batchStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
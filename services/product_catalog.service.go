@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ProductCatalogService is the public product catalogue detonators assemble
+// their campaign orders from, with the categories products are sorted in.
+type ProductCatalogService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewProductCatalogService(ctx context.Context, db *gorm.DB) *ProductCatalogService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &ProductCatalogService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+func slugOf(name string) string {
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func (service ProductCatalogService) CreateCategory(input dto.ProductCategoryRequest) (*entities.ProductCategory, *dto.ApiError) {
+	category := entities.ProductCategory{
+		Name:        input.Name,
+		Slug:        slugOf(input.Name),
+		Description: input.Description,
+	}
+
+	if fail := service.checkSlug(category.Slug, 0); fail != nil {
+		return nil, fail
+	}
+
+	if err := service.DB.Create(&category).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &category, nil
+}
+
+func (service ProductCatalogService) GetCategories() ([]entities.ProductCategory, *dto.ApiError) {
+	categories := []entities.ProductCategory{}
+
+	if err := service.DB.Order("name").Find(&categories).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return categories, nil
+}
+
+func (service ProductCatalogService) GetCategory(id string) (*entities.ProductCategory, *dto.ApiError) {
+	var category entities.ProductCategory
+
+	if err := service.DB.Where("id", id).First(&category).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &category, nil
+}
+
+func (service ProductCatalogService) UpdateCategory(id string, input dto.ProductCategoryRequest) (*entities.ProductCategory, *dto.ApiError) {
+	category, fail := service.GetCategory(id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	slug := slugOf(input.Name)
+	if fail := service.checkSlug(slug, category.ID); fail != nil {
+		return nil, fail
+	}
+
+	update := map[string]any{
+		"name":        input.Name,
+		"slug":        slug,
+		"description": input.Description,
+	}
+
+	if err := service.DB.Model(category).Updates(update).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetCategory(id)
+}
+
+// DeleteCategory removes the category, leaving its products uncategorised.
+func (service ProductCatalogService) DeleteCategory(id string) *dto.ApiError {
+	category, fail := service.GetCategory(id)
+	if fail != nil {
+		return fail
+	}
+
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	if err := tx.Model(&entities.MerchantProduct{}).
+		Where("category_id = ?", category.ID).
+		Update("category_id", nil).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Delete(category).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// checkSlug makes sure no other category than the one of id has the slug.
+func (service ProductCatalogService) checkSlug(slug string, id int) *dto.ApiError {
+	var count int64
+	if err := service.DB.Model(&entities.ProductCategory{}).
+		Where("slug = ? AND id <> ?", slug, id).
+		Count(&count).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if count > 0 {
+		return &dto.ApiError{
+			StatusCode: fiber.ErrConflict,
+			Message:    enums.ErrCategoryExists.Error(),
+		}
+	}
+
+	return nil
+}
+
+// Search lists the approved and active products of approved merchants that
+// are in stock, narrowed down by the filters of filterProducts.
+func (service ProductCatalogService) Search(c *fiber.Ctx, pagination *common.Pagination) ([]entities.MerchantProduct, *dto.ApiError) {
+	var products []entities.MerchantProduct
+
+	query, err := filterProducts(c, service.DB, service.DB.
+		Preload("MerchantProductImage").
+		Preload("Category").
+		Preload("Attributes").
+		Order("created_at desc"))
+	if err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBadParamInput.Error(),
+		}
+	}
+
+	query = query.Find(&products)
+
+	if err := query.Scopes(common.Paginate(query, entities.MerchantProduct{}, pagination)).Find(&products); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return products, nil
+}
+
+// Facets counts the products found by a search with the same filters per
+// category, tag, dietary and allergen attribute and packaging.
+func (service ProductCatalogService) Facets(c *fiber.Ctx) (*dto.ProductFacets, *dto.ApiError) {
+	products, err := filterProducts(c, service.DB, service.DB.Model(&entities.MerchantProduct{}).Select("merchant_products.id"))
+	if err != nil {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrBadParamInput.Error(),
+		}
+	}
+
+	facets := dto.ProductFacets{
+		Categories: []dto.CategoryFacet{},
+		Packaging:  []dto.FacetCount{},
+	}
+
+	if err := service.DB.Model(&entities.MerchantProduct{}).
+		Select("product_categories.id, product_categories.name, COUNT(*) AS total").
+		Joins("JOIN product_categories ON product_categories.id = merchant_products.category_id").
+		Where("merchant_products.id IN (?)", products).
+		Group("product_categories.id, product_categories.name").
+		Order("total desc, product_categories.name").
+		Scan(&facets.Categories).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := service.DB.Model(&entities.MerchantProduct{}).
+		Select("packaging AS value, COUNT(*) AS total").
+		Where("id IN (?) AND packaging <> ''", products).
+		Group("packaging").
+		Order("total desc, packaging").
+		Scan(&facets.Packaging).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	attributes := map[string]*[]dto.FacetCount{
+		enums.ProductAttributeTag:      &facets.Tags,
+		enums.ProductAttributeDietary:  &facets.Dietary,
+		enums.ProductAttributeAllergen: &facets.Allergens,
+	}
+
+	for kind, counts := range attributes {
+		*counts = []dto.FacetCount{}
+
+		if err := service.DB.Model(&entities.ProductAttribute{}).
+			Select("value, COUNT(*) AS total").
+			Where("kind = ? AND merchant_product_id IN (?)", kind, products).
+			Group("value").
+			Order("total desc, value").
+			Scan(counts).Error; err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+	}
+
+	return &facets, nil
+}
+
+// filterProducts narrows query down to the catalogue products matching
+// ?q=, ?category_id=, ?merchant_id=, ?province=, ?city=, ?packaging=,
+// ?min_price=, ?max_price=, ?min_portion= and ?max_portion=. Products must
+// have every ?tag= and ?dietary= and none of the ?exclude_allergen=. Lists
+// are separated by commas.
+func filterProducts(c *fiber.Ctx, db *gorm.DB, query *gorm.DB) (*gorm.DB, error) {
+	merchants := db.Model(&entities.Merchant{}).Select("id").Where("status = ?", "approved")
+
+	if province := c.Query("province"); province != "" {
+		merchants = merchants.Where("province = ?", province)
+	}
+
+	if city := c.Query("city"); city != "" {
+		merchants = merchants.Where("city = ?", city)
+	}
+
+	query = query.Where("merchant_products.merchant_id IN (?) AND merchant_products.status = ? AND merchant_products.is_active = ? AND merchant_products.qty > 0",
+		merchants, enums.ProductStatusApproved, true)
+
+	if q := c.Query("q"); q != "" {
+		like := "%" + q + "%"
+		query = query.Where("(merchant_products.name LIKE ? OR merchant_products.description LIKE ?)", like, like)
+	}
+
+	if categories := queryList(c, "category_id"); len(categories) > 0 {
+		query = query.Where("merchant_products.category_id IN ?", categories)
+	}
+
+	if merchantId := c.Query("merchant_id"); merchantId != "" {
+		query = query.Where("merchant_products.merchant_id = ?", merchantId)
+	}
+
+	if packaging := queryList(c, "packaging"); len(packaging) > 0 {
+		query = query.Where("merchant_products.packaging IN ?", packaging)
+	}
+
+	// orders are charged the running promotion, so that is the price to filter on
+	now := time.Now()
+	promo := db.
+		Model(&entities.ProductPrice{}).
+		Select("product_prices.price").
+		Where("product_prices.merchant_product_id = merchant_products.id AND product_prices.kind = ? AND product_prices.starts_at <= ? AND product_prices.ends_at > ?", enums.ProductPricePromo, now, now).
+		Order("product_prices.starts_at desc, product_prices.id desc").
+		Limit(1)
+	price := gorm.Expr("COALESCE((?), merchant_products.price)", promo)
+	portion := gorm.Expr("merchant_products.portion_grams")
+
+	ranges := []struct {
+		param  string
+		clause string
+		column clause.Expr
+	}{
+		{"min_price", "? >= ?", price},
+		{"max_price", "? <= ?", price},
+		{"min_portion", "? >= ?", portion},
+		{"max_portion", "? <= ?", portion},
+	}
+
+	for _, bound := range ranges {
+		if value := c.Query(bound.param); value != "" {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, err
+			}
+			query = query.Where(bound.clause, bound.column, number)
+		}
+	}
+
+	required := map[string][]string{
+		enums.ProductAttributeTag:     queryList(c, "tag"),
+		enums.ProductAttributeDietary: queryList(c, "dietary"),
+	}
+
+	for kind, values := range required {
+		for _, value := range values {
+			query = query.Where("merchant_products.id IN (?)", db.
+				Model(&entities.ProductAttribute{}).
+				Select("merchant_product_id").
+				Where("kind = ? AND value = ?", kind, value))
+		}
+	}
+
+	if allergens := queryList(c, "exclude_allergen"); len(allergens) > 0 {
+		query = query.Where("merchant_products.id NOT IN (?)", db.
+			Model(&entities.ProductAttribute{}).
+			Select("merchant_product_id").
+			Where("kind = ? AND value IN ?", enums.ProductAttributeAllergen, allergens))
+	}
+
+	return query, nil
+}
+
+// queryList reads a comma separated query parameter, lower cased.
+func queryList(c *fiber.Ctx, key string) []string {
+	var list []string
+	for _, value := range strings.Split(c.Query(key), ",") {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			list = append(list, value)
+		}
+	}
+
+	return list
+}
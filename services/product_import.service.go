@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	// ProductImportMaxRows is how many products a single file may hold.
+	ProductImportMaxRows = 5000
+	// ProductImportMaxSize is the largest file, in bytes, that is read for an import.
+	ProductImportMaxSize = 2 << 20
+	// ProductImportStaleAfter is how long a job may go without progress
+	// before it is taken for interrupted, e.g. by a restart.
+	ProductImportStaleAfter = 30 * time.Minute
+)
+
+// productImportColumns are the columns an import file must have. The columns
+// category_id, portion_grams, packaging, tags, dietary, allergens and
+// image_urls are optional, lists being separated by "|".
+var productImportColumns = []string{"name", "description", "price", "qty"}
+
+// ProductImportService creates merchant products in bulk from CSV and XLSX
+// files. Every row is validated first and the products are only created when
+// all of them are valid, in a single transaction.
+type ProductImportService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewProductImportService(ctx context.Context, db *gorm.DB) *ProductImportService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &ProductImportService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// Import reads the file and imports its products for the merchant of ownerId,
+// or for input.MerchantID when ownerId is 0. With input.Async the file is
+// processed in the background and the queued job is returned right away.
+func (service ProductImportService) Import(userId int, ownerId int, input dto.ProductImportRequest, filename string, data []byte, locale string) (*dto.ProductImportReport, *dto.ApiError) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	if _, ok := common.SheetContentTypes[format]; !ok {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    common.LocalizedError(locale, enums.ErrImportFormat),
+		}
+	}
+
+	var merchant entities.Merchant
+
+	query := service.DB.Where("id = ?", input.MerchantID)
+	if ownerId != 0 {
+		query = service.DB.Where("user_id = ?", ownerId)
+	}
+
+	if err := query.First(&merchant).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	job := entities.ProductImportJob{
+		MerchantID: merchant.ID,
+		CreatedBy:  userId,
+		Filename:   filename,
+		Status:     enums.ProductImportQueued,
+		DryRun:     input.DryRun,
+	}
+
+	if err := service.DB.Create(&job).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if input.Async {
+		go service.run(job, format, data, locale)
+
+		return reportOf(job), nil
+	}
+
+	job = service.run(job, format, data, locale)
+
+	return reportOf(job), nil
+}
+
+// GetJob returns an import with its row report. Users other than
+// superadmins, who pass an ownerId of 0, only see their own imports.
+func (service ProductImportService) GetJob(ownerId int, id string) (*dto.ProductImportReport, *dto.ApiError) {
+	service.FailStale()
+
+	var job entities.ProductImportJob
+
+	query := service.DB.Where("id = ?", id)
+	if ownerId != 0 {
+		query = query.Where("created_by = ?", ownerId)
+	}
+
+	if err := query.First(&job).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return reportOf(job), nil
+}
+
+// FailStale marks the jobs that stopped making progress as failed, so imports
+// cut off by a restart do not stay queued or running forever.
+func (service ProductImportService) FailStale() {
+	now := time.Now()
+
+	if err := service.DB.Model(&entities.ProductImportJob{}).
+		Where("status IN ? AND updated_at < ?", []string{enums.ProductImportQueued, enums.ProductImportRunning}, now.Add(-ProductImportStaleAfter)).
+		Updates(map[string]any{
+			"status":      enums.ProductImportFailed,
+			"error":       enums.ErrImportInterrupted.Error(),
+			"finished_at": now,
+		}).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+	}
+}
+
+// run processes the import job and stores its outcome. A panic while
+// processing fails the job instead of leaving it running.
+func (service ProductImportService) run(job entities.ProductImportJob, format string, data []byte, locale string) (result entities.ProductImportJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			service.Log.Error().Msg(fmt.Sprintf("product import %d failed: %v", job.ID, r))
+
+			now := time.Now()
+			job.Status = enums.ProductImportFailed
+			job.Error = common.LocalizedError(locale, enums.ErrInternalServor)
+			job.FinishedAt = &now
+
+			if err := service.DB.Select("status", "error", "finished_at").Updates(&job).Error; err != nil {
+				service.Log.Error().Msg(err.Error())
+			}
+
+			result = job
+		}
+	}()
+
+	service.DB.Model(&job).Update("status", enums.ProductImportRunning)
+
+	rows, err := service.process(job, format, data, locale)
+
+	report, _ := json.Marshal(rows)
+	now := time.Now()
+
+	job.Status = enums.ProductImportCompleted
+	job.Report = string(report)
+	job.FinishedAt = &now
+
+	job.Total = len(rows)
+	for _, row := range rows {
+		if row.Valid {
+			job.Valid++
+		} else {
+			job.Invalid++
+		}
+
+		if row.ProductID != 0 {
+			job.Created++
+		}
+	}
+
+	if err != nil {
+		job.Status = enums.ProductImportFailed
+		job.Error = common.LocalizedError(locale, err)
+	}
+
+	if err := service.DB.Select("status", "total", "valid", "invalid", "created", "error", "report", "finished_at").
+		Updates(&job).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+	}
+
+	return job
+}
+
+// process validates the rows of the file and, unless it is a dry run, creates
+// their products when they are all valid.
+func (service ProductImportService) process(job entities.ProductImportJob, format string, data []byte, locale string) ([]dto.ProductImportRow, error) {
+	sheet, err := common.ReadSheet(format, data, ProductImportMaxRows+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sheet) == 0 {
+		return nil, enums.ErrImportColumns
+	}
+
+	columns := map[string]int{}
+	for i, name := range sheet[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range productImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, enums.ErrImportColumns
+		}
+	}
+
+	cell := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+
+		return strings.TrimSpace(row[i])
+	}
+
+	var ids []int
+	if err := service.DB.Model(&entities.ProductCategory{}).Pluck("id", &ids).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, err
+	}
+
+	categories := map[int]bool{}
+	for _, id := range ids {
+		categories[id] = true
+	}
+
+	rows := []dto.ProductImportRow{}
+	requests := []dto.MerchantProductRequest{}
+	invalid := false
+
+	for i, record := range sheet[1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row := dto.ProductImportRow{
+			Row:  i + 2,
+			Name: cell(record, "name"),
+		}
+
+		request := dto.MerchantProductRequest{
+			MerchantID:  job.MerchantID,
+			Name:        row.Name,
+			Description: cell(record, "description"),
+		}
+
+		if value := cell(record, "price"); value != "" {
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, dto.ApiFieldError{
+					Field:   "price",
+					Message: common.LocalizedError(locale, enums.ErrImportNumber),
+				})
+			}
+			request.Price = price
+		}
+
+		numbers := map[string]*int{
+			"qty":           &request.QTY,
+			"category_id":   &request.CategoryID,
+			"portion_grams": &request.PortionGrams,
+		}
+
+		for name, number := range numbers {
+			if value := cell(record, name); value != "" {
+				parsed, err := strconv.Atoi(value)
+				if err != nil {
+					row.Errors = append(row.Errors, dto.ApiFieldError{
+						Field:   name,
+						Message: common.LocalizedError(locale, enums.ErrImportNumber),
+					})
+				}
+				*number = parsed
+			}
+		}
+
+		if request.CategoryID != 0 && !categories[request.CategoryID] {
+			row.Errors = append(row.Errors, dto.ApiFieldError{
+				Field:   "category_id",
+				Message: common.LocalizedError(locale, enums.ErrCategoryNotFound),
+			})
+		}
+
+		request.Packaging = strings.ToLower(cell(record, "packaging"))
+		request.Tags = importList(cell(record, "tags"))
+		request.Dietary = importList(strings.ToLower(cell(record, "dietary")))
+		request.Allergens = importList(strings.ToLower(cell(record, "allergens")))
+
+		for _, url := range importList(cell(record, "image_urls")) {
+			request.Images = append(request.Images, struct {
+				ImageURL string `json:"image_url"`
+			}{ImageURL: url})
+		}
+
+		// a number that could not be read is reported once, not as missing too
+		for _, fail := range common.ValidateRequest(request, locale) {
+			if !hasFieldError(row.Errors, fail.Field) {
+				row.Errors = append(row.Errors, fail)
+			}
+		}
+
+		row.Valid = len(row.Errors) == 0
+		invalid = invalid || !row.Valid
+
+		rows = append(rows, row)
+		requests = append(requests, request)
+	}
+
+	// a dry run only reports on the rows
+	if job.DryRun {
+		return rows, nil
+	}
+
+	if invalid {
+		return rows, enums.ErrImportInvalid
+	}
+
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	for i, request := range requests {
+		product, err := createProduct(tx, request)
+		if err != nil {
+			service.Log.Error().Msg(err.Error())
+			return rows, err
+		}
+
+		rows[i].ProductID = product.ID
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		for i := range rows {
+			rows[i].ProductID = 0
+		}
+
+		return rows, err
+	}
+
+	return rows, nil
+}
+
+// importList splits a cell holding several values separated by "|".
+func importList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func hasFieldError(errors []dto.ApiFieldError, field string) bool {
+	for _, fail := range errors {
+		if fail.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func reportOf(job entities.ProductImportJob) *dto.ProductImportReport {
+	report := dto.ProductImportReport{
+		JobID:      job.ID,
+		MerchantID: job.MerchantID,
+		Filename:   job.Filename,
+		Status:     job.Status,
+		DryRun:     job.DryRun,
+		Total:      job.Total,
+		Valid:      job.Valid,
+		Invalid:    job.Invalid,
+		Created:    job.Created,
+		Error:      job.Error,
+		Rows:       []dto.ProductImportRow{},
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+
+	if job.Report != "" {
+		json.Unmarshal([]byte(job.Report), &report.Rows)
+	}
+
+	return &report
+}
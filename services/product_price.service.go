@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProductPriceService keeps the price history of merchant products and the
+// regular and promotional prices merchants schedule ahead.
+type ProductPriceService struct {
+	DB  *gorm.DB
+	Log *zerolog.Logger
+}
+
+func NewProductPriceService(ctx context.Context, db *gorm.DB) *ProductPriceService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &ProductPriceService{
+		DB:  db,
+		Log: logger,
+	}
+}
+
+// GetPrices lists the prices of the product, the latest to start first, with
+// the scheduled ones ahead of its history. Merchants only see their own
+// products, superadmins pass an ownerId of 0.
+func (service ProductPriceService) GetPrices(ownerId int, id string) ([]entities.ProductPrice, *dto.ApiError) {
+	merchantProduct, fail := service.ownProduct(service.DB, ownerId, id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	prices := []entities.ProductPrice{}
+	if err := service.DB.
+		Where("merchant_product_id = ?", merchantProduct.ID).
+		Order("starts_at desc, id desc").
+		Find(&prices).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return prices, nil
+}
+
+// Schedule adds a regular price taking over at input.StartsAt, or a
+// promotional price holding from input.StartsAt to input.EndsAt. Merchants may
+// only price their own products, superadmins pass an ownerId of 0.
+func (service ProductPriceService) Schedule(ownerId int, id string, input dto.ProductPriceRequest) (*entities.ProductPrice, *dto.ApiError) {
+	tx := service.DB.Begin()
+	defer tx.Rollback()
+
+	// the product is locked so concurrent promotions cannot both pass the
+	// overlap check
+	merchantProduct, fail := service.ownProduct(tx.Clauses(clause.Locking{Strength: "UPDATE"}), ownerId, id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	now := time.Now()
+
+	price := entities.ProductPrice{
+		MerchantProductID: merchantProduct.ID,
+		Kind:              input.Kind,
+		Price:             decimal.NewFromFloat(input.Price),
+		StartsAt:          input.StartsAt,
+		Note:              input.Note,
+	}
+
+	if input.Kind == enums.ProductPriceRegular {
+		if !input.StartsAt.After(now) {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrPriceStart.Error(),
+			}
+		}
+	} else {
+		if input.EndsAt == nil || !input.EndsAt.After(input.StartsAt) || !input.EndsAt.After(now) {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrBadRequest,
+				Message:    enums.ErrPromoPeriod.Error(),
+			}
+		}
+
+		price.EndsAt = input.EndsAt
+
+		var overlaps int64
+		if err := tx.Model(&entities.ProductPrice{}).
+			Where("merchant_product_id = ? AND kind = ? AND starts_at < ? AND ends_at > ?",
+				merchantProduct.ID, enums.ProductPricePromo, input.EndsAt, input.StartsAt).
+			Count(&overlaps).Error; err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		if overlaps > 0 {
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrConflict,
+				Message:    enums.ErrPromoOverlap.Error(),
+			}
+		}
+	}
+
+	if err := tx.Create(&price).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &price, nil
+}
+
+// Cancel drops a scheduled price. A promotion already running is ended right
+// away instead, regular prices already started stay in the history.
+func (service ProductPriceService) Cancel(ownerId int, id string) (*entities.ProductPrice, *dto.ApiError) {
+	var price entities.ProductPrice
+	if err := service.DB.First(&price, "id", id).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	if _, fail := service.ownProduct(service.DB, ownerId, price.MerchantProductID); fail != nil {
+		return nil, fail
+	}
+
+	now := time.Now()
+
+	switch {
+	case price.Kind == enums.ProductPriceRegular && !price.StartsAt.After(now),
+		price.Kind == enums.ProductPricePromo && !price.EndsAt.After(now):
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrPriceApplied.Error(),
+		}
+	case price.Kind == enums.ProductPricePromo && !price.StartsAt.After(now):
+		if err := service.DB.Model(&price).Update("ends_at", now).Error; err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+
+		return &price, nil
+	}
+
+	if err := service.DB.Delete(&price).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &price, nil
+}
+
+// ownProduct finds the product of id with db, among the products of the
+// merchant of ownerId unless it is 0.
+func (service ProductPriceService) ownProduct(db *gorm.DB, ownerId int, id any) (*entities.MerchantProduct, *dto.ApiError) {
+	var merchantProduct entities.MerchantProduct
+
+	query := db.Where("id = ?", id)
+	if ownerId != 0 {
+		query = query.Where("merchant_id IN (?)", service.DB.
+			Model(&entities.Merchant{}).
+			Select("id").
+			Where("user_id = ?", ownerId))
+	}
+
+	if err := query.First(&merchantProduct).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &merchantProduct, nil
+}
+
+// effectivePrice is the unit price of the product at the moment at: the
+// promotion running then, otherwise the last regular price started by then.
+func effectivePrice(db *gorm.DB, product entities.MerchantProduct, at time.Time) (decimal.Decimal, error) {
+	var prices []entities.ProductPrice
+
+	if err := db.
+		Where("merchant_product_id = ? AND starts_at <= ? AND (kind = ? OR ends_at > ?)", product.ID, at, enums.ProductPriceRegular, at).
+		Order("starts_at desc, id desc").
+		Find(&prices).Error; err != nil {
+		return decimal.Zero, err
+	}
+
+	return selectPrice(prices, product.Price, at), nil
+}
+
+// selectPrice picks the price holding at the moment at out of prices ordered
+// by the latest to start first. The latest promotion running wins over the
+// regular prices, fallback is used when none has started yet.
+func selectPrice(prices []entities.ProductPrice, fallback decimal.Decimal, at time.Time) decimal.Decimal {
+	var regular *entities.ProductPrice
+
+	for i, price := range prices {
+		if price.StartsAt.After(at) {
+			continue
+		}
+
+		switch price.Kind {
+		case enums.ProductPricePromo:
+			if price.EndsAt != nil && price.EndsAt.After(at) {
+				return price.Price
+			}
+		case enums.ProductPriceRegular:
+			if regular == nil {
+				regular = &prices[i]
+			}
+		}
+	}
+
+	if regular != nil {
+		return regular.Price
+	}
+
+	return fallback
+}
+
+// recordPrice adds the current price of the product to its history.
+func recordPrice(tx *gorm.DB, product entities.MerchantProduct) error {
+	now := time.Now()
+
+	return tx.Create(&entities.ProductPrice{
+		MerchantProductID: product.ID,
+		Kind:              enums.ProductPriceRegular,
+		Price:             product.Price,
+		StartsAt:          now,
+		AppliedAt:         &now,
+	}).Error
+}
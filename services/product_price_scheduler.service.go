@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"foodia-be/configs"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const DefaultPriceInterval = time.Minute
+
+// ProductPriceScheduler applies the regular prices merchants scheduled to
+// their products once they start, so listings show the current price. Orders
+// do not wait for it, they price products with effectivePrice.
+type ProductPriceScheduler struct {
+	DB       *gorm.DB
+	Log      *zerolog.Logger
+	Interval time.Duration
+}
+
+func NewProductPriceScheduler(ctx context.Context, db *gorm.DB) *ProductPriceScheduler {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	config := ctx.Value(enums.ConfigCtxKey).(*configs.EnvConfig)
+
+	scheduler := &ProductPriceScheduler{
+		DB:       db,
+		Log:      logger,
+		Interval: config.PriceInterval,
+	}
+
+	if scheduler.Interval <= 0 {
+		scheduler.Interval = DefaultPriceInterval
+	}
+
+	return scheduler
+}
+
+// Run applies the prices until ctx is cancelled.
+func (scheduler *ProductPriceScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.Interval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.tick(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (scheduler *ProductPriceScheduler) tick(now time.Time) {
+	var prices []entities.ProductPrice
+	if err := scheduler.DB.
+		Where("kind = ? AND applied_at IS NULL AND starts_at <= ?", enums.ProductPriceRegular, now).
+		Order("starts_at, id").
+		Find(&prices).Error; err != nil {
+		scheduler.Log.Error().Msg(err.Error())
+		return
+	}
+
+	// in start order, so the last price due on a product is the one it keeps
+	for _, price := range prices {
+		if err := scheduler.apply(price, now); err != nil {
+			scheduler.Log.Error().Msg(fmt.Sprintf("price %d could not be applied: %s", price.ID, err.Error()))
+		}
+	}
+}
+
+func (scheduler *ProductPriceScheduler) apply(price entities.ProductPrice, now time.Time) error {
+	tx := scheduler.DB.Begin()
+	defer tx.Rollback()
+
+	// a price the merchant set directly since it started wins over it
+	var newer int64
+	if err := tx.Model(&entities.ProductPrice{}).
+		Where("merchant_product_id = ? AND kind = ? AND applied_at IS NOT NULL AND starts_at > ?",
+			price.MerchantProductID, enums.ProductPriceRegular, price.StartsAt).
+		Count(&newer).Error; err != nil {
+		return err
+	}
+
+	if newer == 0 {
+		if err := tx.Model(&entities.MerchantProduct{}).
+			Where("id = ?", price.MerchantProductID).
+			Update("price", price.Price).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&price).Update("applied_at", now).Error; err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
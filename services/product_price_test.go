@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSelectPrice(t *testing.T) {
+	now := time.Date(2023, 9, 17, 10, 0, 0, 0, time.UTC)
+	fallback := decimal.NewFromInt(25000)
+
+	regular := func(price int64, startsAt time.Time) entities.ProductPrice {
+		return entities.ProductPrice{Kind: enums.ProductPriceRegular, Price: decimal.NewFromInt(price), StartsAt: startsAt}
+	}
+
+	promo := func(price int64, startsAt time.Time, endsAt time.Time) entities.ProductPrice {
+		return entities.ProductPrice{Kind: enums.ProductPricePromo, Price: decimal.NewFromInt(price), StartsAt: startsAt, EndsAt: &endsAt}
+	}
+
+	tests := []struct {
+		name   string
+		prices []entities.ProductPrice
+		want   int64
+	}{
+		{"no prices", nil, 25000},
+		{"regular started", []entities.ProductPrice{regular(27000, now.Add(-time.Hour))}, 27000},
+		{"regular starting now", []entities.ProductPrice{regular(27000, now)}, 27000},
+		{"regular not started yet", []entities.ProductPrice{regular(27000, now.Add(time.Hour))}, 25000},
+		{"latest regular wins", []entities.ProductPrice{
+			regular(28000, now.Add(-time.Hour)),
+			regular(27000, now.Add(-2*time.Hour)),
+		}, 28000},
+		{"running promo wins over regular", []entities.ProductPrice{
+			regular(28000, now.Add(-time.Hour)),
+			promo(20000, now.Add(-2*time.Hour), now.Add(time.Hour)),
+		}, 20000},
+		{"promo ended", []entities.ProductPrice{
+			promo(20000, now.Add(-2*time.Hour), now.Add(-time.Hour)),
+			regular(27000, now.Add(-3*time.Hour)),
+		}, 27000},
+		{"promo ending now", []entities.ProductPrice{promo(20000, now.Add(-time.Hour), now)}, 25000},
+		{"promo not started yet", []entities.ProductPrice{promo(20000, now.Add(time.Hour), now.Add(2*time.Hour))}, 25000},
+		{"promo without end", []entities.ProductPrice{{Kind: enums.ProductPricePromo, Price: decimal.NewFromInt(20000), StartsAt: now.Add(-time.Hour)}}, 25000},
+		{"latest promo wins", []entities.ProductPrice{
+			promo(18000, now.Add(-time.Hour), now.Add(time.Hour)),
+			promo(20000, now.Add(-2*time.Hour), now.Add(2*time.Hour)),
+		}, 18000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectPrice(tt.prices, fallback, now); !got.Equal(decimal.NewFromInt(tt.want)) {
+				t.Fatalf("selectPrice() = %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
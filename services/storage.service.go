@@ -0,0 +1,196 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+const StorageRoot = "./storage"
+
+// storageLock serialises the check-then-rename step so that two uploads of the
+// same content cannot both believe they created the file.
+var storageLock sync.Mutex
+
+// storagePending counts, per path, the open batches holding a file that was
+// created by a batch which has not committed yet. A rolled back batch only
+// removes the file when no other open batch reuses it and no batch has
+// committed a record pointing at it in the meantime.
+var storagePending = map[string]int{}
+
+type StorageService struct {
+	Root string
+	Log  *zerolog.Logger
+}
+
+func NewStorageService(logger *zerolog.Logger) *StorageService {
+	return &StorageService{
+		Root: StorageRoot,
+		Log:  logger,
+	}
+}
+
+// StorageBatch tracks the files written during a single unit of work so they can
+// be removed again when the surrounding database transaction is rolled back.
+// It mirrors the gorm transaction usage: defer Rollback() and call Commit() once
+// the database commit succeeded.
+type StorageBatch struct {
+	service   *StorageService
+	pending   []string
+	committed bool
+}
+
+func (service StorageService) Batch() *StorageBatch {
+	return &StorageBatch{service: &service}
+}
+
+// Store saves the uploaded file under destination using the SHA-256 of its
+// content as the file name. When a file with the same content already exists
+// it is reused instead of written again. The returned path is relative to the
+// storage root, e.g. "merchant/<hash>.jpg".
+func (batch *StorageBatch) Store(destination string, file *multipart.FileHeader) (string, error) {
+	path, pending, err := batch.service.store(destination, file)
+	if err != nil {
+		return "", err
+	}
+
+	if pending {
+		batch.pending = append(batch.pending, path)
+	}
+
+	return path, nil
+}
+
+// Commit keeps every file written or reused by the batch.
+func (batch *StorageBatch) Commit() {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	for _, path := range batch.pending {
+		delete(storagePending, path)
+	}
+	batch.pending = nil
+	batch.committed = true
+}
+
+// Rollback removes the files written by the batch unless Commit was called.
+// Files that already existed before the batch started, or that another batch
+// reused and committed meanwhile, are never touched.
+func (batch *StorageBatch) Rollback() {
+	if batch.committed {
+		return
+	}
+
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	for _, path := range batch.pending {
+		count, ok := storagePending[path]
+		if !ok {
+			continue
+		}
+
+		if count > 1 {
+			storagePending[path] = count - 1
+			continue
+		}
+
+		delete(storagePending, path)
+		batch.service.unlink(path)
+	}
+	batch.pending = nil
+}
+
+// Release removes a previously stored file when it is no longer referenced.
+// inUse reports whether any record still points at the path; since files are
+// shared between records with identical content, the caller must check this.
+func (service StorageService) Release(path string, inUse func(path string) bool) {
+	if path == "" || inUse(path) {
+		return
+	}
+
+	service.remove(path)
+}
+
+func (service StorageService) store(destination string, file *multipart.FileHeader) (string, bool, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", false, err
+	}
+	defer src.Close()
+
+	dir := filepath.Join(service.Root, destination)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", false, err
+	}
+
+	// write to a temporary file first while hashing, so the content is read once
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return "", false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), src); err != nil {
+		tmp.Close()
+		return "", false, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", false, err
+	}
+
+	name := hex.EncodeToString(hash.Sum(nil)) + strings.ToLower(filepath.Ext(file.Filename))
+	path := destination + "/" + name
+	target := filepath.Join(service.Root, path)
+
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	if _, err := os.Stat(target); err == nil {
+		// the file may still belong to an uncommitted batch; hold on to it as well
+		if count, ok := storagePending[path]; ok {
+			storagePending[path] = count + 1
+			return path, true, nil
+		}
+		return path, false, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", false, err
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", false, err
+	}
+
+	storagePending[path] = 1
+
+	return path, true, nil
+}
+
+func (service StorageService) remove(path string) {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+
+	if _, ok := storagePending[path]; ok {
+		return
+	}
+
+	service.unlink(path)
+}
+
+// unlink deletes the file; the caller must hold storageLock.
+func (service StorageService) unlink(path string) {
+	if err := os.Remove(filepath.Join(service.Root, path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		service.Log.Error().Msg(fmt.Sprintf("failed to remove %s: %v", path, err))
+	}
+}
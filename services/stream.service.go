@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"foodia-be/common"
+	"foodia-be/configs"
+	"foodia-be/dto"
+	"foodia-be/enums"
+
+	"github.com/rs/zerolog"
+)
+
+const streamSubscriberBuffer = 64
+
+// Broker fans stream events out to the subscribers of this instance. The
+// in-process MemoryBroker only reaches clients connected to the same process;
+// an external broker can be plugged in behind the same interface.
+type Broker interface {
+	Name() string
+	Publish(event dto.StreamEvent)
+	// Subscribe returns the channel events are delivered on and a function
+	// that must be called to stop the subscription.
+	Subscribe() (<-chan dto.StreamEvent, func())
+}
+
+// NewBroker builds the broker selected by STREAM_BROKER.
+func NewBroker(config *configs.EnvConfig, logger *zerolog.Logger) (Broker, error) {
+	switch config.StreamBroker {
+	case "", enums.StreamBrokerMemory:
+		return NewMemoryBroker(logger), nil
+	}
+
+	return nil, fmt.Errorf("unknown stream broker %q", config.StreamBroker)
+}
+
+// MemoryBroker delivers events to subscribers of the current process. Slow
+// subscribers whose buffer is full miss events rather than blocking publishers.
+type MemoryBroker struct {
+	Log *zerolog.Logger
+
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]chan dto.StreamEvent
+}
+
+func NewMemoryBroker(logger *zerolog.Logger) *MemoryBroker {
+	return &MemoryBroker{
+		Log:         logger,
+		subscribers: map[int]chan dto.StreamEvent{},
+	}
+}
+
+func (b *MemoryBroker) Name() string {
+	return enums.StreamBrokerMemory
+}
+
+func (b *MemoryBroker) Publish(event dto.StreamEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for id, subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			if b.Log != nil {
+				b.Log.Error().Msg(fmt.Sprintf("stream subscriber %d is full, dropping %s event", id, event.Topic))
+			}
+		}
+	}
+}
+
+func (b *MemoryBroker) Subscribe() (<-chan dto.StreamEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	subscriber := make(chan dto.StreamEvent, streamSubscriberBuffer)
+	b.subscribers[id] = subscriber
+
+	var once sync.Once
+	return subscriber, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers, id)
+			close(subscriber)
+		})
+	}
+}
+
+type StreamService struct {
+	Broker Broker
+	Log    *zerolog.Logger
+}
+
+func NewStreamService(ctx context.Context) *StreamService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+	broker, _ := ctx.Value(enums.BrokerCtxKey).(Broker)
+
+	return &StreamService{
+		Broker: broker,
+		Log:    logger,
+	}
+}
+
+// Batch collects events raised during a transaction so they are only
+// published once the transaction has committed.
+func (service StreamService) Batch() *EventBatch {
+	return &EventBatch{broker: service.Broker}
+}
+
+// Publish sends an event right away, for changes that are not part of a transaction.
+func (service StreamService) Publish(event dto.StreamEvent) {
+	if service.Broker == nil {
+		return
+	}
+
+	if event.ID == "" {
+		event.ID = common.GenerateUUID()
+	}
+
+	service.Broker.Publish(event)
+}
+
+// Visible reports whether a subscriber of the given user and topics receives the event.
+func (service StreamService) Visible(event dto.StreamEvent, userId int, topics map[string]bool) bool {
+	if event.UserId != 0 && event.UserId != userId {
+		return false
+	}
+
+	return len(topics) == 0 || topics[event.Topic]
+}
+
+// EventBatch holds the stream events of one unit of work until Publish.
+type EventBatch struct {
+	broker Broker
+	events []dto.StreamEvent
+}
+
+func (b *EventBatch) Add(event dto.StreamEvent) {
+	if b == nil {
+		return
+	}
+
+	if event.ID == "" {
+		event.ID = common.GenerateUUID()
+	}
+
+	b.events = append(b.events, event)
+}
+
+// Publish hands the collected events to the broker. Call it after the
+// transaction that produced them has committed.
+func (b *EventBatch) Publish() {
+	if b == nil || b.broker == nil {
+		return
+	}
+
+	for _, event := range b.events {
+		b.broker.Publish(event)
+	}
+
+	b.events = nil
+}
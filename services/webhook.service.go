@@ -0,0 +1,389 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"foodia-be/common"
+	"foodia-be/dto"
+	"foodia-be/entities"
+	"foodia-be/enums"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookTimeout         = 10 * time.Second
+	webhookSecretBytes     = 32
+	webhookMaxResponseBody = 2048
+)
+
+type WebhookService struct {
+	DB     *gorm.DB
+	Log    *zerolog.Logger
+	Outbox *OutboxService
+	Client *http.Client
+}
+
+func NewWebhookService(ctx context.Context, db *gorm.DB) *WebhookService {
+	logger := ctx.Value(enums.LoggerCtxKey).(*zerolog.Logger)
+
+	return &WebhookService{
+		DB:     db,
+		Log:    logger,
+		Outbox: NewOutboxService(ctx, db),
+		Client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Publish queues the event for every active subscription interested in it,
+// within tx so nothing is sent for a change that is rolled back.
+func (service WebhookService) Publish(tx *gorm.DB, event string, data any) error {
+	var subscriptions []entities.WebhookSubscription
+	if err := tx.Where("is_active = ?", true).Find(&subscriptions).Error; err != nil {
+		return err
+	}
+
+	payload := dto.WebhookPayload{
+		ID:        common.GenerateUUID(),
+		Event:     event,
+		CreatedAt: time.Now(),
+		Data:      data,
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(event) {
+			continue
+		}
+
+		if err := service.Outbox.Enqueue(tx, enums.OutboxChannelWebhook, event, strconv.Itoa(subscription.ID), "", payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deliver is the outbox handler of the webhook channel. Every attempt is
+// logged; a failed attempt returns an error so the outbox retries it later.
+func (service WebhookService) Deliver(message entities.Outbox) error {
+	var subscription entities.WebhookSubscription
+	if err := service.DB.First(&subscription, "id", message.Recipient).Error; err != nil {
+		return err
+	}
+
+	if !subscription.IsActive {
+		service.Log.Info().Msg(fmt.Sprintf("webhook subscription %d is inactive, dropping %s", subscription.ID, message.Type))
+		return nil
+	}
+
+	delivery := service.send(subscription, message.Type, []byte(message.Payload))
+	delivery.OutboxID = message.ID
+	delivery.Attempt = message.Attempts + 1
+
+	if err := service.DB.Create(&delivery).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+	}
+
+	if !delivery.Success {
+		return fmt.Errorf("webhook %s to %s failed: %s", message.Type, subscription.URL, delivery.Error)
+	}
+
+	return nil
+}
+
+// send posts a signed payload to the subscription and reports the outcome.
+func (service WebhookService) send(subscription entities.WebhookSubscription, event string, body []byte) entities.WebhookDelivery {
+	delivery := entities.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		Event:          event,
+		URL:            subscription.URL,
+		Payload:        string(body),
+		Attempt:        1,
+	}
+
+	var payload dto.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err == nil {
+		delivery.EventID = payload.ID
+	}
+
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(fiber.HeaderUserAgent, "Foodia-Webhook/1.0")
+	req.Header.Set(enums.WebhookHeaderEvent, event)
+	req.Header.Set(enums.WebhookHeaderDelivery, delivery.EventID)
+	req.Header.Set(enums.WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(enums.WebhookHeaderSignature, "sha256="+common.SignPayload(subscription.Secret, timestamp, body))
+
+	started := time.Now()
+	res, err := service.Client.Do(req)
+	delivery.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer res.Body.Close()
+
+	response, _ := io.ReadAll(io.LimitReader(res.Body, webhookMaxResponseBody))
+	delivery.StatusCode = res.StatusCode
+	delivery.ResponseBody = string(response)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		delivery.Error = fmt.Sprintf("subscriber responded with status %d", res.StatusCode)
+		return delivery
+	}
+
+	delivery.Success = true
+
+	return delivery
+}
+
+func (service WebhookService) Create(input dto.WebhookSubscriptionRequest) (*entities.CreatedWebhookSubscription, *dto.ApiError) {
+	secret := input.Secret
+	if secret == "" {
+		generated, err := common.GenerateSecret(webhookSecretBytes)
+		if err != nil {
+			service.Log.Error().Msg(err.Error())
+			return nil, &dto.ApiError{
+				StatusCode: fiber.ErrInternalServerError,
+				Message:    err.Error(),
+			}
+		}
+		secret = generated
+	}
+
+	subscription := entities.WebhookSubscription{
+		URL:         input.URL,
+		Secret:      secret,
+		Events:      strings.Join(input.Events, ","),
+		Description: input.Description,
+		IsActive:    input.IsActive == nil || *input.IsActive,
+	}
+
+	if err := service.DB.Create(&subscription).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &entities.CreatedWebhookSubscription{
+		WebhookSubscription: subscription,
+		Secret:              secret,
+	}, nil
+}
+
+func (service WebhookService) GetAll(pagination *common.Pagination) ([]entities.WebhookSubscription, *dto.ApiError) {
+	var subscriptions []entities.WebhookSubscription
+
+	query := service.DB.Order("created_at desc").Find(&subscriptions)
+
+	if err := query.Scopes(common.Paginate(query, entities.WebhookSubscription{}, pagination)).Find(&subscriptions); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return subscriptions, nil
+}
+
+func (service WebhookService) GetByID(id string) (*entities.WebhookSubscription, *dto.ApiError) {
+	var subscription entities.WebhookSubscription
+
+	if err := service.DB.Where("id", id).First(&subscription).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &subscription, nil
+}
+
+func (service WebhookService) Update(id string, input dto.WebhookSubscriptionRequest) (*entities.WebhookSubscription, *dto.ApiError) {
+	subscription, fail := service.GetByID(id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	update := map[string]any{
+		"url":         input.URL,
+		"events":      strings.Join(input.Events, ","),
+		"description": input.Description,
+	}
+
+	if input.Secret != "" {
+		update["secret"] = input.Secret
+	}
+
+	if input.IsActive != nil {
+		update["is_active"] = *input.IsActive
+	}
+
+	if err := service.DB.Model(subscription).Updates(update).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return service.GetByID(id)
+}
+
+func (service WebhookService) Delete(id string) *dto.ApiError {
+	subscription, fail := service.GetByID(id)
+	if fail != nil {
+		return fail
+	}
+
+	if err := service.DB.Delete(subscription).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func (service WebhookService) GetDeliveries(c *fiber.Ctx, pagination *common.Pagination) ([]entities.WebhookDelivery, *dto.ApiError) {
+	var deliveries []entities.WebhookDelivery
+
+	query := service.DB.Order("created_at desc")
+
+	if subscriptionId := c.Query("subscription_id"); subscriptionId != "" {
+		query = query.Where("subscription_id = ?", subscriptionId)
+	}
+
+	if event := c.Query("event"); event != "" {
+		query = query.Where("event = ?", event)
+	}
+
+	switch c.Query("success") {
+	case "true":
+		query = query.Where("success = ?", true)
+	case "false":
+		query = query.Where("success = ?", false)
+	}
+
+	query = query.Find(&deliveries)
+
+	if err := query.Scopes(common.Paginate(query, entities.WebhookDelivery{}, pagination)).Find(&deliveries); err.Error != nil {
+		service.Log.Error().Msg(err.Error.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error.Error(),
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (service WebhookService) GetDelivery(id string) (*entities.WebhookDelivery, *dto.ApiError) {
+	var delivery entities.WebhookDelivery
+
+	if err := service.DB.Preload("Subscription").Where("id", id).First(&delivery).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrNotFound,
+			Message:    err.Error(),
+		}
+	}
+
+	return &delivery, nil
+}
+
+// Redeliver queues the payload of a logged delivery again with a fresh
+// attempt budget. The payload keeps its event id so receivers can deduplicate.
+func (service WebhookService) Redeliver(id string) (*entities.Outbox, *dto.ApiError) {
+	delivery, fail := service.GetDelivery(id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	// pings are sent right away and never go through the outbox
+	if delivery.Event == enums.WebhookEventPing {
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrBadRequest,
+			Message:    enums.ErrWebhookPingRedelivery.Error(),
+		}
+	}
+
+	message := entities.Outbox{
+		Channel:       enums.OutboxChannelWebhook,
+		Type:          delivery.Event,
+		Recipient:     strconv.Itoa(delivery.SubscriptionID),
+		Payload:       delivery.Payload,
+		Status:        enums.OutboxStatusPending,
+		MaxAttempts:   service.Outbox.maxAttempts(),
+		NextAttemptAt: time.Now(),
+	}
+
+	if err := service.DB.Create(&message).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &message, nil
+}
+
+// Ping sends a signed ping event to the subscription right away, so a partner
+// or a local stand-in receiver can check its endpoint and signature handling.
+func (service WebhookService) Ping(id string) (*entities.WebhookDelivery, *dto.ApiError) {
+	subscription, fail := service.GetByID(id)
+	if fail != nil {
+		return nil, fail
+	}
+
+	body, err := json.Marshal(dto.WebhookPayload{
+		ID:        common.GenerateUUID(),
+		Event:     enums.WebhookEventPing,
+		CreatedAt: time.Now(),
+		Data: map[string]any{
+			"subscription_id": subscription.ID,
+		},
+	})
+	if err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	delivery := service.send(*subscription, enums.WebhookEventPing, body)
+
+	if err := service.DB.Create(&delivery).Error; err != nil {
+		service.Log.Error().Msg(err.Error())
+		return nil, &dto.ApiError{
+			StatusCode: fiber.ErrInternalServerError,
+			Message:    err.Error(),
+		}
+	}
+
+	return &delivery, nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"foodia-be/entities"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dryRun returns a MySQL connection that only builds statements, for checking
+// what would be written without a database.
+func dryRun(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "foodia:foodia@tcp(127.0.0.1:3306)/foodia?parseTime=true",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// inserted returns the value the INSERT of statement writes to column, and
+// whether it writes the column at all.
+func inserted(t *testing.T, statement *gorm.Statement, column string) (any, bool) {
+	values, ok := statement.Clauses["VALUES"].Expression.(clause.Values)
+	if !ok || len(values.Values) != 1 {
+		t.Fatalf("statement %q does not insert one row", statement.SQL.String())
+	}
+
+	for i, each := range values.Columns {
+		if each.Name == column {
+			return values.Values[0][i], true
+		}
+	}
+
+	return nil, false
+}
+
+func TestCreateInactiveSubscription(t *testing.T) {
+	subscription := entities.WebhookSubscription{URL: "https://partner.example.com/hook", Secret: "secret", Events: "order.created"}
+
+	statement := dryRun(t).Create(&subscription).Statement
+
+	if value, ok := inserted(t, statement, "is_active"); !ok || value != false {
+		t.Fatalf("is_active is written as %v (%v), want false", value, ok)
+	}
+}